@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"ticktok-service/config"
+	"ticktok-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 性能基准命令，在事务中写入测试数据，测量结束后回滚，不影响库中已有数据
+//
+// 用法: go run ./cmd/benchmark [-config config/config.yaml] [-sizes 10,100,1000] [-iterations 20] <基准名>
+var benchmarks = map[string]func(db *gorm.DB, size, iterations int) error{
+	"message-list": benchMessageList,
+}
+
+// errRollback 测量完成后回滚事务
+var errRollback = errors.New("rollback")
+
+// queryCount 已执行的SQL语句数量
+var queryCount atomic.Int64
+
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "配置文件路径")
+	sizes := flag.String("sizes", "10,100,1000", "数据规模，逗号分隔")
+	iterations := flag.Int("iterations", 20, "每个规模的测量次数")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "用法: %s [-config 配置文件] [-sizes 规模] [-iterations 次数] <基准名>\n可用基准:\n", os.Args[0])
+		for name := range benchmarks {
+			fmt.Fprintf(os.Stderr, "  %s\n", name)
+		}
+	}
+	flag.Parse()
+
+	bench, ok := benchmarks[flag.Arg(0)]
+	if !ok || *iterations < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// 加载配置
+	if err := config.LoadConfig(*configPath); err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	// 初始化数据库
+	if err := model.SetupDB(); err != nil {
+		log.Fatalf("初始化数据库失败: %v", err)
+	}
+	db := model.DB.Session(&gorm.Session{Logger: logger.Discard})
+	if err := registerQueryCounter(db); err != nil {
+		log.Fatalf("注册SQL计数回调失败: %v", err)
+	}
+
+	for _, field := range strings.Split(*sizes, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || size < 1 {
+			log.Fatalf("无效的数据规模: %s", field)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := bench(tx, size, *iterations); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if err != nil && !errors.Is(err, errRollback) {
+			log.Fatalf("执行基准 %s 失败: %v", flag.Arg(0), err)
+		}
+	}
+}
+
+// registerQueryCounter 统计每条执行的SQL语句
+func registerQueryCounter(db *gorm.DB) error {
+	count := func(*gorm.DB) { queryCount.Add(1) }
+	callback := db.Callback()
+	if err := callback.Query().After("gorm:query").Register("benchmark:count_query", count); err != nil {
+		return err
+	}
+	if err := callback.Row().After("gorm:row").Register("benchmark:count_row", count); err != nil {
+		return err
+	}
+	return callback.Raw().After("gorm:raw").Register("benchmark:count_raw", count)
+}
+
+// measure 执行fn iterations次，输出平均耗时和每次执行的SQL数量
+func measure(name string, size, iterations int, fn func() error) error {
+	// 预热一次，排除首次执行的连接和语句准备开销
+	if err := fn(); err != nil {
+		return err
+	}
+
+	queryCount.Store(0)
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	elapsed := time.Since(start)
+
+	fmt.Printf("%-28s size=%-6d %10.2f ms/op %8d queries/op\n",
+		name, size,
+		float64(elapsed.Microseconds())/1000/float64(iterations),
+		queryCount.Load()/int64(iterations),
+	)
+	return nil
+}
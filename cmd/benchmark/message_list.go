@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
+	"ticktok-service/internal/repository"
+	"ticktok-service/internal/service"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// messagesPerConversation 每个会话写入的消息数量
+const messagesPerConversation = 3
+
+// benchMessageList 对比消息列表的批量查询实现与逐会话查询的写法
+// 写入一个拥有size个单聊会话的用户，其中一半对方是好友，部分为AI机器人或系统账号
+func benchMessageList(db *gorm.DB, size, iterations int) error {
+	viewer, err := seedConversations(db, size)
+	if err != nil {
+		return err
+	}
+
+	hub := ws.NewHub()
+	notifier := service.NewNotificationService(db)
+	messageService := service.NewMessageService(db, hub, notifier, service.NewBotService(db, hub, notifier))
+	if err := measure("message-list", size, iterations, func() error {
+		list, err := messageService.GetMessageList(viewer.ID, false)
+		if err != nil {
+			return err
+		}
+		if len(list) != size {
+			return fmt.Errorf("消息列表应有 %d 个会话，实际为 %d", size, len(list))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return measure("message-list/per-conversation", size, iterations, func() error {
+		return perConversationMessageList(db, viewer.ID)
+	})
+}
+
+// perConversationMessageList 逐个会话查询对方用户、未读数和好友类型，作为对照
+func perConversationMessageList(db *gorm.DB, userID uint) error {
+	messages, err := repository.NewMessageRepository(db).GetLastMessages(userID)
+	if err != nil {
+		return err
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	friendRepo := repository.NewFriendRepository(db)
+	for _, message := range messages {
+		peerID := message.SenderID
+		if peerID == userID {
+			peerID = message.ReceiverID
+		}
+		if _, err := userRepo.GetUserByID(peerID); err != nil {
+			return err
+		}
+		var unread model.UnreadMessage
+		if err := db.Select("count").
+			Where("user_id = ? AND sender_id = ? AND group_id = 0", userID, peerID).
+			Limit(1).Find(&unread).Error; err != nil {
+			return err
+		}
+		if _, err := friendRepo.GetFriendship(userID, peerID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedConversations 写入测试用户及其size个单聊会话，返回该用户
+func seedConversations(db *gorm.DB, size int) (*model.User, error) {
+	now := time.Now()
+	users := make([]*model.User, 0, size+1)
+	for i := 0; i <= size; i++ {
+		accountType := model.AccountTypeNormal
+		if i > 0 && i%25 == 0 {
+			accountType = model.AccountTypeSystem
+		}
+		users = append(users, &model.User{
+			UID:         uint(i),
+			Nickname:    fmt.Sprintf("bench-%d", i),
+			Status:      model.PresenceOffline,
+			AccountType: accountType,
+			CreatedAt:   now,
+		})
+	}
+	if err := db.CreateInBatches(&users, 500).Error; err != nil {
+		return nil, err
+	}
+	viewer, peers := users[0], users[1:]
+
+	var friendships []*model.Friendship
+	var messages []*model.Message
+	var unreads []*model.UnreadMessage
+	for i, peer := range peers {
+		if i%2 == 0 {
+			friendType := model.FriendTypeNormal
+			if i%10 == 0 {
+				friendType = model.FriendTypeAIBot
+			}
+			friendships = append(friendships, &model.Friendship{
+				UserID:     viewer.ID,
+				FriendID:   peer.ID,
+				FriendType: friendType,
+				CreatedAt:  now,
+			})
+		}
+
+		sessionID := model.SessionIDFor(viewer.ID, peer.ID)
+		for j := 0; j < messagesPerConversation; j++ {
+			senderID, receiverID := peer.ID, viewer.ID
+			if j%2 == 1 {
+				senderID, receiverID = viewer.ID, peer.ID
+			}
+			messages = append(messages, &model.Message{
+				SessionID:  sessionID,
+				SenderID:   senderID,
+				ReceiverID: receiverID,
+				Type:       model.MessageTypeText,
+				Content:    fmt.Sprintf("消息 %d", j),
+				Timestamp:  now.Add(time.Duration(i*messagesPerConversation+j) * time.Millisecond).UnixMilli(),
+				Status:     "sent",
+				CreatedAt:  now,
+			})
+		}
+
+		unreads = append(unreads, &model.UnreadMessage{
+			UserID:    viewer.ID,
+			SenderID:  peer.ID,
+			Count:     i % 5,
+			UpdatedAt: now,
+		})
+	}
+
+	if err := db.Omit(clause.Associations).CreateInBatches(&friendships, 500).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Omit(clause.Associations).CreateInBatches(&messages, 500).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Omit(clause.Associations).CreateInBatches(&unreads, 500).Error; err != nil {
+		return nil, err
+	}
+	return viewer, nil
+}
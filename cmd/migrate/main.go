@@ -0,0 +1,214 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"ticktok-service/config"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/repository"
+	"ticktok-service/internal/service"
+	"time"
+)
+
+// 数据迁移命令
+//
+// 用法: go run ./cmd/migrate [-config config/config.yaml] <任务名>
+var tasks = map[string]func() error{
+	"merge-sessions":           mergeSessions,
+	"drop-message-receiver-fk": dropMessageReceiverFK,
+	"extend-message-types":     extendMessageTypes,
+	"drop-user-last-seen":      dropUserLastSeen,
+	"extend-message-status":    extendMessageStatus,
+	"backfill-feed-inbox":      backfillFeedInbox,
+	"reset-legacy-owners":      resetLegacyOwners,
+	"backfill-post-authors":    backfillPostAuthors,
+	"backfill-account-types":   backfillAccountTypes,
+}
+
+func main() {
+	configPath := flag.String("config", "config/config.yaml", "配置文件路径")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "用法: %s [-config 配置文件] <任务名>\n可用任务:\n", os.Args[0])
+		for name := range tasks {
+			fmt.Fprintf(os.Stderr, "  %s\n", name)
+		}
+	}
+	flag.Parse()
+
+	task, ok := tasks[flag.Arg(0)]
+	if !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// 加载配置
+	if err := config.LoadConfig(*configPath); err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	// 初始化数据库
+	if err := model.SetupDB(); err != nil {
+		log.Fatalf("初始化数据库失败: %v", err)
+	}
+
+	if err := task(); err != nil {
+		log.Fatalf("执行迁移任务 %s 失败: %v", flag.Arg(0), err)
+	}
+}
+
+// mergeSessions 合并A→B与B→A重复的会话
+func mergeSessions() error {
+	removed, err := repository.NewMessageRepository(model.DB).MergeDuplicateSessions()
+	if err != nil {
+		return err
+	}
+	log.Printf("会话合并完成，删除重复会话 %d 个", removed)
+	return nil
+}
+
+// dropMessageReceiverFK 删除messages.receiver_id的外键约束，群消息的receiver_id为0
+func dropMessageReceiverFK() error {
+	const constraint = "fk_messages_receiver"
+	migrator := model.DB.Migrator()
+	if !migrator.HasConstraint(&model.Message{}, constraint) {
+		log.Printf("约束 %s 不存在，无需处理", constraint)
+		return nil
+	}
+	if err := migrator.DropConstraint(&model.Message{}, constraint); err != nil {
+		return err
+	}
+	log.Printf("已删除约束 %s", constraint)
+	return nil
+}
+
+// extendMessageTypes 按模型定义修改messages.type的枚举值，AutoMigrate不会修改已有枚举列
+func extendMessageTypes() error {
+	if err := model.DB.Migrator().AlterColumn(&model.Message{}, "Type"); err != nil {
+		return err
+	}
+	log.Printf("已更新messages.type枚举")
+	return nil
+}
+
+// dropUserLastSeen 删除users表中旧的字符串last_seen列，最后活跃时间已改存在last_seen_at
+func dropUserLastSeen() error {
+	const column = "last_seen"
+	migrator := model.DB.Migrator()
+	if !migrator.HasColumn(&model.User{}, column) {
+		log.Printf("列 users.%s 不存在，无需处理", column)
+		return nil
+	}
+	if err := migrator.DropColumn(&model.User{}, column); err != nil {
+		return err
+	}
+	log.Printf("已删除列 users.%s", column)
+	return nil
+}
+
+// extendMessageStatus 按模型定义修改messages.status的枚举值，增加delivered状态
+func extendMessageStatus() error {
+	if err := model.DB.Migrator().AlterColumn(&model.Message{}, "Status"); err != nil {
+		return err
+	}
+	log.Printf("已更新messages.status枚举")
+	return nil
+}
+
+// backfillFeedInbox 为启用关注时间线前已有的关注关系补齐收件箱
+func backfillFeedInbox() error {
+	processed, err := service.NewFeedService(model.DB).BackfillInboxes()
+	if err != nil {
+		return err
+	}
+	log.Printf("收件箱补齐完成，处理关注关系 %d 条", processed)
+	return nil
+}
+
+// resetLegacyOwners 将登录上线前的历史上传、草稿和发布内容的作者由旧的默认值1改回0（作者未知）
+// 登录上线前没有任何登录凭证，早于第一个登录凭证的记录都不可能属于已登录的用户
+func resetLegacyOwners() error {
+	var first model.UserCredential
+	if err := model.DB.Order("created_at").Limit(1).Find(&first).Error; err != nil {
+		return err
+	}
+	cutoff := first.CreatedAt
+	if first.ID == 0 {
+		cutoff = time.Now()
+	}
+
+	tables := []struct {
+		name  string
+		model interface{}
+	}{
+		{"media_files", &model.MediaFile{}},
+		{"drafts", &model.Draft{}},
+		{"contents", &model.Content{}},
+	}
+	for _, t := range tables {
+		result := model.DB.Model(t.model).Where("user_id = ? AND created_at < ?", 1, cutoff).Update("user_id", 0)
+		if result.Error != nil {
+			return result.Error
+		}
+		log.Printf("%s: 重置历史数据作者 %d 条", t.name, result.RowsAffected)
+	}
+	return nil
+}
+
+// backfillPostAuthors 为历史博客补齐发布者用户ID（user_id），屏蔽、关注和个人主页都依赖该字段
+// 只采用数字authorId且对应用户存在的博客；昵称可以被任何人改成相同的值，不能作为发布者的依据，
+// 因此非数字authorId的博客和只记录了作者昵称的轮播内容保持为0
+func backfillPostAuthors() error {
+	var blogs []*model.Blog
+	if err := model.DB.Select("id", "author_id").Where("user_id = ?", 0).Find(&blogs).Error; err != nil {
+		return err
+	}
+
+	var ids []uint
+	for _, blog := range blogs {
+		if id, err := strconv.ParseUint(blog.AuthorID, 10, 32); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	users, err := repository.NewUserRepository(model.DB).GetUsersByIDs(ids)
+	if err != nil {
+		return err
+	}
+	existing := make(map[uint]bool, len(users))
+	for _, user := range users {
+		existing[user.ID] = true
+	}
+
+	var count int
+	for _, blog := range blogs {
+		id, err := strconv.ParseUint(blog.AuthorID, 10, 32)
+		if err != nil || !existing[uint(id)] {
+			continue
+		}
+		if err := model.DB.Model(&model.Blog{}).Where("id = ?", blog.ID).Update("user_id", uint(id)).Error; err != nil {
+			return err
+		}
+		count++
+	}
+
+	log.Printf("发布者补齐完成，博客 %d/%d 条", count, len(blogs))
+	return nil
+}
+
+// backfillAccountTypes 按好友关系中标记的friend_type为已有的系统账号和AI机器人设置users.account_type
+// 公告收件人、官方账号标识和机器人回复都只看账号类型，同一用户被标记为两种类型时以系统账号为准
+func backfillAccountTypes() error {
+	for _, accountType := range []string{model.AccountTypeSystem, model.AccountTypeAIBot} {
+		result := model.DB.Model(&model.User{}).
+			Where("account_type = ? AND id IN (?)", model.AccountTypeNormal,
+				model.DB.Model(&model.Friendship{}).Select("friend_id").Where("friend_type = ?", accountType)).
+			Update("account_type", accountType)
+		if result.Error != nil {
+			return result.Error
+		}
+		log.Printf("设置为 %s 的账号 %d 个", accountType, result.RowsAffected)
+	}
+	return nil
+}
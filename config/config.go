@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -18,6 +19,93 @@ type Config struct {
 		Password string `mapstructure:"password"`
 		DBName   string `mapstructure:"dbname"`
 	} `mapstructure:"database"`
+
+	JWT struct {
+		Secret          string        `mapstructure:"secret"`
+		Issuer          string        `mapstructure:"issuer"`
+		AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl"`
+		RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
+	} `mapstructure:"jwt"`
+
+	Chat struct {
+		RecallWindow          time.Duration `mapstructure:"recall_window"`           // 发送后允许撤回的时长
+		EditWindow            time.Duration `mapstructure:"edit_window"`             // 发送后允许编辑的时长
+		AllowStrangerMessages bool          `mapstructure:"allow_stranger_messages"` // 用户未设置隐私时是否允许非好友发消息
+		TypingTimeout         time.Duration `mapstructure:"typing_timeout"`          // 输入状态未刷新多久后自动清除
+		AllowedReactions      []string      `mapstructure:"allowed_reactions"`       // 允许使用的表情回应，为空时使用默认列表
+	} `mapstructure:"chat"`
+
+	Presence struct {
+		AwayAfter     time.Duration `mapstructure:"away_after"`     // 无活动多久后置为离开
+		OfflineAfter  time.Duration `mapstructure:"offline_after"`  // 无活动多久后置为离线
+		SweepInterval time.Duration `mapstructure:"sweep_interval"` // 检查空闲用户的周期
+	} `mapstructure:"presence"`
+
+	Push struct {
+		QueueSize int `mapstructure:"queue_size"` // 待推送通知的队列长度，队列满时丢弃新通知
+		Workers   int `mapstructure:"workers"`    // 并发推送的协程数
+
+		APNs struct {
+			KeyFile    string `mapstructure:"key_file"` // .p8密钥文件，为空时不启用
+			KeyID      string `mapstructure:"key_id"`
+			TeamID     string `mapstructure:"team_id"`
+			Topic      string `mapstructure:"topic"` // 应用的Bundle ID
+			Production bool   `mapstructure:"production"`
+		} `mapstructure:"apns"`
+
+		FCM struct {
+			CredentialsFile string `mapstructure:"credentials_file"` // 服务账号JSON密钥文件，为空时不启用
+		} `mapstructure:"fcm"`
+
+		Webhook struct {
+			URL    string `mapstructure:"url"`    // 自建推送网关地址，为空时不启用
+			Secret string `mapstructure:"secret"` // 请求体签名密钥
+		} `mapstructure:"webhook"`
+
+		Log struct {
+			Enabled bool   `mapstructure:"enabled"`
+			File    string `mapstructure:"file"` // 为空时写入标准日志
+		} `mapstructure:"log"`
+	} `mapstructure:"push"`
+
+	Bot struct {
+		Responder   string        `mapstructure:"responder"`    // 回复器：rule或http
+		Workers     int           `mapstructure:"workers"`      // 并发回复的协程数，同一会话的消息总是由同一个协程按顺序处理
+		QueueSize   int           `mapstructure:"queue_size"`   // 每个协程的待回复队列长度
+		HistorySize int           `mapstructure:"history_size"` // 作为上下文的历史消息条数
+		Timeout     time.Duration `mapstructure:"timeout"`      // 单次生成回复的超时时间
+
+		Rules []struct {
+			Keyword string `mapstructure:"keyword"`
+			Reply   string `mapstructure:"reply"`
+		} `mapstructure:"rules"`
+		Fallback string `mapstructure:"fallback"` // 没有命中规则时的回复，为空时原样复述
+
+		HTTP struct {
+			URL          string `mapstructure:"url"` // 兼容OpenAI Chat Completions的接口地址
+			APIKey       string `mapstructure:"api_key"`
+			Model        string `mapstructure:"model"`
+			SystemPrompt string `mapstructure:"system_prompt"`
+		} `mapstructure:"http"`
+	} `mapstructure:"bot"`
+
+	Admin struct {
+		UserIDs               []uint `mapstructure:"user_ids"`                // 可以访问管理接口的用户ID
+		AnnouncementBatchSize int    `mapstructure:"announcement_batch_size"` // 群发公告时每批写入的接收者数量
+	} `mapstructure:"admin"`
+
+	Feed struct {
+		BigAccountFollowers int64 `mapstructure:"big_account_followers"` // 粉丝数达到该值的账号发布作品时不写入粉丝收件箱，改为读取时拉取
+		FanoutBatchSize     int   `mapstructure:"fanout_batch_size"`     // 写入粉丝收件箱时每批的粉丝数量
+		BackfillSize        int   `mapstructure:"backfill_size"`         // 关注普通账号时补入收件箱的最近作品数
+	} `mapstructure:"feed"`
+
+	Account struct {
+		DeletionGracePeriod time.Duration `mapstructure:"deletion_grace_period"` // 申请注销后的冷静期，期间可撤销
+		SweepInterval       time.Duration `mapstructure:"sweep_interval"`        // 检查到期注销申请和过期导出文件的周期
+		ExportDir           string        `mapstructure:"export_dir"`            // 个人数据导出压缩包的存放目录
+		ExportTTL           time.Duration `mapstructure:"export_ttl"`            // 导出压缩包生成后可下载的时长
+	} `mapstructure:"account"`
 }
 
 var AppConfig Config
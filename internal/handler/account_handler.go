@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"ticktok-service/internal/middleware"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/service"
+	"ticktok-service/pkg/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler 账号注销与个人数据导出处理器
+type AccountHandler struct {
+	accountService service.AccountService
+}
+
+// NewAccountHandler 创建新的账号处理器，账号服务在进程内共享
+func NewAccountHandler(accountService service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// RequestDeletion 申请注销账号
+func (h *AccountHandler) RequestDeletion(c *gin.Context) {
+	var req model.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	deletion, err := h.accountService.RequestDeletion(middleware.CurrentUserID(c), &req)
+	if err != nil {
+		failAccount(c, "申请注销失败", err)
+		return
+	}
+
+	util.Success(c, deletion)
+}
+
+// GetDeletion 获取注销申请状态
+func (h *AccountHandler) GetDeletion(c *gin.Context) {
+	deletion, err := h.accountService.GetDeletion(middleware.CurrentUserID(c))
+	if err != nil {
+		failAccount(c, "获取注销申请失败", err)
+		return
+	}
+
+	util.Success(c, deletion)
+}
+
+// CancelDeletion 撤销注销申请
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	if err := h.accountService.CancelDeletion(middleware.CurrentUserID(c)); err != nil {
+		failAccount(c, "撤销注销申请失败", err)
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// CreateExport 创建个人数据导出
+func (h *AccountHandler) CreateExport(c *gin.Context) {
+	export, err := h.accountService.CreateExport(middleware.CurrentUserID(c), requestBaseURL(c))
+	if err != nil {
+		failAccount(c, "创建数据导出失败", err)
+		return
+	}
+
+	util.Success(c, export)
+}
+
+// GetExports 获取个人数据导出记录
+func (h *AccountHandler) GetExports(c *gin.Context) {
+	exports, err := h.accountService.GetExports(middleware.CurrentUserID(c))
+	if err != nil {
+		failAccount(c, "获取数据导出失败", err)
+		return
+	}
+
+	util.Success(c, exports)
+}
+
+// DownloadExport 下载个人数据导出压缩包
+func (h *AccountHandler) DownloadExport(c *gin.Context) {
+	exportID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的导出ID")
+		return
+	}
+
+	path, fileName, err := h.accountService.GetExportFile(middleware.CurrentUserID(c), uint(exportID))
+	if err != nil {
+		failAccount(c, "下载数据导出失败", err)
+		return
+	}
+
+	c.FileAttachment(path, fileName)
+}
+
+// failAccount 根据账号错误类型返回对应的错误码
+func failAccount(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrExportNotFound), errors.Is(err, service.ErrDeletionNotFound):
+		util.Fail(c, 404, err.Error())
+	case errors.Is(err, service.ErrInvalidCredentials):
+		util.Fail(c, 403, "密码错误")
+	case errors.Is(err, service.ErrDeletionPending), errors.Is(err, service.ErrExportInProgress),
+		errors.Is(err, service.ErrExportNotReady):
+		util.Fail(c, 409, err.Error())
+	default:
+		util.Fail(c, 500, msg+": "+err.Error())
+	}
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"ticktok-service/internal/middleware"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
+	"ticktok-service/internal/service"
+	"ticktok-service/pkg/util"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminHandler 管理接口处理器
+type AdminHandler struct {
+	announcementService service.AnnouncementService
+}
+
+// NewAdminHandler 创建新的管理接口处理器
+func NewAdminHandler(db *gorm.DB, hub *ws.Hub) *AdminHandler {
+	return &AdminHandler{
+		announcementService: service.NewAnnouncementService(db, hub),
+	}
+}
+
+// CreateAnnouncement 以系统账号的名义群发公告
+func (h *AdminHandler) CreateAnnouncement(c *gin.Context) {
+	var req model.AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	announcement, err := h.announcementService.Broadcast(middleware.CurrentUserID(c), &req)
+	if err != nil {
+		failAnnouncement(c, "发送公告失败", err)
+		return
+	}
+
+	util.Success(c, announcement)
+}
+
+// GetAnnouncements 分页获取公告
+func (h *AdminHandler) GetAnnouncements(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	announcements, total, err := h.announcementService.GetAnnouncements(page, pageSize)
+	if err != nil {
+		util.Fail(c, 500, "获取公告列表失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, util.NewPageResult(announcements, total, page, pageSize))
+}
+
+// GetAnnouncement 获取公告及其群发进度
+func (h *AdminHandler) GetAnnouncement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的公告ID")
+		return
+	}
+
+	announcement, err := h.announcementService.GetAnnouncement(uint(id))
+	if err != nil {
+		failAnnouncement(c, "获取公告失败", err)
+		return
+	}
+
+	util.Success(c, announcement)
+}
+
+// failAnnouncement 根据公告错误类型返回对应的错误码
+func failAnnouncement(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrAnnouncementNotFound):
+		util.Fail(c, 404, err.Error())
+	case errors.Is(err, service.ErrNotSystemAccount), errors.Is(err, service.ErrInvalidMessage):
+		util.Fail(c, 400, err.Error())
+	default:
+		util.Fail(c, 500, msg+": "+err.Error())
+	}
+}
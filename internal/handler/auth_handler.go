@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"ticktok-service/internal/middleware"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/service"
+	"ticktok-service/pkg/util"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AuthHandler 认证相关处理器
+type AuthHandler struct {
+	authService service.AuthService
+}
+
+// NewAuthHandler 创建新的认证处理器
+func NewAuthHandler(db *gorm.DB) *AuthHandler {
+	return &AuthHandler{
+		authService: service.NewAuthService(db),
+	}
+}
+
+// Register 注册
+func (h *AuthHandler) Register(c *gin.Context) {
+	// 解析请求参数
+	var req model.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	// 注册用户
+	tokens, err := h.authService.Register(&req)
+	if err != nil {
+		if errors.Is(err, service.ErrUsernameTaken) {
+			util.Fail(c, 409, err.Error())
+			return
+		}
+		util.Fail(c, 500, "注册失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, tokens)
+}
+
+// Login 登录
+func (h *AuthHandler) Login(c *gin.Context) {
+	// 解析请求参数
+	var req model.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	// 登录
+	tokens, err := h.authService.Login(&req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			util.Fail(c, 401, err.Error())
+			return
+		}
+		util.Fail(c, 500, "登录失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, tokens)
+}
+
+// Refresh 刷新令牌
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	// 解析请求参数
+	var req model.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	// 刷新令牌
+	tokens, err := h.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		util.Fail(c, 401, "刷新令牌失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, tokens)
+}
+
+// Logout 退出登录
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	// 刷新令牌可选，未提供时注销全部设备
+	var req struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	if err := h.authService.Logout(userID, req.RefreshToken); err != nil {
+		util.Fail(c, 400, "退出登录失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, true)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"ticktok-service/internal/middleware"
+	"ticktok-service/internal/service"
+	"ticktok-service/pkg/util"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// BlockHandler 屏蔽相关处理器
+type BlockHandler struct {
+	blockService service.BlockService
+}
+
+// NewBlockHandler 创建新的屏蔽处理器
+func NewBlockHandler(db *gorm.DB) *BlockHandler {
+	return &BlockHandler{
+		blockService: service.NewBlockService(db),
+	}
+}
+
+// GetBlockedUsers 获取屏蔽列表
+func (h *BlockHandler) GetBlockedUsers(c *gin.Context) {
+	users, err := h.blockService.GetBlockedUsers(middleware.CurrentUserID(c))
+	if err != nil {
+		util.Fail(c, 500, "获取屏蔽列表失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, users)
+}
+
+// BlockUser 屏蔽用户
+func (h *BlockHandler) BlockUser(c *gin.Context) {
+	blockedID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的用户ID")
+		return
+	}
+
+	if err := h.blockService.BlockUser(middleware.CurrentUserID(c), uint(blockedID)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			util.Fail(c, 404, err.Error())
+		case errors.Is(err, service.ErrCannotBlockSelf):
+			util.Fail(c, 400, err.Error())
+		default:
+			util.Fail(c, 500, "屏蔽用户失败: "+err.Error())
+		}
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// UnblockUser 取消屏蔽
+func (h *BlockHandler) UnblockUser(c *gin.Context) {
+	blockedID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的用户ID")
+		return
+	}
+
+	if err := h.blockService.UnblockUser(middleware.CurrentUserID(c), uint(blockedID)); err != nil {
+		util.Fail(c, 500, "取消屏蔽失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, true)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"strconv"
+	"ticktok-service/internal/middleware"
 	"ticktok-service/internal/service"
 	"ticktok-service/pkg/util"
 
@@ -33,7 +34,7 @@ func (h *BlogHandler) GetBlogs(c *gin.Context) {
 	}
 
 	// 获取博客列表
-	blogs, total, err := h.blogService.GetBlogs(page, pageSize)
+	blogs, total, err := h.blogService.GetBlogs(middleware.CurrentUserID(c), page, pageSize)
 	if err != nil {
 		util.Fail(c, 500, "获取博客列表失败: "+err.Error())
 		return
@@ -54,9 +55,6 @@ func (h *BlogHandler) GetBlogs(c *gin.Context) {
 			tags = append(tags, tag.TagContent)
 		}
 		blogs[i].Tags = nil
-
-		// 设置假数据
-		blogs[i].IsFollowing = true
 	}
 
 	// 返回分页结果
@@ -75,7 +73,7 @@ func (h *BlogHandler) GetBlogDetail(c *gin.Context) {
 	}
 
 	// 获取博客详情
-	blog, err := h.blogService.GetBlogByID(uint(id))
+	blog, err := h.blogService.GetBlogByID(middleware.CurrentUserID(c), uint(id))
 	if err != nil {
 		util.Fail(c, 404, "博客不存在: "+err.Error())
 		return
@@ -93,10 +91,6 @@ func (h *BlogHandler) GetBlogDetail(c *gin.Context) {
 	// 	tags = append(tags, tag.TagContent)
 	// }
 	// // blog.Tags = nil
-	
-
-	// // 设置假数据
-	// blog.IsFollowing = true
 
 	util.Success(c, blog)
 }
@@ -121,7 +115,7 @@ func (h *BlogHandler) SearchBlogs(c *gin.Context) {
 	}
 
 	// 搜索博客
-	blogs, total, err := h.blogService.SearchBlogs(keyword, page, pageSize)
+	blogs, total, err := h.blogService.SearchBlogs(middleware.CurrentUserID(c), keyword, page, pageSize)
 	if err != nil {
 		util.Fail(c, 500, "搜索博客失败: "+err.Error())
 		return
@@ -142,9 +136,6 @@ func (h *BlogHandler) SearchBlogs(c *gin.Context) {
 			tags = append(tags, tag.TagContent)
 		}
 		blogs[i].Tags = nil
-
-		// 设置假数据
-		blogs[i].IsFollowing = true
 	}
 
 	// 返回分页结果
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"strconv"
+	"ticktok-service/internal/middleware"
+	"ticktok-service/internal/service"
+	"ticktok-service/pkg/util"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FeedHandler 关注时间线相关处理器
+type FeedHandler struct {
+	feedService service.FeedService
+}
+
+// NewFeedHandler 创建新的关注时间线处理器
+func NewFeedHandler(db *gorm.DB) *FeedHandler {
+	return &FeedHandler{
+		feedService: service.NewFeedService(db),
+	}
+}
+
+// GetFollowingFeed 获取关注的人发布的作品，before为上一页返回的nextCursor（毫秒），pageSize最大50
+func (h *FeedHandler) GetFollowingFeed(c *gin.Context) {
+	before, err := strconv.ParseInt(c.DefaultQuery("before", "0"), 10, 64)
+	if err != nil || before < 0 {
+		util.Fail(c, 400, "无效的before游标")
+		return
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if pageSize < 1 || pageSize > 50 {
+		pageSize = 20
+	}
+
+	feed, err := h.feedService.GetFollowingFeed(middleware.CurrentUserID(c), before, pageSize)
+	if err != nil {
+		util.Fail(c, 500, "获取关注时间线失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, feed)
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"ticktok-service/internal/middleware"
+	"ticktok-service/internal/service"
+	"ticktok-service/pkg/util"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// FollowHandler 关注相关处理器
+type FollowHandler struct {
+	followService service.FollowService
+}
+
+// NewFollowHandler 创建新的关注处理器
+func NewFollowHandler(db *gorm.DB) *FollowHandler {
+	return &FollowHandler{
+		followService: service.NewFollowService(db),
+	}
+}
+
+// Follow 关注用户
+func (h *FollowHandler) Follow(c *gin.Context) {
+	targetID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.followService.Follow(middleware.CurrentUserID(c), targetID)
+	if err != nil {
+		failFollow(c, "关注失败", err)
+		return
+	}
+
+	util.Success(c, status)
+}
+
+// Unfollow 取消关注
+func (h *FollowHandler) Unfollow(c *gin.Context) {
+	targetID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.followService.Unfollow(middleware.CurrentUserID(c), targetID)
+	if err != nil {
+		failFollow(c, "取消关注失败", err)
+		return
+	}
+
+	util.Success(c, status)
+}
+
+// GetFollowStatus 获取与用户的关注关系
+func (h *FollowHandler) GetFollowStatus(c *gin.Context) {
+	targetID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.followService.GetFollowStatus(middleware.CurrentUserID(c), targetID)
+	if err != nil {
+		failFollow(c, "获取关注关系失败", err)
+		return
+	}
+
+	util.Success(c, status)
+}
+
+// GetFollowers 分页获取用户的粉丝列表
+func (h *FollowHandler) GetFollowers(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	page, pageSize := parseFollowPage(c)
+
+	users, total, err := h.followService.GetFollowers(middleware.CurrentUserID(c), userID, page, pageSize)
+	if err != nil {
+		failFollow(c, "获取粉丝列表失败", err)
+		return
+	}
+
+	util.Success(c, util.NewPageResult(users, total, page, pageSize))
+}
+
+// GetFollowing 分页获取用户的关注列表
+func (h *FollowHandler) GetFollowing(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+	page, pageSize := parseFollowPage(c)
+
+	users, total, err := h.followService.GetFollowing(middleware.CurrentUserID(c), userID, page, pageSize)
+	if err != nil {
+		failFollow(c, "获取关注列表失败", err)
+		return
+	}
+
+	util.Success(c, util.NewPageResult(users, total, page, pageSize))
+}
+
+// parseUserID 解析路径中的用户ID，失败时已写入错误响应
+func parseUserID(c *gin.Context) (uint, bool) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的用户ID")
+		return 0, false
+	}
+	return uint(userID), true
+}
+
+// parseFollowPage 解析关注列表的分页参数，pageSize默认20，最大100
+func parseFollowPage(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// failFollow 根据关注错误类型返回对应的错误码
+func failFollow(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		util.Fail(c, 404, err.Error())
+	case errors.Is(err, service.ErrUserBlocked):
+		util.Fail(c, 403, err.Error())
+	case errors.Is(err, service.ErrCannotFollowSelf):
+		util.Fail(c, 400, err.Error())
+	default:
+		util.Fail(c, 500, msg+": "+err.Error())
+	}
+}
@@ -1,6 +1,11 @@
 package handler
 
 import (
+	"errors"
+	"strconv"
+	"ticktok-service/internal/middleware"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
 	"ticktok-service/internal/service"
 	"ticktok-service/pkg/util"
 
@@ -14,16 +19,16 @@ type FriendHandler struct {
 }
 
 // NewFriendHandler 创建新的好友处理器
-func NewFriendHandler(db *gorm.DB) *FriendHandler {
+func NewFriendHandler(db *gorm.DB, hub *ws.Hub, presence service.PresenceService, notifier service.NotificationService) *FriendHandler {
 	return &FriendHandler{
-		friendService: service.NewFriendService(db),
+		friendService: service.NewFriendService(db, hub, presence, notifier),
 	}
 }
 
 // GetFriends 获取好友列表
 func (h *FriendHandler) GetFriends(c *gin.Context) {
-	// 这里简化处理，实际应用中应该从JWT或会话中获取当前用户ID
-	userID := uint(1) // 假设当前用户ID为1
+	// 获取当前登录用户ID
+	userID := middleware.CurrentUserID(c)
 
 	// 获取好友列表
 	friends, err := h.friendService.GetFriendsByUserID(userID)
@@ -33,4 +38,146 @@ func (h *FriendHandler) GetFriends(c *gin.Context) {
 	}
 
 	util.Success(c, friends)
-} 
\ No newline at end of file
+}
+
+// GetUserFriends 查看其他用户的好友列表，是否可见由对方的隐私设置决定
+func (h *FriendHandler) GetUserFriends(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	friends, err := h.friendService.GetUserFriends(middleware.CurrentUserID(c), userID)
+	if err != nil {
+		failFriend(c, "获取好友列表失败", err)
+		return
+	}
+
+	util.Success(c, friends)
+}
+
+// SendFriendRequest 发送好友申请
+func (h *FriendHandler) SendFriendRequest(c *gin.Context) {
+	// 解析请求参数
+	var req model.SendFriendRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	request, err := h.friendService.SendFriendRequest(middleware.CurrentUserID(c), &req)
+	if err != nil {
+		failFriend(c, "发送好友申请失败", err)
+		return
+	}
+
+	util.Success(c, request)
+}
+
+// GetReceivedRequests 获取收到的待处理好友申请
+func (h *FriendHandler) GetReceivedRequests(c *gin.Context) {
+	requests, err := h.friendService.GetReceivedRequests(middleware.CurrentUserID(c))
+	if err != nil {
+		util.Fail(c, 500, "获取好友申请失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, requests)
+}
+
+// GetSentRequests 获取发出的待处理好友申请
+func (h *FriendHandler) GetSentRequests(c *gin.Context) {
+	requests, err := h.friendService.GetSentRequests(middleware.CurrentUserID(c))
+	if err != nil {
+		util.Fail(c, 500, "获取好友申请失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, requests)
+}
+
+// AcceptFriendRequest 通过好友申请
+func (h *FriendHandler) AcceptFriendRequest(c *gin.Context) {
+	requestID, ok := parseFriendRequestID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.friendService.AcceptFriendRequest(middleware.CurrentUserID(c), requestID); err != nil {
+		failFriend(c, "通过好友申请失败", err)
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// RejectFriendRequest 拒绝好友申请
+func (h *FriendHandler) RejectFriendRequest(c *gin.Context) {
+	requestID, ok := parseFriendRequestID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.friendService.RejectFriendRequest(middleware.CurrentUserID(c), requestID); err != nil {
+		failFriend(c, "拒绝好友申请失败", err)
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// CancelFriendRequest 撤销好友申请
+func (h *FriendHandler) CancelFriendRequest(c *gin.Context) {
+	requestID, ok := parseFriendRequestID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.friendService.CancelFriendRequest(middleware.CurrentUserID(c), requestID); err != nil {
+		failFriend(c, "撤销好友申请失败", err)
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// DeleteFriend 删除好友
+func (h *FriendHandler) DeleteFriend(c *gin.Context) {
+	friendID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的用户ID")
+		return
+	}
+
+	if err := h.friendService.DeleteFriend(middleware.CurrentUserID(c), uint(friendID)); err != nil {
+		failFriend(c, "删除好友失败", err)
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// parseFriendRequestID 解析路径中的好友申请ID，失败时已写入错误响应
+func parseFriendRequestID(c *gin.Context) (uint, bool) {
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的申请ID")
+		return 0, false
+	}
+	return uint(requestID), true
+}
+
+// failFriend 根据好友错误类型返回对应的错误码
+func failFriend(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrFriendRequestNotFound):
+		util.Fail(c, 404, err.Error())
+	case errors.Is(err, service.ErrUserBlocked), errors.Is(err, service.ErrFriendListHidden):
+		util.Fail(c, 403, err.Error())
+	case errors.Is(err, service.ErrCannotAddSelf), errors.Is(err, service.ErrAlreadyFriends),
+		errors.Is(err, service.ErrNotFriends), errors.Is(err, service.ErrFriendRequestExists):
+		util.Fail(c, 400, err.Error())
+	default:
+		util.Fail(c, 500, msg+": "+err.Error())
+	}
+}
@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"ticktok-service/internal/middleware"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
+	"ticktok-service/internal/service"
+	"ticktok-service/pkg/util"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GroupHandler 群聊相关处理器
+type GroupHandler struct {
+	groupService service.GroupService
+}
+
+// NewGroupHandler 创建新的群聊处理器
+func NewGroupHandler(db *gorm.DB, hub *ws.Hub, notifier service.NotificationService) *GroupHandler {
+	return &GroupHandler{
+		groupService: service.NewGroupService(db, hub, notifier),
+	}
+}
+
+// CreateGroup 创建群聊
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	// 解析请求参数
+	var req model.CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	// 创建群聊
+	group, err := h.groupService.CreateGroup(middleware.CurrentUserID(c), &req)
+	if err != nil {
+		failGroup(c, "创建群聊失败", err)
+		return
+	}
+
+	util.Success(c, group)
+}
+
+// GetGroup 获取群聊信息
+func (h *GroupHandler) GetGroup(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	group, err := h.groupService.GetGroup(middleware.CurrentUserID(c), groupID)
+	if err != nil {
+		failGroup(c, "获取群聊信息失败", err)
+		return
+	}
+
+	util.Success(c, group)
+}
+
+// GetMembers 获取群成员列表
+func (h *GroupHandler) GetMembers(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.groupService.GetMembers(middleware.CurrentUserID(c), groupID)
+	if err != nil {
+		failGroup(c, "获取群成员失败", err)
+		return
+	}
+
+	util.Success(c, members)
+}
+
+// InviteMembers 邀请成员
+func (h *GroupHandler) InviteMembers(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	// 解析请求参数
+	var req model.InviteMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	if err := h.groupService.InviteMembers(middleware.CurrentUserID(c), groupID, req.UserIDs); err != nil {
+		failGroup(c, "邀请成员失败", err)
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// LeaveGroup 退出群聊
+func (h *GroupHandler) LeaveGroup(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.groupService.LeaveGroup(middleware.CurrentUserID(c), groupID); err != nil {
+		failGroup(c, "退出群聊失败", err)
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// KickMember 移出成员
+func (h *GroupHandler) KickMember(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+	targetID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的用户ID")
+		return
+	}
+
+	if err := h.groupService.KickMember(middleware.CurrentUserID(c), groupID, uint(targetID)); err != nil {
+		failGroup(c, "移出成员失败", err)
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// UpdateMemberRole 设置成员角色
+func (h *GroupHandler) UpdateMemberRole(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+	targetID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的用户ID")
+		return
+	}
+
+	// 解析请求参数
+	var req model.UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	if err := h.groupService.UpdateMemberRole(middleware.CurrentUserID(c), groupID, uint(targetID), req.Role); err != nil {
+		failGroup(c, "设置成员角色失败", err)
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// SendGroupMessage 发送群消息
+func (h *GroupHandler) SendGroupMessage(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	// 解析请求参数
+	var req model.GroupMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	message, err := h.groupService.SendGroupMessage(middleware.CurrentUserID(c), groupID, &req)
+	if err != nil {
+		failGroup(c, "发送群消息失败", err)
+		return
+	}
+
+	util.Success(c, message)
+}
+
+// GetGroupChatHistory 获取群聊历史记录
+func (h *GroupHandler) GetGroupChatHistory(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+	query, ok := parseChatHistoryQuery(c)
+	if !ok {
+		return
+	}
+
+	messages, err := h.groupService.GetGroupChatHistory(middleware.CurrentUserID(c), groupID, query)
+	if err != nil {
+		failGroup(c, "获取群聊历史记录失败", err)
+		return
+	}
+
+	util.Success(c, messages)
+}
+
+// MarkGroupAsRead 标记群消息已读
+func (h *GroupHandler) MarkGroupAsRead(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.groupService.MarkGroupAsRead(middleware.CurrentUserID(c), groupID); err != nil {
+		failGroup(c, "标记群消息已读失败", err)
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// GetGroupReadCursors 获取群成员的阅读游标
+func (h *GroupHandler) GetGroupReadCursors(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	cursors, err := h.groupService.GetGroupReadCursors(middleware.CurrentUserID(c), groupID)
+	if err != nil {
+		failGroup(c, "获取阅读游标失败", err)
+		return
+	}
+
+	util.Success(c, cursors)
+}
+
+// MuteGroup 开启群聊免打扰
+func (h *GroupHandler) MuteGroup(c *gin.Context) {
+	h.setGroupMuted(c, true)
+}
+
+// UnmuteGroup 关闭群聊免打扰
+func (h *GroupHandler) UnmuteGroup(c *gin.Context) {
+	h.setGroupMuted(c, false)
+}
+
+// setGroupMuted 设置路径中群聊的免打扰状态
+func (h *GroupHandler) setGroupMuted(c *gin.Context, muted bool) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.groupService.SetGroupMuted(middleware.CurrentUserID(c), groupID, muted); err != nil {
+		failGroup(c, "设置免打扰失败", err)
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// UpdateGroupSetting 更新群聊会话设置（置顶、归档、草稿）
+func (h *GroupHandler) UpdateGroupSetting(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateConversationSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	setting, err := h.groupService.UpdateGroupSetting(middleware.CurrentUserID(c), groupID, &req)
+	if err != nil {
+		failGroup(c, "更新会话设置失败", err)
+		return
+	}
+
+	util.Success(c, setting)
+}
+
+// DeleteGroupConversation 删除群聊会话的聊天记录，只对自己生效，不退出群聊
+func (h *GroupHandler) DeleteGroupConversation(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	setting, err := h.groupService.DeleteGroupConversation(middleware.CurrentUserID(c), groupID)
+	if err != nil {
+		failGroup(c, "删除会话失败", err)
+		return
+	}
+
+	util.Success(c, setting)
+}
+
+// ExportGroupChat 导出群聊记录，format可选json（默认）、txt、html，以附件形式流式下载
+func (h *GroupHandler) ExportGroupChat(c *gin.Context) {
+	groupID, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.groupService.ExportGroupChat(middleware.CurrentUserID(c), groupID, c.DefaultQuery("format", model.ExportFormatJSON), requestBaseURL(c))
+	if err != nil {
+		failGroup(c, "导出聊天记录失败", err)
+		return
+	}
+
+	streamExport(c, export)
+}
+
+// parseGroupID 解析路径中的群ID，失败时已写入错误响应
+func parseGroupID(c *gin.Context) (uint, bool) {
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的群ID")
+		return 0, false
+	}
+	return uint(groupID), true
+}
+
+// failGroup 根据群聊错误类型返回对应的错误码
+func failGroup(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrGroupNotFound):
+		util.Fail(c, 404, err.Error())
+	case errors.Is(err, service.ErrNotGroupMember), errors.Is(err, service.ErrGroupPermission):
+		util.Fail(c, 403, err.Error())
+	case errors.Is(err, service.ErrNoMembersInvited), errors.Is(err, service.ErrInvalidMessage),
+		errors.Is(err, service.ErrInvalidReply), errors.Is(err, service.ErrInvalidExportFormat):
+		util.Fail(c, 400, err.Error())
+	default:
+		util.Fail(c, 500, msg+": "+err.Error())
+	}
+}
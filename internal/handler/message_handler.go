@@ -1,10 +1,17 @@
 package handler
 
 import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
 	"strconv"
+	"ticktok-service/internal/middleware"
 	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
 	"ticktok-service/internal/service"
 	"ticktok-service/pkg/util"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -16,19 +23,26 @@ type MessageHandler struct {
 }
 
 // NewMessageHandler 创建新的消息处理器
-func NewMessageHandler(db *gorm.DB) *MessageHandler {
+func NewMessageHandler(db *gorm.DB, hub *ws.Hub, notifier service.NotificationService, bots service.BotService) *MessageHandler {
 	return &MessageHandler{
-		messageService: service.NewMessageService(db),
+		messageService: service.NewMessageService(db, hub, notifier, bots),
 	}
 }
 
 // GetMessages 获取消息列表
 func (h *MessageHandler) GetMessages(c *gin.Context) {
-	// 这里简化处理，实际应用中应该从JWT或会话中获取当前用户ID
-	userID := uint(1) // 假设当前用户ID为1
+	// 获取当前登录用户ID
+	userID := middleware.CurrentUserID(c)
+
+	// archived=true时获取归档的会话
+	archived, err := strconv.ParseBool(c.DefaultQuery("archived", "false"))
+	if err != nil {
+		util.Fail(c, 400, "无效的archived参数")
+		return
+	}
 
 	// 获取消息列表
-	messages, err := h.messageService.GetMessageList(userID)
+	messages, err := h.messageService.GetMessageList(userID, archived)
 	if err != nil {
 		util.Fail(c, 500, "获取消息列表失败: "+err.Error())
 		return
@@ -47,16 +61,17 @@ func (h *MessageHandler) GetChatHistory(c *gin.Context) {
 		return
 	}
 
-	// 获取当前用户ID（查询参数）
-	currentUserIDStr := c.DefaultQuery("currentUserId", "1") // 默认为1
-	currentUserID, err := strconv.ParseUint(currentUserIDStr, 10, 32)
-	if err != nil {
-		util.Fail(c, 400, "无效的当前用户ID")
+	// 解析游标参数
+	query, ok := parseChatHistoryQuery(c)
+	if !ok {
 		return
 	}
 
+	// 获取当前登录用户ID
+	currentUserID := middleware.CurrentUserID(c)
+
 	// 获取聊天历史记录
-	messages, err := h.messageService.GetChatHistory(uint(currentUserID), uint(userID))
+	messages, err := h.messageService.GetChatHistory(currentUserID, uint(userID), query)
 	if err != nil {
 		util.Fail(c, 500, "获取聊天历史记录失败: "+err.Error())
 		return
@@ -74,10 +89,13 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
+	// 发送者始终为当前登录用户
+	req.SenderID = middleware.CurrentUserID(c)
+
 	// 发送消息
 	message, err := h.messageService.SendMessage(&req)
 	if err != nil {
-		util.Fail(c, 500, "发送消息失败: "+err.Error())
+		failMessage(c, "发送消息失败", err)
 		return
 	}
 
@@ -94,20 +112,402 @@ func (h *MessageHandler) MarkAsRead(c *gin.Context) {
 		return
 	}
 
-	// 获取当前用户ID（查询参数）
-	currentUserIDStr := c.DefaultQuery("currentUserId", "1") // 默认为1
-	currentUserID, err := strconv.ParseUint(currentUserIDStr, 10, 32)
-	if err != nil {
-		util.Fail(c, 400, "无效的当前用户ID")
+	lastMessageID, ok := parseLastMessageID(c)
+	if !ok {
 		return
 	}
 
+	// 获取当前登录用户ID
+	currentUserID := middleware.CurrentUserID(c)
+
 	// 标记消息为已读
-	success, err := h.messageService.MarkAsRead(uint(currentUserID), uint(userID))
+	success, err := h.messageService.MarkAsRead(currentUserID, uint(userID), lastMessageID)
 	if err != nil {
 		util.Fail(c, 500, "标记消息已读失败: "+err.Error())
 		return
 	}
 
 	util.Success(c, success)
-} 
\ No newline at end of file
+}
+
+// MarkAsDelivered 确认消息已送达
+func (h *MessageHandler) MarkAsDelivered(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的用户ID")
+		return
+	}
+	lastMessageID, ok := parseLastMessageID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.messageService.MarkAsDelivered(middleware.CurrentUserID(c), uint(userID), lastMessageID); err != nil {
+		util.Fail(c, 500, "确认消息送达失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// GetReadCursors 获取单聊双方的阅读游标
+func (h *MessageHandler) GetReadCursors(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的用户ID")
+		return
+	}
+
+	cursors, err := h.messageService.GetReadCursors(middleware.CurrentUserID(c), uint(userID))
+	if err != nil {
+		util.Fail(c, 500, "获取阅读游标失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, cursors)
+}
+
+// SearchMessages 全文搜索消息
+func (h *MessageHandler) SearchMessages(c *gin.Context) {
+	query, ok := parseMessageSearchQuery(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.messageService.SearchMessages(middleware.CurrentUserID(c), query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSearch) {
+			util.Fail(c, 400, err.Error())
+			return
+		}
+		util.Fail(c, 500, "搜索消息失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, result)
+}
+
+// MuteConversation 开启单聊免打扰
+func (h *MessageHandler) MuteConversation(c *gin.Context) {
+	h.setConversationMuted(c, true)
+}
+
+// UnmuteConversation 关闭单聊免打扰
+func (h *MessageHandler) UnmuteConversation(c *gin.Context) {
+	h.setConversationMuted(c, false)
+}
+
+// UpdateConversationSetting 更新与路径中用户的单聊会话设置（置顶、归档、草稿）
+func (h *MessageHandler) UpdateConversationSetting(c *gin.Context) {
+	peerID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的用户ID")
+		return
+	}
+
+	var req model.UpdateConversationSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	setting, err := h.messageService.UpdateConversationSetting(middleware.CurrentUserID(c), uint(peerID), &req)
+	if err != nil {
+		util.Fail(c, 500, "更新会话设置失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, setting)
+}
+
+// DeleteConversation 删除与路径中用户的单聊会话，只对自己生效
+func (h *MessageHandler) DeleteConversation(c *gin.Context) {
+	peerID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的用户ID")
+		return
+	}
+
+	setting, err := h.messageService.DeleteConversation(middleware.CurrentUserID(c), uint(peerID))
+	if err != nil {
+		util.Fail(c, 500, "删除会话失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, setting)
+}
+
+// ExportChat 导出与路径中用户的单聊记录，format可选json（默认）、txt、html，以附件形式流式下载
+func (h *MessageHandler) ExportChat(c *gin.Context) {
+	peerID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的用户ID")
+		return
+	}
+
+	export, err := h.messageService.ExportChat(middleware.CurrentUserID(c), uint(peerID), c.DefaultQuery("format", model.ExportFormatJSON), requestBaseURL(c))
+	if err != nil {
+		failMessage(c, "导出聊天记录失败", err)
+		return
+	}
+
+	streamExport(c, export)
+}
+
+// setConversationMuted 设置与路径中用户的单聊免打扰状态
+func (h *MessageHandler) setConversationMuted(c *gin.Context, muted bool) {
+	peerID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的用户ID")
+		return
+	}
+
+	if err := h.messageService.SetConversationMuted(middleware.CurrentUserID(c), uint(peerID), muted); err != nil {
+		util.Fail(c, 500, "设置免打扰失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// RecallMessage 撤回消息
+func (h *MessageHandler) RecallMessage(c *gin.Context) {
+	messageID, ok := parseMessageID(c)
+	if !ok {
+		return
+	}
+
+	message, err := h.messageService.RecallMessage(middleware.CurrentUserID(c), messageID)
+	if err != nil {
+		failMessage(c, "撤回消息失败", err)
+		return
+	}
+
+	util.Success(c, message)
+}
+
+// EditMessage 编辑消息
+func (h *MessageHandler) EditMessage(c *gin.Context) {
+	messageID, ok := parseMessageID(c)
+	if !ok {
+		return
+	}
+
+	// 解析请求参数
+	var req model.EditMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	message, err := h.messageService.EditMessage(middleware.CurrentUserID(c), messageID, req.Content)
+	if err != nil {
+		failMessage(c, "编辑消息失败", err)
+		return
+	}
+
+	util.Success(c, message)
+}
+
+// AddReaction 为消息添加表情回应
+func (h *MessageHandler) AddReaction(c *gin.Context) {
+	messageID, ok := parseMessageID(c)
+	if !ok {
+		return
+	}
+
+	var req model.ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	reactions, err := h.messageService.AddReaction(middleware.CurrentUserID(c), messageID, req.Emoji)
+	if err != nil {
+		failMessage(c, "添加回应失败", err)
+		return
+	}
+
+	util.Success(c, reactions)
+}
+
+// RemoveReaction 取消表情回应
+func (h *MessageHandler) RemoveReaction(c *gin.Context) {
+	messageID, ok := parseMessageID(c)
+	if !ok {
+		return
+	}
+
+	reactions, err := h.messageService.RemoveReaction(middleware.CurrentUserID(c), messageID, c.Param("emoji"))
+	if err != nil {
+		failMessage(c, "取消回应失败", err)
+		return
+	}
+
+	util.Success(c, reactions)
+}
+
+// GetMessageEdits 获取消息编辑历史
+func (h *MessageHandler) GetMessageEdits(c *gin.Context) {
+	messageID, ok := parseMessageID(c)
+	if !ok {
+		return
+	}
+
+	edits, err := h.messageService.GetMessageEdits(middleware.CurrentUserID(c), messageID)
+	if err != nil {
+		failMessage(c, "获取编辑历史失败", err)
+		return
+	}
+
+	util.Success(c, edits)
+}
+
+// parseMessageID 解析路径中的消息ID，失败时已写入错误响应
+func parseMessageID(c *gin.Context) (uint, bool) {
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的消息ID")
+		return 0, false
+	}
+	return uint(messageID), true
+}
+
+// parseLastMessageID 解析可选的lastMessageId参数，未指定时为0，失败时已写入错误响应
+func parseLastMessageID(c *gin.Context) (uint, bool) {
+	lastMessageID, err := strconv.ParseUint(c.DefaultQuery("lastMessageId", "0"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的lastMessageId")
+		return 0, false
+	}
+	return uint(lastMessageID), true
+}
+
+// streamExport 以附件形式逐批写出导出的聊天记录，开始写出后出错只能记录日志并中断响应
+func streamExport(c *gin.Context, export *service.ChatExport) {
+	c.Header("Content-Type", export.ContentType)
+	c.Header("Content-Disposition", `attachment; filename="`+export.FileName+`"`)
+	c.Status(http.StatusOK)
+	c.Stream(func(w io.Writer) bool {
+		more, err := export.Next(w)
+		if err != nil {
+			log.Printf("导出聊天记录%s失败: %v", export.FileName, err)
+			return false
+		}
+		return more
+	})
+}
+
+// requestBaseURL 根据请求推断站点地址，用于把上传文件的相对地址补全为完整链接
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// failMessage 根据消息错误类型返回对应的错误码
+func failMessage(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrMessageNotFound), errors.Is(err, service.ErrUserNotFound):
+		util.Fail(c, 404, err.Error())
+	case errors.Is(err, service.ErrNotMessageSender), errors.Is(err, service.ErrNotFriends),
+		errors.Is(err, service.ErrUserBlocked), errors.Is(err, service.ErrMessagesRestricted):
+		util.Fail(c, 403, err.Error())
+	case errors.Is(err, service.ErrRecallExpired), errors.Is(err, service.ErrEditExpired),
+		errors.Is(err, service.ErrMessageNotEditable), errors.Is(err, service.ErrInvalidMessage),
+		errors.Is(err, service.ErrMessageRecalled), errors.Is(err, service.ErrInvalidReply),
+		errors.Is(err, service.ErrInvalidReaction), errors.Is(err, service.ErrInvalidExportFormat):
+		util.Fail(c, 400, err.Error())
+	default:
+		util.Fail(c, 500, msg+": "+err.Error())
+	}
+}
+
+// parseChatHistoryQuery 解析聊天历史的游标参数，失败时已写入错误响应
+func parseChatHistoryQuery(c *gin.Context) (*model.ChatHistoryQuery, bool) {
+	before, err := strconv.ParseUint(c.DefaultQuery("before", "0"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的before游标")
+		return nil, false
+	}
+	after, err := strconv.ParseUint(c.DefaultQuery("after", "0"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的after游标")
+		return nil, false
+	}
+	if before > 0 && after > 0 {
+		util.Fail(c, 400, "before和after不能同时指定")
+		return nil, false
+	}
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	return &model.ChatHistoryQuery{
+		Before: uint(before),
+		After:  uint(after),
+		Limit:  pageSize,
+	}, true
+}
+
+// parseMessageSearchQuery 解析消息搜索参数，失败时已写入错误响应
+// start和end为毫秒时间戳，pageSize最大50，context为每条结果前后附带的消息数（0~5）
+func parseMessageSearchQuery(c *gin.Context) (*model.MessageSearchQuery, bool) {
+	query := &model.MessageSearchQuery{
+		Keyword: c.Query("q"),
+		Type:    c.Query("type"),
+	}
+	if query.Keyword == "" {
+		util.Fail(c, 400, "搜索关键词不能为空")
+		return nil, false
+	}
+
+	uintParams := []struct {
+		name   string
+		target *uint
+	}{
+		{"friendId", &query.FriendID},
+		{"groupId", &query.GroupID},
+		{"before", &query.Before},
+	}
+	for _, param := range uintParams {
+		value, err := strconv.ParseUint(c.DefaultQuery(param.name, "0"), 10, 32)
+		if err != nil {
+			util.Fail(c, 400, "无效的"+param.name)
+			return nil, false
+		}
+		*param.target = uint(value)
+	}
+
+	timeParams := []struct {
+		name   string
+		target *time.Time
+	}{
+		{"start", &query.Start},
+		{"end", &query.End},
+	}
+	for _, param := range timeParams {
+		value, err := strconv.ParseInt(c.DefaultQuery(param.name, "0"), 10, 64)
+		if err != nil || value < 0 {
+			util.Fail(c, 400, "无效的"+param.name)
+			return nil, false
+		}
+		if value > 0 {
+			*param.target = time.UnixMilli(value)
+		}
+	}
+
+	query.Limit, _ = strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if query.Limit < 1 || query.Limit > 50 {
+		query.Limit = 20
+	}
+	query.Context, _ = strconv.Atoi(c.DefaultQuery("context", "2"))
+	if query.Context < 0 || query.Context > 5 {
+		query.Context = 2
+	}
+
+	return query, true
+}
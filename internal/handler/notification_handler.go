@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"ticktok-service/internal/middleware"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/service"
+	"ticktok-service/pkg/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler 推送通知相关处理器
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+// NewNotificationHandler 创建新的推送通知处理器，推送服务在进程内共享
+func NewNotificationHandler(notifier service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notifier,
+	}
+}
+
+// RegisterDevice 登记设备推送令牌
+func (h *NotificationHandler) RegisterDevice(c *gin.Context) {
+	var req model.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	if err := h.notificationService.RegisterDevice(middleware.CurrentUserID(c), &req); err != nil {
+		if errors.Is(err, service.ErrUnsupportedProvider) {
+			util.Fail(c, 400, err.Error())
+			return
+		}
+		util.Fail(c, 500, "登记推送令牌失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// UnregisterDevice 注销设备推送令牌
+func (h *NotificationHandler) UnregisterDevice(c *gin.Context) {
+	var req model.UnregisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	if err := h.notificationService.UnregisterDevice(middleware.CurrentUserID(c), req.Token); err != nil {
+		util.Fail(c, 500, "注销推送令牌失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, true)
+}
+
+// GetPreference 获取推送偏好
+func (h *NotificationHandler) GetPreference(c *gin.Context) {
+	preference, err := h.notificationService.GetPreference(middleware.CurrentUserID(c))
+	if err != nil {
+		util.Fail(c, 500, "获取推送偏好失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, preference)
+}
+
+// UpdatePreference 更新推送偏好
+func (h *NotificationHandler) UpdatePreference(c *gin.Context) {
+	var req model.UpdateNotificationPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	preference, err := h.notificationService.UpdatePreference(middleware.CurrentUserID(c), &req)
+	if err != nil {
+		util.Fail(c, 500, "更新推送偏好失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, preference)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"ticktok-service/internal/middleware"
+	"ticktok-service/internal/service"
+	"ticktok-service/pkg/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PresenceHandler 在线状态相关处理器
+type PresenceHandler struct {
+	presenceService service.PresenceService
+}
+
+// NewPresenceHandler 创建新的在线状态处理器，在线状态服务在进程内共享
+func NewPresenceHandler(presence service.PresenceService) *PresenceHandler {
+	return &PresenceHandler{
+		presenceService: presence,
+	}
+}
+
+// GetPresenceBatch 批量获取用户在线状态
+func (h *PresenceHandler) GetPresenceBatch(c *gin.Context) {
+	// 解析请求参数
+	var req struct {
+		UserIDs []uint `json:"userIds" binding:"required,max=200"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	// 获取在线状态
+	presences, err := h.presenceService.GetPresence(middleware.CurrentUserID(c), req.UserIDs)
+	if err != nil {
+		util.Fail(c, 500, "获取在线状态失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, presences)
+}
@@ -3,7 +3,9 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"ticktok-service/internal/middleware"
 	"ticktok-service/internal/model"
+	"ticktok-service/internal/service"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -13,20 +15,22 @@ import (
 
 // PublishHandler 发布相关处理器
 type PublishHandler struct {
-	db *gorm.DB
+	db          *gorm.DB
+	feedService service.FeedService
 }
 
 // NewPublishHandler 创建新的发布处理器
 func NewPublishHandler(db *gorm.DB) *PublishHandler {
 	return &PublishHandler{
-		db: db,
+		db:          db,
+		feedService: service.NewFeedService(db),
 	}
 }
 
 // SaveDraft 保存草稿
 func (h *PublishHandler) SaveDraft(c *gin.Context) {
-	// 开发模式：使用固定用户ID
-	userID := uint(1)
+	// 获取当前登录用户ID
+	userID := middleware.CurrentUserID(c)
 	
 	// 解析请求参数
 	var req model.PublishMessage
@@ -86,8 +90,8 @@ func (h *PublishHandler) SaveDraft(c *gin.Context) {
 
 // PublishContent 发布内容
 func (h *PublishHandler) PublishContent(c *gin.Context) {
-	// 开发模式：使用固定用户ID
-	userID := uint(1)
+	// 获取当前登录用户ID
+	userID := middleware.CurrentUserID(c)
 	
 	// 解析请求参数
 	var req model.PublishMessage
@@ -142,6 +146,9 @@ func (h *PublishHandler) PublishContent(c *gin.Context) {
 		return
 	}
 	
+	// 分发到粉丝的关注时间线
+	h.feedService.Publish(userID, model.PostTypeContent, publishID, content.Visibility, content.CreatedAt)
+	
 	// 返回成功响应
 	c.JSON(http.StatusOK, model.PublishContentResponse{
 		Success:   true,
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"ticktok-service/internal/middleware"
+	"ticktok-service/internal/pkg/ws"
+	"ticktok-service/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -9,38 +11,175 @@ import (
 
 // RegisterRoutes 注册路由
 func RegisterRoutes(r *gin.Engine, db *gorm.DB) {
+	// 创建WebSocket连接中心
+	hub := ws.NewHub()
+
+	// 创建在线状态服务，由连接中心的回调维护用户在线状态
+	presence := service.NewPresenceService(db, hub)
+	hub.SetPresenceListener(presence)
+	presence.Start()
+
+	// 创建输入状态服务，处理客户端上报的输入状态
+	typing := service.NewTypingService(db, hub)
+	hub.HandleEvent(ws.EventChatTyping, typing.HandleTyping)
+	typing.Start()
+
+	// 创建离线推送服务，接收者没有在线设备时由各服务投递通知
+	notifier := service.NewNotificationService(db)
+	notifier.Start()
+
+	// 创建机器人运行时，自动回复发给AI机器人好友的消息
+	bots := service.NewBotService(db, hub, notifier)
+	bots.Start()
+
+	// 创建账号服务，定期执行冷静期已结束的注销并清理过期的数据导出
+	account := service.NewAccountService(db, hub)
+	account.Start()
+
 	// 创建各种处理器
-	friendHandler := NewFriendHandler(db)
-	messageHandler := NewMessageHandler(db)
+	authHandler := NewAuthHandler(db)
+	friendHandler := NewFriendHandler(db, hub, presence, notifier)
+	messageHandler := NewMessageHandler(db, hub, notifier, bots)
 	userHandler := NewUserHandler(db)
 	blogHandler := NewBlogHandler()
 	productHandler := NewProductHandler(db)
 	slideHandler := NewSlideHandler(db)
 	uploadHandler := NewUploadHandler(db)
 	publishHandler := NewPublishHandler(db)
+	wsHandler := NewWSHandler(hub)
+	groupHandler := NewGroupHandler(db, hub, notifier)
+	blockHandler := NewBlockHandler(db)
+	presenceHandler := NewPresenceHandler(presence)
+	notificationHandler := NewNotificationHandler(notifier)
+	adminHandler := NewAdminHandler(db, hub)
+	followHandler := NewFollowHandler(db)
+	feedHandler := NewFeedHandler(db)
+	accountHandler := NewAccountHandler(account)
 
 	// API路由组
 	api := r.Group("/api")
 	{
+		// 认证相关路由（无需登录）
+		auth := api.Group("/auth")
+		{
+			auth.POST("/register", authHandler.Register)
+			auth.POST("/login", authHandler.Login)
+			auth.POST("/refresh", authHandler.Refresh)
+		}
+
+		// 以下路由需要登录
+		authorized := api.Group("", middleware.Auth())
+
+		// 退出登录
+		authorized.POST("/auth/logout", authHandler.Logout)
+
 		// 好友相关路由
-		api.GET("/friends", friendHandler.GetFriends)
+		authorized.GET("/friends", friendHandler.GetFriends)
+		authorized.DELETE("/friends/:userId", friendHandler.DeleteFriend)
+		authorized.POST("/friends/requests", friendHandler.SendFriendRequest)
+		authorized.GET("/friends/requests", friendHandler.GetReceivedRequests)
+		authorized.GET("/friends/requests/sent", friendHandler.GetSentRequests)
+		authorized.POST("/friends/requests/:id/accept", friendHandler.AcceptFriendRequest)
+		authorized.POST("/friends/requests/:id/reject", friendHandler.RejectFriendRequest)
+		authorized.POST("/friends/requests/:id/cancel", friendHandler.CancelFriendRequest)
 
 		// 消息相关路由
-		api.GET("/messages", messageHandler.GetMessages)
-		api.GET("/chat/:userId", messageHandler.GetChatHistory)
-		api.POST("/chat/send", messageHandler.SendMessage)
-		api.PUT("/chat/read/:userId", messageHandler.MarkAsRead)
+		authorized.GET("/messages", messageHandler.GetMessages)
+		authorized.GET("/chat/search", messageHandler.SearchMessages)
+		authorized.GET("/chat/:userId", messageHandler.GetChatHistory)
+		authorized.POST("/chat/send", messageHandler.SendMessage)
+		authorized.PUT("/chat/read/:userId", messageHandler.MarkAsRead)
+		authorized.PUT("/chat/delivered/:userId", messageHandler.MarkAsDelivered)
+		authorized.GET("/chat/cursors/:userId", messageHandler.GetReadCursors)
+		authorized.POST("/chat/recall/:id", messageHandler.RecallMessage)
+		authorized.PUT("/chat/messages/:id", messageHandler.EditMessage)
+		authorized.GET("/chat/messages/:id/edits", messageHandler.GetMessageEdits)
+		authorized.POST("/chat/messages/:id/reactions", messageHandler.AddReaction)
+		authorized.DELETE("/chat/messages/:id/reactions/:emoji", messageHandler.RemoveReaction)
+		authorized.PUT("/chat/mute/:userId", messageHandler.MuteConversation)
+		authorized.DELETE("/chat/mute/:userId", messageHandler.UnmuteConversation)
+		authorized.PUT("/chat/settings/:userId", messageHandler.UpdateConversationSetting)
+		authorized.DELETE("/chat/:userId", messageHandler.DeleteConversation)
+		authorized.GET("/chat/export/:userId", messageHandler.ExportChat)
+
+		// 屏蔽相关路由
+		authorized.GET("/blocks", blockHandler.GetBlockedUsers)
+		authorized.POST("/blocks/:userId", blockHandler.BlockUser)
+		authorized.DELETE("/blocks/:userId", blockHandler.UnblockUser)
+
+		// 群聊相关路由
+		groups := authorized.Group("/groups")
+		{
+			groups.POST("", groupHandler.CreateGroup)
+			groups.GET("/:id", groupHandler.GetGroup)
+			groups.GET("/:id/members", groupHandler.GetMembers)
+			groups.POST("/:id/members", groupHandler.InviteMembers)
+			groups.DELETE("/:id/members/:userId", groupHandler.KickMember)
+			groups.PUT("/:id/members/:userId/role", groupHandler.UpdateMemberRole)
+			groups.POST("/:id/leave", groupHandler.LeaveGroup)
+			groups.GET("/:id/messages", groupHandler.GetGroupChatHistory)
+			groups.POST("/:id/messages", groupHandler.SendGroupMessage)
+			groups.PUT("/:id/read", groupHandler.MarkGroupAsRead)
+			groups.GET("/:id/cursors", groupHandler.GetGroupReadCursors)
+			groups.PUT("/:id/mute", groupHandler.MuteGroup)
+			groups.DELETE("/:id/mute", groupHandler.UnmuteGroup)
+			groups.PUT("/:id/settings", groupHandler.UpdateGroupSetting)
+			groups.DELETE("/:id/messages", groupHandler.DeleteGroupConversation)
+			groups.GET("/:id/export", groupHandler.ExportGroupChat)
+		}
+
+		// WebSocket实时推送
+		authorized.GET("/ws", wsHandler.Connect)
 
 		// 用户相关路由
-		api.GET("/user/:userId", userHandler.GetUser)
-		api.POST("/users/batch", userHandler.GetUsersBatch)
+		authorized.GET("/user/:userId", userHandler.GetUser)
+		authorized.GET("/user/uid/:uid", userHandler.FindUserByUID)
+		authorized.POST("/users/batch", userHandler.GetUsersBatch)
+		authorized.PUT("/user/profile", userHandler.UpdateProfile)
+		authorized.GET("/user/privacy", userHandler.GetPrivacy)
+		authorized.PUT("/user/privacy", userHandler.UpdatePrivacy)
+		api.GET("/users/:userId/profile", middleware.OptionalAuth(), userHandler.GetProfile)
+		api.GET("/users/:userId/friends", middleware.OptionalAuth(), friendHandler.GetUserFriends)
+
+		// 关注相关路由
+		authorized.GET("/follows/:userId", followHandler.GetFollowStatus)
+		authorized.POST("/follows/:userId", followHandler.Follow)
+		authorized.DELETE("/follows/:userId", followHandler.Unfollow)
+		api.GET("/users/:userId/followers", middleware.OptionalAuth(), followHandler.GetFollowers)
+		api.GET("/users/:userId/following", middleware.OptionalAuth(), followHandler.GetFollowing)
+		authorized.GET("/feed/following", feedHandler.GetFollowingFeed)
+
+		// 在线状态
+		authorized.POST("/presence/batch", presenceHandler.GetPresenceBatch)
+
+		// 推送通知相关路由
+		authorized.POST("/notifications/devices", notificationHandler.RegisterDevice)
+		authorized.DELETE("/notifications/devices", notificationHandler.UnregisterDevice)
+		authorized.GET("/notifications/preferences", notificationHandler.GetPreference)
+		authorized.PUT("/notifications/preferences", notificationHandler.UpdatePreference)
+
+		// 账号注销与个人数据导出
+		authorized.POST("/account/deletion", accountHandler.RequestDeletion)
+		authorized.GET("/account/deletion", accountHandler.GetDeletion)
+		authorized.DELETE("/account/deletion", accountHandler.CancelDeletion)
+		authorized.POST("/account/exports", accountHandler.CreateExport)
+		authorized.GET("/account/exports", accountHandler.GetExports)
+		authorized.GET("/account/exports/:id/download", accountHandler.DownloadExport)
+
+		// 管理相关路由
+		admin := authorized.Group("/admin", middleware.AdminOnly())
+		{
+			admin.POST("/announcements", adminHandler.CreateAnnouncement)
+			admin.GET("/announcements", adminHandler.GetAnnouncements)
+			admin.GET("/announcements/:id", adminHandler.GetAnnouncement)
+		}
 		
 		// 博客相关路由
-		api.GET("/blogs", blogHandler.GetBlogs)
+		api.GET("/blogs", middleware.OptionalAuth(), blogHandler.GetBlogs)
 		// 搜索博客 - 注意：这个路由必须放在/:id前面，否则会被误认为是id参数
-		api.GET("/blogs/search", blogHandler.SearchBlogs)
+		api.GET("/blogs/search", middleware.OptionalAuth(), blogHandler.SearchBlogs)
 		// 博客详情
-		api.GET("/blogs/:id", blogHandler.GetBlogDetail)
+		api.GET("/blogs/:id", middleware.OptionalAuth(), blogHandler.GetBlogDetail)
 		
 		// 商城相关路由
 		mall := api.Group("/mall")
@@ -54,7 +193,7 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB) {
 		}
 		
 		// 轮播内容相关路由
-		slide := api.Group("/slide")
+		slide := api.Group("/slide", middleware.OptionalAuth())
 		{
 			// 获取轮播内容列表
 			slide.GET("/items", slideHandler.GetSlideItems)
@@ -72,7 +211,7 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB) {
 		}
 		
 		// 发布相关路由
-		publish := api.Group("/publish")
+		publish := authorized.Group("/publish")
 		{
 			// 保存草稿
 			publish.POST("/drafts", publishHandler.SaveDraft)
@@ -83,7 +222,7 @@ func RegisterRoutes(r *gin.Engine, db *gorm.DB) {
 		}
 		
 		// 文件上传相关路由
-		api.POST("/upload/media", uploadHandler.UploadMedia)
+		authorized.POST("/upload/media", uploadHandler.UploadMedia)
 	}
 	
 	// 配置静态文件服务
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"strconv"
+	"ticktok-service/internal/middleware"
 	"ticktok-service/internal/service"
 	"ticktok-service/pkg/util"
 
@@ -34,7 +35,7 @@ func (h *SlideHandler) GetSlideItems(c *gin.Context) {
 	}
 
 	// 获取轮播内容列表
-	result, err := h.slideService.GetSlideItems(startIndex, pageSize)
+	result, err := h.slideService.GetSlideItems(middleware.CurrentUserID(c), startIndex, pageSize)
 	if err != nil {
 		util.Fail(c, 500, "获取轮播内容列表失败: "+err.Error())
 		return
@@ -53,7 +54,7 @@ func (h *SlideHandler) GetSlideItemDetail(c *gin.Context) {
 	}
 
 	// 获取轮播内容详情
-	item, err := h.slideService.GetSlideItemByItemID(itemID)
+	item, err := h.slideService.GetSlideItemByItemID(middleware.CurrentUserID(c), itemID)
 	if err != nil {
 		util.Fail(c, 404, "轮播内容不存在: "+err.Error())
 		return
@@ -82,7 +83,7 @@ func (h *SlideHandler) GetSlideItemsByType(c *gin.Context) {
 	}
 
 	// 获取指定类型的轮播内容
-	result, err := h.slideService.GetSlideItemsByType(contentType, startIndex, pageSize)
+	result, err := h.slideService.GetSlideItemsByType(middleware.CurrentUserID(c), contentType, startIndex, pageSize)
 	if err != nil {
 		util.Fail(c, 500, "获取轮播内容失败: "+err.Error())
 		return
@@ -111,7 +112,7 @@ func (h *SlideHandler) SearchSlideItems(c *gin.Context) {
 	}
 
 	// 搜索轮播内容
-	result, err := h.slideService.SearchSlideItems(keyword, startIndex, pageSize)
+	result, err := h.slideService.SearchSlideItems(middleware.CurrentUserID(c), keyword, startIndex, pageSize)
 	if err != nil {
 		util.Fail(c, 500, "搜索轮播内容失败: "+err.Error())
 		return
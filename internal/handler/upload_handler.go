@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"ticktok-service/internal/middleware"
 	"ticktok-service/internal/model"
 	"time"
 
@@ -89,6 +90,7 @@ func (h *UploadHandler) UploadMedia(c *gin.Context) {
 	// 7. 保存文件信息到数据库
 	mediaFile := model.MediaFile{
 		ID:        fileID,
+		UserID:    middleware.CurrentUserID(c),
 		Type:      fileType,
 		URL:       "/uploads/" + filepath.ToSlash(relativePath), // 使用正斜杠
 		FilePath:  fullPath,
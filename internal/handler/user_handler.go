@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
+	"ticktok-service/internal/middleware"
+	"ticktok-service/internal/model"
 	"ticktok-service/internal/service"
 	"ticktok-service/pkg/util"
 
@@ -11,13 +14,15 @@ import (
 
 // UserHandler 用户相关处理器
 type UserHandler struct {
-	userService service.UserService
+	userService    service.UserService
+	privacyService service.PrivacyService
 }
 
 // NewUserHandler 创建新的用户处理器
 func NewUserHandler(db *gorm.DB) *UserHandler {
 	return &UserHandler{
-		userService: service.NewUserService(db),
+		userService:    service.NewUserService(db),
+		privacyService: service.NewPrivacyService(db),
 	}
 }
 
@@ -32,7 +37,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 	}
 
 	// 获取用户信息
-	user, err := h.userService.GetUserByID(uint(userID))
+	user, err := h.userService.GetUserByID(middleware.CurrentUserID(c), uint(userID))
 	if err != nil {
 		util.Fail(c, 404, "用户不存在: "+err.Error())
 		return
@@ -41,6 +46,23 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 	util.Success(c, user)
 }
 
+// FindUserByUID 通过UID查找用户
+func (h *UserHandler) FindUserByUID(c *gin.Context) {
+	uid, err := strconv.ParseUint(c.Param("uid"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的UID")
+		return
+	}
+
+	user, err := h.userService.FindUserByUID(middleware.CurrentUserID(c), uint(uid))
+	if err != nil {
+		failUser(c, "查找用户失败", err)
+		return
+	}
+
+	util.Success(c, user)
+}
+
 // GetUsersBatch 批量获取用户信息
 func (h *UserHandler) GetUsersBatch(c *gin.Context) {
 	// 解析请求参数
@@ -53,11 +75,88 @@ func (h *UserHandler) GetUsersBatch(c *gin.Context) {
 	}
 
 	// 获取用户信息
-	users, err := h.userService.GetUsersByIDs(req.UserIDs)
+	users, err := h.userService.GetUsersByIDs(middleware.CurrentUserID(c), req.UserIDs)
 	if err != nil {
 		util.Fail(c, 500, "获取用户信息失败: "+err.Error())
 		return
 	}
 
 	util.Success(c, users)
-} 
\ No newline at end of file
+}
+
+// UpdateProfile 更新当前用户的个人资料
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	var req model.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(middleware.CurrentUserID(c), &req)
+	if err != nil {
+		failUser(c, "更新个人资料失败", err)
+		return
+	}
+
+	util.Success(c, user)
+}
+
+// GetProfile 获取用户的公开主页，游客也可以访问
+func (h *UserHandler) GetProfile(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		util.Fail(c, 400, "无效的用户ID")
+		return
+	}
+
+	profile, err := h.userService.GetProfile(middleware.CurrentUserID(c), uint(userID))
+	if err != nil {
+		failUser(c, "获取用户主页失败", err)
+		return
+	}
+
+	util.Success(c, profile)
+}
+
+// GetPrivacy 获取当前用户的隐私设置
+func (h *UserHandler) GetPrivacy(c *gin.Context) {
+	setting, err := h.privacyService.GetSetting(middleware.CurrentUserID(c))
+	if err != nil {
+		util.Fail(c, 500, "获取隐私设置失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, setting)
+}
+
+// UpdatePrivacy 更新当前用户的隐私设置
+func (h *UserHandler) UpdatePrivacy(c *gin.Context) {
+	var req model.UpdatePrivacySettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.Fail(c, 400, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	setting, err := h.privacyService.UpdateSetting(middleware.CurrentUserID(c), &req)
+	if err != nil {
+		util.Fail(c, 500, "更新隐私设置失败: "+err.Error())
+		return
+	}
+
+	util.Success(c, setting)
+}
+
+// failUser 根据用户错误类型返回对应的错误码
+func failUser(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		util.Fail(c, 404, err.Error())
+	case errors.Is(err, service.ErrUserBlocked):
+		util.Fail(c, 403, err.Error())
+	case errors.Is(err, service.ErrInvalidNickname), errors.Is(err, service.ErrInvalidAvatar),
+		errors.Is(err, service.ErrInvalidBirthday):
+		util.Fail(c, 400, err.Error())
+	default:
+		util.Fail(c, 500, msg+": "+err.Error())
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"ticktok-service/internal/middleware"
+	"ticktok-service/internal/pkg/ws"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// WSHandler WebSocket相关处理器
+type WSHandler struct {
+	hub      *ws.Hub
+	upgrader websocket.Upgrader
+}
+
+// NewWSHandler 创建新的WebSocket处理器
+func NewWSHandler(hub *ws.Hub) *WSHandler {
+	return &WSHandler{
+		hub: hub,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				// 非浏览器客户端不携带Origin
+				return origin == "" || slices.Contains(middleware.AllowOrigins, origin)
+			},
+		},
+	}
+}
+
+// Connect 建立WebSocket连接，用于实时推送消息
+func (h *WSHandler) Connect(c *gin.Context) {
+	userID := middleware.CurrentUserID(c)
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade失败时已向客户端写入错误响应
+		log.Printf("WebSocket升级失败: %v", err)
+		return
+	}
+
+	h.hub.Serve(conn, userID)
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"slices"
+	"ticktok-service/config"
+	"ticktok-service/pkg/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminOnly 返回管理员权限中间件，只允许配置中的管理员访问，必须在Auth中间件之后使用
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !slices.Contains(config.AppConfig.Admin.UserIDs, CurrentUserID(c)) {
+			util.Fail(c, 403, "需要管理员权限")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/token"
+	"ticktok-service/pkg/util"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextUserIDKey 当前用户ID在gin.Context中的键
+const ContextUserIDKey = "userID"
+
+// accountCheckTTL 未注销的查询结果的缓存时间，注销完成后最多经过这么久令牌失效
+const accountCheckTTL = time.Minute
+
+// accountStates 用户ID到注销状态的缓存，已注销是终态，一直保留
+var accountStates sync.Map
+
+// accountState 缓存的注销状态
+type accountState struct {
+	deleted   bool
+	checkedAt time.Time
+}
+
+// Auth 返回JWT认证中间件，校验访问令牌并将当前用户ID写入上下文，已注销的账号视为未登录
+func Auth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := parseAccessToken(c)
+		if err != nil {
+			util.Fail(c, 401, "未登录或登录已过期")
+			c.Abort()
+			return
+		}
+		deleted, err := accountDeleted(claims.UserID)
+		if err != nil {
+			log.Printf("校验用户%d的注销状态失败: %v", claims.UserID, err)
+			util.Fail(c, 500, "校验账号状态失败")
+			c.Abort()
+			return
+		}
+		if deleted {
+			util.Fail(c, 401, "账号已注销")
+			c.Abort()
+			return
+		}
+
+		c.Set(ContextUserIDKey, claims.UserID)
+		c.Next()
+	}
+}
+
+// OptionalAuth 返回可选认证中间件，携带有效访问令牌时写入当前用户ID，否则以游客身份继续，已注销的账号视为游客
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, err := parseAccessToken(c); err == nil {
+			if deleted, err := accountDeleted(claims.UserID); err == nil && !deleted {
+				c.Set(ContextUserIDKey, claims.UserID)
+			}
+		}
+		c.Next()
+	}
+}
+
+// CurrentUserID 获取当前登录用户ID，必须在Auth或OptionalAuth中间件之后使用，游客返回0
+func CurrentUserID(c *gin.Context) uint {
+	return c.GetUint(ContextUserIDKey)
+}
+
+// parseAccessToken 从Authorization头中解析访问令牌
+// 浏览器的WebSocket握手无法设置请求头，此时改从token查询参数读取
+func parseAccessToken(c *gin.Context) (*token.Claims, error) {
+	header := c.GetHeader("Authorization")
+	tokenString, found := strings.CutPrefix(header, "Bearer ")
+	if !found && c.IsWebsocket() {
+		tokenString, found = c.Query("token"), true
+	}
+	if !found || tokenString == "" {
+		return nil, token.ErrInvalidToken
+	}
+	return token.Parse(tokenString, token.TypeAccess)
+}
+
+// accountDeleted 判断账号是否已完成注销，注销后仍未过期的访问令牌不再有效
+// 结果按accountCheckTTL缓存，避免每个请求都查询数据库
+func accountDeleted(userID uint) (bool, error) {
+	if cached, ok := accountStates.Load(userID); ok {
+		state := cached.(accountState)
+		if state.deleted || time.Since(state.checkedAt) < accountCheckTTL {
+			return state.deleted, nil
+		}
+	}
+
+	var count int64
+	if err := model.DB.Model(&model.AccountDeletion{}).
+		Where("user_id = ? AND completed_at IS NOT NULL", userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	accountStates.Store(userID, accountState{deleted: count > 0, checkedAt: time.Now()})
+	return count > 0, nil
+}
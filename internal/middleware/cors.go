@@ -7,15 +7,18 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// AllowOrigins 允许跨域访问的来源，WebSocket握手同样使用该列表校验Origin
+var AllowOrigins = []string{
+	"http://localhost:3000",  // React开发服务器
+	"http://127.0.0.1:3000",
+	"http://localhost:3001",  // 备用端口
+	"http://127.0.0.1:3001",
+}
+
 // CORS 返回配置好的CORS中间件
 func CORS() gin.HandlerFunc {
 	return cors.New(cors.Config{
-		AllowOrigins: []string{
-			"http://localhost:3000",  // React开发服务器
-			"http://127.0.0.1:3000",
-			"http://localhost:3001",  // 备用端口
-			"http://127.0.0.1:3001",
-		},
+		AllowOrigins: AllowOrigins,
 		AllowMethods: []string{
 			"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS",
 		},
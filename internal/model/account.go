@@ -0,0 +1,108 @@
+package model
+
+import (
+	"time"
+)
+
+// DeletedUserNickname 注销后匿名化的用户昵称
+const DeletedUserNickname = "已注销用户"
+
+// 个人数据导出状态
+const (
+	DataExportPending = "pending"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+	DataExportExpired = "expired"
+)
+
+// AccountDeletion 用户发起的注销申请，冷静期内可撤销，到期后由后台任务匿名化账号
+type AccountDeletion struct {
+	UserID      uint       `gorm:"primaryKey;autoIncrement:false"`
+	RequestedAt time.Time  `gorm:"column:requested_at;not null"`
+	ScheduledAt time.Time  `gorm:"column:scheduled_at;not null;index"` // 冷静期结束、开始执行注销的时间
+	CompletedAt *time.Time `gorm:"column:completed_at"`                // 注销完成的时间，为空表示仍在冷静期内
+}
+
+// DataExport 个人数据导出任务，生成的压缩包在过期后删除
+type DataExport struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `gorm:"column:user_id;not null;index"`
+	Status     string     `gorm:"type:enum('pending','ready','failed','expired');not null"`
+	FilePath   string     `gorm:"column:file_path;size:500"`
+	FileSize   int64      `gorm:"column:file_size;not null"`
+	Error      string     `gorm:"size:255"`
+	CreatedAt  time.Time  `gorm:"not null"`
+	FinishedAt *time.Time `gorm:"column:finished_at"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at;index"`
+}
+
+// DeleteAccountRequest 申请注销账号请求，需要再次输入密码确认
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// AccountDeletionResponse 注销申请状态
+type AccountDeletionResponse struct {
+	Pending     bool  `json:"pending"`     // 是否处于注销冷静期
+	RequestedAt int64 `json:"requestedAt"` // 申请时间（毫秒）
+	ScheduledAt int64 `json:"scheduledAt"` // 执行注销的时间（毫秒），冷静期结束前可撤销
+}
+
+// DataExportResponse 个人数据导出任务响应
+type DataExportResponse struct {
+	ID         uint   `json:"id"`
+	Status     string `json:"status"`
+	FileSize   int64  `json:"fileSize"`
+	Error      string `json:"error,omitempty"`
+	CreatedAt  int64  `json:"createdAt"`
+	FinishedAt int64  `json:"finishedAt"`
+	ExpiresAt  int64  `json:"expiresAt"` // 压缩包的过期时间（毫秒），过期后不能再下载
+}
+
+// AccountArchive 个人数据导出中直接从各表读取的数据，隐私设置、推送偏好、聊天记录和媒体文件另行读取
+type AccountArchive struct {
+	User                 *User
+	Username             string
+	Devices              []*DeviceToken
+	Friends              []*Friendship
+	FriendRequests       []*FriendRequest
+	Following            []*UserFollow
+	Followers            []*UserFollow
+	Blocks               []*UserBlock
+	Mutes                []*ConversationMute
+	ConversationSettings []*ConversationSetting
+	GroupMemberships     []*GroupMember
+	Reactions            []*MessageReaction
+	Drafts               []*Draft
+	Contents             []*Content
+	Blogs                []*Blog
+	MediaFiles           []*MediaFile
+	ChatPeerIDs          []uint // 有过单聊的用户ID
+	Groups               []*ChatGroup
+}
+
+// ArchivePost 导出的草稿或发布内容，JSON存储的字段已解析
+type ArchivePost struct {
+	ID          string      `json:"id"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	MediaItems  []MediaItem `json:"mediaItems"`
+	Topics      []string    `json:"topics"`
+	Mentions    []string    `json:"mentions"`
+	Tags        []string    `json:"tags"`
+	Visibility  string      `json:"visibility"`
+	IsDaily     bool        `json:"isDaily"`
+	CreatedAt   int64       `json:"createdAt"`
+	UpdatedAt   int64       `json:"updatedAt"`
+}
+
+// ArchiveMedia 导出的上传文件，文件本身位于压缩包的Path，磁盘上已不存在时Path为空
+type ArchiveMedia struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	URL       string `json:"url"`
+	FileName  string `json:"fileName"`
+	FileSize  int64  `json:"fileSize"`
+	Path      string `json:"path"`
+	CreatedAt int64  `json:"createdAt"`
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 公告群发状态
+const (
+	AnnouncementStatusSending = "sending"
+	AnnouncementStatusSent    = "sent"
+	AnnouncementStatusFailed  = "failed"
+)
+
+// Announcement 系统公告，记录一次以系统账号名义进行的群发
+type Announcement struct {
+	ID             uint       `gorm:"primaryKey"`
+	SenderID       uint       `gorm:"column:sender_id;not null;index"` // 发送公告的系统账号
+	CreatedBy      uint       `gorm:"column:created_by;not null"`      // 发起群发的管理员
+	Type           string     `gorm:"size:20;not null"`
+	Content        string     `gorm:"type:text"`
+	Payload        string     `gorm:"type:text"`
+	Caption        string     `gorm:"size:255"`
+	Duration       string     `gorm:"size:10"`
+	Segment        string     `gorm:"type:text"` // 接收者范围，JSON存储
+	Status         string     `gorm:"type:enum('sending','sent','failed');not null"`
+	RecipientCount int        `gorm:"column:recipient_count;not null"` // 已送达的接收者数量
+	Error          string     `gorm:"size:255"`
+	CreatedAt      time.Time  `gorm:"not null"`
+	FinishedAt     *time.Time `gorm:"column:finished_at"`
+}
+
+// AnnouncementSegment 公告接收者范围，各条件同时生效，全部为空时发给所有普通用户
+type AnnouncementSegment struct {
+	UserIDs          []uint `json:"userIds,omitempty"`          // 指定用户
+	RegisteredAfter  int64  `json:"registeredAfter,omitempty"`  // 注册时间不早于（毫秒）
+	RegisteredBefore int64  `json:"registeredBefore,omitempty"` // 注册时间早于（毫秒）
+	ActiveSince      int64  `json:"activeSince,omitempty"`      // 最后活跃时间不早于（毫秒）
+}
+
+// AnnouncementRequest 群发公告请求，消息内容的校验规则与单聊消息一致
+type AnnouncementRequest struct {
+	SenderID uint                `json:"senderId" binding:"required"` // 系统账号ID
+	Type     string              `json:"type" binding:"required"`
+	Content  string              `json:"content"`
+	Duration string              `json:"duration,omitempty"`
+	Caption  string              `json:"caption,omitempty"`
+	Payload  json.RawMessage     `json:"payload,omitempty"`
+	Segment  AnnouncementSegment `json:"segment"`
+}
+
+// AnnouncementResponse 公告响应
+type AnnouncementResponse struct {
+	ID             uint                 `json:"id"`
+	SenderID       uint                 `json:"senderId"`
+	CreatedBy      uint                 `json:"createdBy"`
+	Type           string               `json:"type"`
+	Content        string               `json:"content"`
+	Caption        string               `json:"caption,omitempty"`
+	Duration       string               `json:"duration,omitempty"`
+	Payload        json.RawMessage      `json:"payload,omitempty"`
+	Segment        *AnnouncementSegment `json:"segment"`
+	Status         string               `json:"status"`
+	RecipientCount int                  `json:"recipientCount"`
+	Error          string               `json:"error,omitempty"`
+	CreatedAt      int64                `json:"createdAt"`
+	FinishedAt     int64                `json:"finishedAt"` // 群发完成时间（毫秒），发送中为0
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+)
+
+// UserCredential 用户登录凭证模型
+type UserCredential struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	UserID       uint      `json:"userId" gorm:"column:user_id;not null;uniqueIndex"`
+	Username     string    `json:"username" gorm:"size:50;not null;uniqueIndex"`
+	PasswordHash string    `json:"-" gorm:"column:password_hash;size:100;not null"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"not null"`
+	UpdatedAt    time.Time `json:"updatedAt" gorm:"not null"`
+	User         User      `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// RefreshToken 刷新令牌模型，用于令牌轮换和注销
+type RefreshToken struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"userId" gorm:"column:user_id;not null;index"`
+	TokenID   string    `json:"tokenId" gorm:"column:token_id;size:50;not null;uniqueIndex"`
+	ExpiresAt time.Time `json:"expiresAt" gorm:"column:expires_at;not null"`
+	Revoked   bool      `json:"revoked" gorm:"default:false"`
+	CreatedAt time.Time `json:"createdAt" gorm:"not null"`
+}
+
+// RegisterRequest 注册请求
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Password string `json:"password" binding:"required,min=6,max=72"`
+	Nickname string `json:"nickname" binding:"required,max=100"`
+	Avatar   string `json:"avatar,omitempty"`
+}
+
+// LoginRequest 登录请求
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// TokenResponse 令牌响应
+type TokenResponse struct {
+	AccessToken  string       `json:"accessToken"`
+	RefreshToken string       `json:"refreshToken"`
+	ExpiresIn    int64        `json:"expiresIn"`
+	User         UserResponse `json:"user"`
+}
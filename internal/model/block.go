@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+// UserBlock 用户屏蔽关系，UserID屏蔽了BlockedID
+type UserBlock struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"userId" gorm:"column:user_id;not null;uniqueIndex:idx_user_block"`
+	BlockedID uint      `json:"blockedId" gorm:"column:blocked_id;not null;uniqueIndex:idx_user_block;index"`
+	CreatedAt time.Time `json:"createdAt" gorm:"not null"`
+	Blocked   User      `json:"-" gorm:"foreignKey:BlockedID"`
+}
+
+// ConversationMute 会话免打扰设置
+// 单聊时PeerID为对方用户ID、GroupID为0；群聊时PeerID为0
+type ConversationMute struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"userId" gorm:"column:user_id;not null;uniqueIndex:idx_conversation_mute"`
+	PeerID    uint      `json:"peerId" gorm:"column:peer_id;not null;default:0;uniqueIndex:idx_conversation_mute"`
+	GroupID   uint      `json:"groupId" gorm:"column:group_id;not null;default:0;uniqueIndex:idx_conversation_mute"`
+	CreatedAt time.Time `json:"createdAt" gorm:"not null"`
+}
+
+// BlockedUserResponse 屏蔽用户响应
+type BlockedUserResponse struct {
+	ID        uint   `json:"id"`
+	Nickname  string `json:"nickname"`
+	Avatar    string `json:"avatar"`
+	BlockedAt int64  `json:"blockedAt"`
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strconv"
 	"time"
 )
 
@@ -10,6 +11,7 @@ type Blog struct {
 	AuthorID     string    `gorm:"size:50;not null" json:"authorId"`
 	AuthorName   string    `gorm:"size:100;not null" json:"authorName"`
 	AuthorAvatar string    `gorm:"size:255;not null" json:"authorAvatar"`
+	UserID       uint      `gorm:"column:user_id;default:0;index" json:"userId"` // 发布者用户ID，历史数据由 backfill-post-authors 按数字authorId补齐，无法确定发布者时为0
 	Title        string    `gorm:"size:200;not null" json:"title"`
 	CoverImg     string    `gorm:"size:255;not null" json:"coverImg"`
 	Content      string    `gorm:"type:text;not null" json:"content"`
@@ -26,6 +28,18 @@ type Blog struct {
 	Comments []Comment   `gorm:"foreignKey:BlogID" json:"comments"`
 }
 
+// PublisherID 返回博客发布者的用户ID，user_id未补齐时使用数字authorId，无法确定时返回0
+func (b *Blog) PublisherID() uint {
+	if b.UserID != 0 {
+		return b.UserID
+	}
+	id, err := strconv.ParseUint(b.AuthorID, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(id)
+}
+
 // BlogImage 博客图片模型
 type BlogImage struct {
 	ID       uint   `gorm:"primaryKey" json:"-"`
@@ -0,0 +1,22 @@
+package model
+
+// 机器人对话中的角色
+const (
+	BotRoleUser      = "user"      // 用户发送的消息
+	BotRoleAssistant = "assistant" // 机器人的回复
+)
+
+// BotTurn 机器人对话上下文中的一条消息
+type BotTurn struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// BotRequest 交给机器人回复的对话
+type BotRequest struct {
+	BotID   uint
+	BotName string
+	UserID  uint
+	Content string    // 用户最新发送的消息，非文本消息为内容摘要
+	History []BotTurn // 之前的对话，按时间正序
+}
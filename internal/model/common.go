@@ -7,4 +7,11 @@ type PageResult struct {
 	Page     int         `json:"page"`
 	PageSize int         `json:"pageSize"`
 	HasMore  bool        `json:"hasMore"`
-} 
\ No newline at end of file
+}
+
+// CursorResult 游标分页结果
+type CursorResult struct {
+	List       interface{} `json:"list"`
+	NextCursor uint        `json:"nextCursor"`
+	HasMore    bool        `json:"hasMore"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+)
+
+// ConversationSetting 用户对会话的个人设置，只影响自己的会话列表和聊天记录，不影响其他参与者
+// 单聊时PeerID为对方用户ID、GroupID为0；群聊时PeerID为0
+type ConversationSetting struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	UserID            uint       `json:"userId" gorm:"column:user_id;not null;uniqueIndex:idx_conversation_setting"`
+	SessionID         string     `json:"sessionId" gorm:"column:session_id;size:50;not null;uniqueIndex:idx_conversation_setting"`
+	PeerID            uint       `json:"peerId" gorm:"column:peer_id;not null"`
+	GroupID           uint       `json:"groupId" gorm:"column:group_id;not null"`
+	PinnedAt          *time.Time `json:"pinnedAt" gorm:"column:pinned_at"` // 置顶时间，为空表示未置顶
+	Archived          bool       `json:"archived" gorm:"not null"`
+	ArchivedMessageID uint       `json:"archivedMessageId" gorm:"column:archived_message_id;not null"` // 归档时会话的最后一条消息ID，之后有新消息时自动取消归档
+	ClearedMessageID  uint       `json:"clearedMessageId" gorm:"column:cleared_message_id;not null"`   // 删除会话时的最后一条消息ID，该ID及之前的消息对用户不可见
+	Draft             string     `json:"draft" gorm:"type:text"`
+	DraftUpdatedAt    *time.Time `json:"draftUpdatedAt" gorm:"column:draft_updated_at"`
+	UpdatedAt         time.Time  `json:"updatedAt" gorm:"not null"`
+}
+
+// IsArchived 会话最后一条消息为lastMessageID时是否仍处于归档状态
+func (s *ConversationSetting) IsArchived(lastMessageID uint) bool {
+	return s.Archived && lastMessageID <= s.ArchivedMessageID
+}
+
+// IsCleared 会话最后一条消息为lastMessageID时是否已被删除，删除后有新消息时会话重新出现
+func (s *ConversationSetting) IsCleared(lastMessageID uint) bool {
+	return s.ClearedMessageID > 0 && lastMessageID <= s.ClearedMessageID
+}
+
+// UpdateConversationSettingRequest 更新会话设置请求，未传的字段保持不变，draft传空字符串表示清空草稿
+type UpdateConversationSettingRequest struct {
+	Pinned   *bool   `json:"pinned"`
+	Archived *bool   `json:"archived"`
+	Draft    *string `json:"draft" binding:"omitempty,max=5000"`
+}
+
+// ConversationSettingResponse 会话设置响应
+type ConversationSettingResponse struct {
+	SessionID        string `json:"sessionId"`
+	PeerID           uint   `json:"peerId,omitempty"`
+	GroupID          uint   `json:"groupId,omitempty"`
+	Pinned           bool   `json:"pinned"`
+	PinnedAt         int64  `json:"pinnedAt"` // 置顶时间（毫秒），未置顶为0
+	Archived         bool   `json:"archived"`
+	ClearedMessageID uint   `json:"clearedMessageId"` // 该ID及之前的消息已被删除
+	Draft            string `json:"draft"`
+	DraftUpdatedAt   int64  `json:"draftUpdatedAt"`
+}
@@ -25,6 +25,8 @@ func SetupDB() error {
 
 	gormConfig := &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
+		// 将唯一索引冲突转换为gorm.ErrDuplicatedKey，便于识别并发注册时的UID冲突
+		TranslateError: true,
 	}
 
 	var err error
@@ -48,12 +50,32 @@ func SetupDB() error {
 		&BlogImage{},
 		&BlogTag{},
 		&Comment{},
+		// 认证相关表
+		&UserCredential{},
+		&RefreshToken{},
 		// 聊天相关表
 		&User{},
 		&Friendship{},
+		&FriendRequest{},
+		&UserBlock{},
+		&UserFollow{},
+		&FeedItem{},
+		&ConversationMute{},
+		&ConversationSetting{},
+		&DeviceToken{},
+		&NotificationPreference{},
+		&PrivacySetting{},
+		&AccountDeletion{},
+		&DataExport{},
 		&Message{},
+		&MessageEdit{},
+		&MessageReaction{},
 		&Session{},
 		&UnreadMessage{},
+		&ReadCursor{},
+		&ChatGroup{},
+		&GroupMember{},
+		&Announcement{},
 		// 轮播内容相关表
 		&SlideItem{},
 		&SlideItemLabel{},
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"encoding/json"
+)
+
+// 聊天记录导出格式
+const (
+	ExportFormatJSON = "json"
+	ExportFormatText = "txt"
+	ExportFormatHTML = "html"
+)
+
+// ExportParticipant 导出记录中的会话参与者
+type ExportParticipant struct {
+	ID       uint   `json:"id"`
+	Nickname string `json:"nickname"`
+}
+
+// ExportHeader 导出记录的会话信息
+type ExportHeader struct {
+	SessionID    string               `json:"sessionId"`
+	Title        string               `json:"title"`
+	IsGroup      bool                 `json:"isGroup"`
+	ExportedBy   uint                 `json:"exportedBy"`
+	ExportedAt   int64                `json:"exportedAt"` // 导出时间（毫秒）
+	Participants []*ExportParticipant `json:"participants"`
+}
+
+// ExportMedia 媒体消息的文件信息，上传记录中找不到时只有URL
+type ExportMedia struct {
+	URL      string `json:"url"`
+	FileName string `json:"fileName,omitempty"`
+	FileSize int64  `json:"fileSize,omitempty"`
+}
+
+// ExportMessage 导出记录中的一条消息
+type ExportMessage struct {
+	ID         uint            `json:"id"`
+	SenderID   uint            `json:"senderId"`
+	SenderName string          `json:"senderName"`
+	Type       string          `json:"type"`
+	Content    string          `json:"content"`
+	Caption    string          `json:"caption,omitempty"`
+	Duration   string          `json:"duration,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Media      *ExportMedia    `json:"media,omitempty"`
+	ReplyToID  uint            `json:"replyToId,omitempty"`
+	Recalled   bool            `json:"recalled"`
+	Edited     bool            `json:"edited"`
+	Timestamp  int64           `json:"timestamp"`
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+)
+
+// FeedItem 关注时间线的收件箱条目，普通账号发布作品时写入每个粉丝的收件箱
+// 粉丝数达到阈值的大账号不写收件箱，读取时直接从作品表拉取
+type FeedItem struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"userId" gorm:"column:user_id;not null;uniqueIndex:idx_feed_item;index:idx_feed_inbox,priority:1"`
+	AuthorID    uint      `json:"authorId" gorm:"column:author_id;not null;index"`
+	PostType    string    `json:"postType" gorm:"column:post_type;type:enum('slide','content','blog');not null;uniqueIndex:idx_feed_item"`
+	PostID      string    `json:"postId" gorm:"column:post_id;size:50;not null;uniqueIndex:idx_feed_item"` // 轮播内容为item_id
+	PublishedAt time.Time `json:"publishedAt" gorm:"column:published_at;not null;index:idx_feed_inbox,priority:2"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"not null"`
+}
+
+// Key 作品在时间线中的唯一标识
+func (f *FeedItem) Key() string {
+	return f.PostType + ":" + f.PostID
+}
+
+// FeedQuery 时间线查询条件
+// At不为零值时返回发布时间等于At的全部作品，用于补齐分页边界上同一时刻发布的作品；
+// 否则返回发布时间早于Before的最新Limit条，Before为零值时从最新开始
+type FeedQuery struct {
+	Before time.Time
+	At     time.Time
+	Limit  int
+}
+
+// FeedItemResponse 时间线中的一条作品，按作品类型只返回slide、content、blog之一
+type FeedItemResponse struct {
+	PostType    string             `json:"postType"`
+	PostID      string             `json:"postId"`
+	Author      *UserResponse      `json:"author"`
+	PublishedAt int64              `json:"publishedAt"` // 发布时间（毫秒）
+	Slide       *SlideItemResponse `json:"slide,omitempty"`
+	Content     *ContentResponse   `json:"content,omitempty"`
+	Blog        *Blog              `json:"blog,omitempty"`
+}
+
+// FeedResponse 时间线分页响应
+type FeedResponse struct {
+	List       []*FeedItemResponse `json:"list"`
+	NextCursor int64               `json:"nextCursor"` // 下一页的before参数，即本页最后一条的发布时间（毫秒）
+	HasMore    bool                `json:"hasMore"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+)
+
+// UserFollow 用户关注关系，UserID关注了FollowingID，单向且与好友关系相互独立
+type UserFollow struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"userId" gorm:"column:user_id;not null;uniqueIndex:idx_user_follow"`
+	FollowingID uint      `json:"followingId" gorm:"column:following_id;not null;uniqueIndex:idx_user_follow;index"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"not null"`
+	Follower    User      `json:"-" gorm:"foreignKey:UserID"`
+	Following   User      `json:"-" gorm:"foreignKey:FollowingID"`
+}
+
+// FollowUserResponse 粉丝或关注列表中的用户，关注状态均相对于当前用户
+type FollowUserResponse struct {
+	ID           uint   `json:"id"`
+	Nickname     string `json:"nickname"`
+	Avatar       string `json:"avatar"`
+	Signature    string `json:"signature"`
+	IsFollowing  bool   `json:"isFollowing"`  // 当前用户是否关注了该用户
+	IsFollowedBy bool   `json:"isFollowedBy"` // 该用户是否关注了当前用户
+	IsMutual     bool   `json:"isMutual"`     // 是否互相关注
+	FollowedAt   int64  `json:"followedAt"`   // 关注时间（毫秒）
+}
+
+// FollowStatusResponse 当前用户与某个用户的关注关系
+type FollowStatusResponse struct {
+	UserID         uint  `json:"userId"`
+	IsFollowing    bool  `json:"isFollowing"`
+	IsFollowedBy   bool  `json:"isFollowedBy"`
+	IsMutual       bool  `json:"isMutual"`
+	FollowerCount  int64 `json:"followerCount"`
+	FollowingCount int64 `json:"followingCount"`
+}
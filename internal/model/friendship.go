@@ -4,6 +4,13 @@ import (
 	"time"
 )
 
+// 好友类型
+const (
+	FriendTypeNormal = "normal" // 普通用户
+	FriendTypeAIBot  = "aibot"  // AI机器人，收到消息后自动回复
+	FriendTypeSystem = "system" // 系统账号
+)
+
 // Friendship 好友关系模型
 type Friendship struct {
 	ID         uint      `json:"id" gorm:"primaryKey"`
@@ -13,4 +20,41 @@ type Friendship struct {
 	CreatedAt  time.Time `json:"createdAt" gorm:"not null"`
 	User       User      `json:"-" gorm:"foreignKey:UserID"`
 	Friend     User      `json:"-" gorm:"foreignKey:FriendID"`
-} 
\ No newline at end of file
+} 
+
+// 好友申请状态
+const (
+	FriendRequestPending   = "pending"
+	FriendRequestAccepted  = "accepted"
+	FriendRequestRejected  = "rejected"
+	FriendRequestCancelled = "cancelled"
+)
+
+// FriendRequest 好友申请模型
+type FriendRequest struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	FromUserID uint      `json:"fromUserId" gorm:"column:from_user_id;not null;index"`
+	ToUserID   uint      `json:"toUserId" gorm:"column:to_user_id;not null;index"`
+	Message    string    `json:"message" gorm:"size:255"`
+	Status     string    `json:"status" gorm:"type:enum('pending','accepted','rejected','cancelled');default:'pending'"`
+	CreatedAt  time.Time `json:"createdAt" gorm:"not null"`
+	UpdatedAt  time.Time `json:"updatedAt" gorm:"not null"`
+	FromUser   User      `json:"-" gorm:"foreignKey:FromUserID"`
+	ToUser     User      `json:"-" gorm:"foreignKey:ToUserID"`
+}
+
+// SendFriendRequestRequest 发送好友申请请求
+type SendFriendRequestRequest struct {
+	UserID  uint   `json:"userId" binding:"required"`
+	Message string `json:"message" binding:"max=255"`
+}
+
+// FriendRequestResponse 好友申请响应
+type FriendRequestResponse struct {
+	ID        uint          `json:"id"`
+	FromUser  *UserResponse `json:"fromUser"`
+	ToUser    *UserResponse `json:"toUser"`
+	Message   string        `json:"message"`
+	Status    string        `json:"status"`
+	CreatedAt int64         `json:"createdAt"`
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 群成员角色
+const (
+	GroupRoleOwner  = "owner"
+	GroupRoleAdmin  = "admin"
+	GroupRoleMember = "member"
+)
+
+// ChatGroup 群聊模型
+type ChatGroup struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	Name          string    `json:"name" gorm:"size:100;not null"`
+	Avatar        string    `json:"avatar" gorm:"size:255"`
+	OwnerID       uint      `json:"ownerId" gorm:"column:owner_id;not null"`
+	LastMessageID uint      `json:"lastMessageId" gorm:"column:last_message_id;default:0"`
+	CreatedAt     time.Time `json:"createdAt" gorm:"not null"`
+	UpdatedAt     time.Time `json:"updatedAt" gorm:"not null"`
+	Owner         User      `json:"-" gorm:"foreignKey:OwnerID"`
+}
+
+// GroupMember 群成员模型
+type GroupMember struct {
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	GroupID  uint      `json:"groupId" gorm:"column:group_id;not null;uniqueIndex:idx_group_member"`
+	UserID   uint      `json:"userId" gorm:"column:user_id;not null;uniqueIndex:idx_group_member;index"`
+	Role     string    `json:"role" gorm:"type:enum('owner','admin','member');default:'member'"`
+	JoinedAt time.Time `json:"joinedAt" gorm:"column:joined_at;not null"`
+	Group    ChatGroup `json:"-" gorm:"foreignKey:GroupID"`
+	User     User      `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// GroupSessionID 生成群聊的会话ID
+func GroupSessionID(groupID uint) string {
+	return fmt.Sprintf("group_%d", groupID)
+}
+
+// CreateGroupRequest 创建群聊请求
+type CreateGroupRequest struct {
+	Name      string `json:"name" binding:"required,max=100"`
+	Avatar    string `json:"avatar,omitempty"`
+	MemberIDs []uint `json:"memberIds" binding:"required,min=1"`
+}
+
+// InviteMembersRequest 邀请成员请求
+type InviteMembersRequest struct {
+	UserIDs []uint `json:"userIds" binding:"required,min=1"`
+}
+
+// UpdateMemberRoleRequest 设置成员角色请求
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin member"`
+}
+
+// GroupMessageRequest 发送群消息请求，各类型的校验规则与单聊一致
+type GroupMessageRequest struct {
+	Type      string          `json:"type" binding:"required"`
+	Content   string          `json:"content"`
+	Timestamp int64           `json:"timestamp" binding:"required"`
+	Duration  string          `json:"duration,omitempty"`
+	Caption   string          `json:"caption,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	ReplyToID uint            `json:"replyToId,omitempty"` // 引用同一群聊中的消息
+}
+
+// GroupResponse 群聊响应
+type GroupResponse struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Avatar      string `json:"avatar"`
+	OwnerID     uint   `json:"ownerId"`
+	MemberCount int    `json:"memberCount"`
+	MyRole      string `json:"myRole"`
+	SessionID   string `json:"sessionId"`
+}
+
+// GroupMemberResponse 群成员响应
+type GroupMemberResponse struct {
+	UserID   uint   `json:"userId"`
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+	Role     string `json:"role"`
+	JoinedAt int64  `json:"joinedAt"`
+}
@@ -1,73 +1,279 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 )
 
+// 消息类型
+const (
+	MessageTypeText        = "text"
+	MessageTypeVoice       = "voice"
+	MessageTypeImage       = "image"
+	MessageTypeVideo       = "video"
+	MessageTypeFile        = "file"
+	MessageTypeLocation    = "location"
+	MessageTypeSticker     = "sticker"
+	MessageTypeProductCard = "product_card" // 商品卡片，引用Product
+	MessageTypePostCard    = "post_card"    // 作品卡片，引用SlideItem或Blog
+)
+
 // Message 消息模型
 type Message struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	SessionID   string     `json:"sessionId" gorm:"column:session_id;size:50;not null"`
+	SenderID    uint       `json:"senderId" gorm:"column:sender_id;not null"`
+	ReceiverID  uint       `json:"receiverId" gorm:"column:receiver_id;not null"` // 群消息为0
+	GroupID     uint       `json:"groupId,omitempty" gorm:"column:group_id;default:0;index"`
+	Type        string     `json:"type" gorm:"type:enum('text','voice','image','video','file','location','sticker','product_card','post_card');not null"`
+	Content     string     `json:"content" gorm:"type:text;index:idx_messages_fulltext,class:FULLTEXT,option:WITH PARSER ngram"`
+	Payload     string     `json:"-" gorm:"type:text"` // 结构化消息内容，JSON存储
+	Duration    string     `json:"duration,omitempty" gorm:"size:10"`
+	Caption     string     `json:"caption,omitempty" gorm:"size:255;index:idx_messages_fulltext,class:FULLTEXT,option:WITH PARSER ngram"`
+	Timestamp   int64      `json:"timestamp" gorm:"not null"`
+	Status      string     `json:"status" gorm:"type:enum('sending','sent','delivered','read','failed');default:'sending'"`
+	Recalled    bool       `json:"recalled" gorm:"default:false"`
+	Edited      bool       `json:"edited" gorm:"default:false"`
+	DeliveredAt *time.Time `json:"deliveredAt" gorm:"column:delivered_at"`                        // 单聊接收方确认送达的时间
+	ReadAt      *time.Time `json:"readAt" gorm:"column:read_at"`                                  // 单聊接收方已读的时间
+	ReplyToID   uint       `json:"replyToId,omitempty" gorm:"column:reply_to_id;default:0;index"` // 引用的消息ID，0表示没有引用
+	CreatedAt   time.Time  `json:"createdAt" gorm:"not null"`
+	Sender      User       `json:"-" gorm:"foreignKey:SenderID"`
+	Receiver    User       `json:"-" gorm:"foreignKey:ReceiverID;-:migration"` // 群消息没有接收者，不建外键约束
+	ReplyTo     *Message   `json:"-" gorm:"foreignKey:ReplyToID;-:migration"`  // reply_to_id为0表示没有引用，不建外键约束
+}
+
+// MessageEdit 消息编辑历史，保存每次编辑前的内容
+type MessageEdit struct {
 	ID         uint      `json:"id" gorm:"primaryKey"`
-	SessionID  string    `json:"sessionId" gorm:"column:session_id;size:50;not null"`
-	SenderID   uint      `json:"senderId" gorm:"column:sender_id;not null"`
-	ReceiverID uint      `json:"receiverId" gorm:"column:receiver_id;not null"`
-	Type       string    `json:"type" gorm:"type:enum('text','voice','image');not null"`
-	Content    string    `json:"content" gorm:"type:text"`
-	Duration   string    `json:"duration,omitempty" gorm:"size:10"`
-	Caption    string    `json:"caption,omitempty" gorm:"size:255"`
-	Timestamp  int64     `json:"timestamp" gorm:"not null"`
-	Status     string    `json:"status" gorm:"type:enum('sending','sent','read','failed');default:'sending'"`
-	CreatedAt  time.Time `json:"createdAt" gorm:"not null"`
-	Sender     User      `json:"-" gorm:"foreignKey:SenderID"`
-	Receiver   User      `json:"-" gorm:"foreignKey:ReceiverID"`
+	MessageID  uint      `json:"messageId" gorm:"column:message_id;not null;index"`
+	OldContent string    `json:"oldContent" gorm:"column:old_content;type:text"`
+	EditedAt   time.Time `json:"editedAt" gorm:"column:edited_at;not null"`
+}
+
+// EditMessageRequest 编辑消息请求
+type EditMessageRequest struct {
+	Content string `json:"content" binding:"required"`
 }
 
 // MessageRequest 发送消息请求
 type MessageRequest struct {
-	SenderID   uint   `json:"senderId" binding:"required"`
-	ReceiverID uint   `json:"receiverId" binding:"required"`
-	IsSelf     bool   `json:"isSelf" binding:"required"`
-	Type       string `json:"type" binding:"required"`
-	Content    string `json:"content" binding:"required"`
-	Timestamp  int64  `json:"timestamp" binding:"required"`
-	Duration   string `json:"duration,omitempty"`
-	Caption    string `json:"caption,omitempty"`
+	SenderID   uint            `json:"senderId"` // 由服务端根据登录用户填充
+	ReceiverID uint            `json:"receiverId" binding:"required"`
+	IsSelf     bool            `json:"isSelf" binding:"required"`
+	Type       string          `json:"type" binding:"required"`
+	Content    string          `json:"content"` // 各类型的必填规则见SendMessage校验
+	Timestamp  int64           `json:"timestamp" binding:"required"`
+	Duration   string          `json:"duration,omitempty"`
+	Caption    string          `json:"caption,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	ReplyToID  uint            `json:"replyToId,omitempty"` // 引用同一会话中的消息
+}
+
+// ChatHistoryQuery 聊天历史游标查询参数
+// Before和After至多设置一个：Before向前加载更早的消息，After加载更新的消息，都为空时返回最新一页
+type ChatHistoryQuery struct {
+	Before uint
+	After  uint
+	Limit  int
+	Since  uint // 只返回ID大于Since的消息，由服务端按用户删除会话的位置填充
+}
+
+// MessageSearchQuery 消息搜索条件，除Keyword外均为可选
+type MessageSearchQuery struct {
+	Keyword  string
+	FriendID uint      // 只搜索与该好友的单聊
+	GroupID  uint      // 只搜索该群聊
+	Type     string    // 消息类型
+	Start    time.Time // 发送时间下限
+	End      time.Time // 发送时间上限
+	Before   uint      // 游标，只返回ID小于该值的消息
+	Limit    int
+	Context  int // 每条结果前后附带的消息数
+}
+
+// SearchSession 搜索结果所属的会话
+type SearchSession struct {
+	SessionID string `json:"sessionId"`
+	IsGroup   bool   `json:"isGroup"`
+	ID        uint   `json:"id"` // 单聊为对方用户ID，群聊为群ID
+	Name      string `json:"name"`
+	Avatar    string `json:"avatar"`
+}
+
+// MessageSearchHit 消息搜索结果
+type MessageSearchHit struct {
+	Message *ChatMessage   `json:"message"`
+	Snippet string         `json:"snippet"` // 命中关键词附近的文本片段
+	Session *SearchSession `json:"session"`
+	Before  []*ChatMessage `json:"before"` // 同一会话中紧邻的更早消息，按时间正序
+	After   []*ChatMessage `json:"after"`  // 同一会话中紧邻的更新消息，按时间正序
 }
 
 // MessageResponse 消息响应
 type MessageResponse struct {
-	ID         uint   `json:"id"`
-	SenderID   uint   `json:"senderId"`
-	ReceiverID uint   `json:"receiverId"`
-	IsSelf     bool   `json:"isSelf"`
-	Type       string `json:"type"`
-	Content    string `json:"content"`
-	Timestamp  int64  `json:"timestamp"`
-	Status     string `json:"status"`
-	SessionID  string `json:"sessionId"`
-	Duration   string `json:"duration,omitempty"`
-	Caption    string `json:"caption,omitempty"`
+	ID         uint            `json:"id"`
+	SenderID   uint            `json:"senderId"`
+	ReceiverID uint            `json:"receiverId"`
+	IsSelf     bool            `json:"isSelf"`
+	Type       string          `json:"type"`
+	Content    string          `json:"content"`
+	Timestamp  int64           `json:"timestamp"`
+	Status     string          `json:"status"`
+	SessionID  string          `json:"sessionId"`
+	Duration   string          `json:"duration,omitempty"`
+	Caption    string          `json:"caption,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	ReplyTo    *QuotedMessage  `json:"replyTo,omitempty"`
 }
 
 // ChatMessage 聊天消息响应
 type ChatMessage struct {
+	ID          uint               `json:"id"`
+	SenderID    uint               `json:"senderId"`
+	ReceiverID  uint               `json:"receiverId"`
+	IsSelf      bool               `json:"isSelf"`
+	Type        string             `json:"type"`
+	Content     string             `json:"content"`
+	Timestamp   int64              `json:"timestamp"`
+	Status      string             `json:"status"`
+	SessionID   string             `json:"sessionId"`
+	GroupID     uint               `json:"groupId,omitempty"`
+	Duration    string             `json:"duration,omitempty"`
+	Caption     string             `json:"caption,omitempty"`
+	Recalled    bool               `json:"recalled"`
+	Edited      bool               `json:"edited"`
+	Payload     json.RawMessage    `json:"payload,omitempty"`
+	DeliveredAt int64              `json:"deliveredAt,omitempty"` // 毫秒时间戳，仅单聊
+	ReadAt      int64              `json:"readAt,omitempty"`      // 毫秒时间戳，仅单聊
+	ReplyTo     *QuotedMessage     `json:"replyTo,omitempty"`
+	Reactions   []*ReactionSummary `json:"reactions,omitempty"` // 仅聊天历史返回
+}
+
+// QuotedMessage 被引用消息的摘要
+type QuotedMessage struct {
 	ID         uint   `json:"id"`
 	SenderID   uint   `json:"senderId"`
-	ReceiverID uint   `json:"receiverId"`
-	IsSelf     bool   `json:"isSelf"`
+	SenderName string `json:"senderName"`
 	Type       string `json:"type"`
-	Content    string `json:"content"`
-	Timestamp  int64  `json:"timestamp"`
-	Status     string `json:"status"`
-	SessionID  string `json:"sessionId"`
-	Duration   string `json:"duration,omitempty"`
-	Caption    string `json:"caption,omitempty"`
+	Content    string `json:"content"` // 内容摘要，已撤回时为空
+	Recalled   bool   `json:"recalled"`
+}
+
+// FilePayload 文件消息内容，Content为文件URL
+type FilePayload struct {
+	FileName string `json:"fileName"`
+	FileSize int64  `json:"fileSize"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// VideoPayload 视频消息内容，Content为视频URL
+type VideoPayload struct {
+	CoverURL string `json:"coverUrl,omitempty"`
+	Width    int    `json:"width,omitempty"`
+	Height   int    `json:"height,omitempty"`
+}
+
+// LocationPayload 位置消息内容
+type LocationPayload struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+	Address   string  `json:"address,omitempty"`
+}
+
+// StickerPayload 表情消息内容，Content为表情图片URL
+type StickerPayload struct {
+	StickerID string `json:"stickerId,omitempty"`
+	PackID    string `json:"packId,omitempty"`
+}
+
+// ProductCardPayload 商品卡片内容，发送时由服务端根据商品ID填充快照
+type ProductCardPayload struct {
+	ProductID     uint   `json:"productId"`
+	Title         string `json:"title"`
+	Image         string `json:"image"`
+	Price         string `json:"price"`
+	OriginalPrice string `json:"originalPrice"`
+	ShopName      string `json:"shopName"`
+}
+
+// 作品类型，作品卡片只支持slide和blog
+const (
+	PostTypeSlide   = "slide"
+	PostTypeBlog    = "blog"
+	PostTypeContent = "content"
+)
+
+// PostCardPayload 作品卡片内容，发送时由服务端根据作品ID填充快照
+type PostCardPayload struct {
+	PostType     string `json:"postType"`
+	PostID       string `json:"postId"`
+	Title        string `json:"title"`
+	Cover        string `json:"cover"`
+	ContentType  string `json:"contentType,omitempty"`
+	AuthorName   string `json:"authorName"`
+	AuthorAvatar string `json:"authorAvatar"`
 }
 
 // MessageListResponse 消息列表项
 type MessageListResponse struct {
-	ID     uint          `json:"id"`
-	Sender FriendResponse `json:"sender"`
-	Text   string        `json:"text"`
-	Time   string        `json:"time"`
-	Unread int           `json:"unread"`
-} 
\ No newline at end of file
+	ID       uint           `json:"id"`
+	Sender   FriendResponse `json:"sender"` // 群聊时为群信息
+	Text     string         `json:"text"`
+	Time     string         `json:"time"`
+	Unread   int            `json:"unread"`
+	IsGroup  bool           `json:"isGroup"`
+	GroupID  uint           `json:"groupId,omitempty"`
+	Muted    bool           `json:"muted"`
+	Pinned   bool           `json:"pinned"`
+	Archived bool           `json:"archived"`
+	Draft    string         `json:"draft,omitempty"` // 未发送的草稿
+	LastAt   int64          `json:"-"`               // 最后一条消息的时间戳，用于排序
+	PinnedAt int64          `json:"-"`               // 置顶时间，置顶会话按此排序
+}
+
+// MessageStatusEvent 消息状态变更推送
+type MessageStatusEvent struct {
+	MessageID uint   `json:"messageId"`
+	SessionID string `json:"sessionId"`
+	Status    string `json:"status"`
+}
+
+// ReadReceiptEvent 已读回执推送，LastReadMessageID及之前的消息均已读
+type ReadReceiptEvent struct {
+	ReaderID          uint   `json:"readerId"`
+	SessionID         string `json:"sessionId"`
+	LastReadMessageID uint   `json:"lastReadMessageId"`
+	ReadAt            int64  `json:"readAt"`
+}
+
+// 输入状态
+const (
+	TypingActionTyping    = "typing"    // 正在输入
+	TypingActionRecording = "recording" // 正在录音
+	TypingActionStopped   = "stopped"   // 停止输入
+)
+
+// TypingRequest 客户端通过WebSocket上报的输入状态，PeerID和GroupID二选一
+type TypingRequest struct {
+	PeerID  uint   `json:"peerId"`
+	GroupID uint   `json:"groupId"`
+	Action  string `json:"action"`
+}
+
+// TypingEvent 输入状态推送，只经WebSocket转发，不写入数据库
+type TypingEvent struct {
+	SessionID string `json:"sessionId"`
+	UserID    uint   `json:"userId"`
+	GroupID   uint   `json:"groupId,omitempty"`
+	Action    string `json:"action"`
+}
+
+// DeliveryReceiptEvent 送达回执推送，LastMessageID及之前的消息均已送达
+type DeliveryReceiptEvent struct {
+	UserID        uint   `json:"userId"`
+	SessionID     string `json:"sessionId"`
+	LastMessageID uint   `json:"lastMessageId"`
+	DeliveredAt   int64  `json:"deliveredAt"`
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+)
+
+// 通知类型
+const (
+	NotificationKindMessage       = "message"        // 单聊消息
+	NotificationKindGroupMessage  = "group_message"  // 群聊消息
+	NotificationKindFriendRequest = "friend_request" // 好友申请
+)
+
+// DeviceToken 设备推送令牌，同一令牌只属于最后登记它的用户
+type DeviceToken struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"userId" gorm:"column:user_id;not null;index"`
+	Provider  string    `json:"provider" gorm:"size:20;not null"` // 推送渠道：apns、fcm、webhook、log
+	Token     string    `json:"token" gorm:"size:255;not null;uniqueIndex"`
+	CreatedAt time.Time `json:"createdAt" gorm:"not null"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"not null"`
+}
+
+// NotificationPreference 用户的推送偏好，没有记录时全部开启
+type NotificationPreference struct {
+	UserID         uint      `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	Enabled        bool      `json:"enabled" gorm:"not null"` // 总开关
+	Messages       bool      `json:"messages" gorm:"not null"`
+	GroupMessages  bool      `json:"groupMessages" gorm:"column:group_messages;not null"`
+	FriendRequests bool      `json:"friendRequests" gorm:"column:friend_requests;not null"`
+	ShowPreview    bool      `json:"showPreview" gorm:"column:show_preview;not null"` // 关闭时通知中不显示消息内容
+	UpdatedAt      time.Time `json:"-" gorm:"not null"`
+}
+
+// RegisterDeviceRequest 登记设备推送令牌请求
+type RegisterDeviceRequest struct {
+	Provider string `json:"provider" binding:"required"`
+	Token    string `json:"token" binding:"required,max=255"`
+}
+
+// UnregisterDeviceRequest 注销设备推送令牌请求
+type UnregisterDeviceRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// UpdateNotificationPreferenceRequest 更新推送偏好请求，未传的字段保持不变
+type UpdateNotificationPreferenceRequest struct {
+	Enabled        *bool `json:"enabled"`
+	Messages       *bool `json:"messages"`
+	GroupMessages  *bool `json:"groupMessages"`
+	FriendRequests *bool `json:"friendRequests"`
+	ShowPreview    *bool `json:"showPreview"`
+}
+
+// Notification 待发送的离线通知，标题和正文由推送服务根据类型生成
+type Notification struct {
+	UserID    uint   // 接收者
+	Kind      string // 通知类型
+	SenderID  uint   // 消息发送者或好友申请发起者
+	GroupID   uint   // 群聊消息所在的群
+	SessionID string
+	MessageID uint
+	Preview   string // 消息内容摘要
+}
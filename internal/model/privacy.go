@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+)
+
+// 隐私设置的可见范围
+const (
+	PrivacyEveryone = "everyone" // 所有人
+	PrivacyFriends  = "friends"  // 仅好友
+	PrivacyNobody   = "nobody"   // 任何人都不可以
+)
+
+// PrivacySetting 用户的隐私设置，没有记录时使用默认设置，本人不受自己设置的限制
+type PrivacySetting struct {
+	UserID               uint      `json:"-" gorm:"primaryKey;autoIncrement:false"`
+	WhoCanMessage        string    `json:"whoCanMessage" gorm:"column:who_can_message;type:enum('everyone','friends','nobody');not null"`               // 谁可以给我发单聊消息
+	FriendListVisibility string    `json:"friendListVisibility" gorm:"column:friend_list_visibility;type:enum('everyone','friends','nobody');not null"` // 谁可以查看我的好友列表
+	ShowOnlineStatus     bool      `json:"showOnlineStatus" gorm:"column:show_online_status;not null"`                                                  // 关闭时其他人看到的始终是离线
+	SearchableByUID      bool      `json:"searchableByUid" gorm:"column:searchable_by_uid;not null"`                                                    // 关闭时不能通过UID找到我，非好友也看不到我的UID
+	UpdatedAt            time.Time `json:"-" gorm:"not null"`
+}
+
+// UpdatePrivacySettingRequest 更新隐私设置请求，未传的字段保持不变
+type UpdatePrivacySettingRequest struct {
+	WhoCanMessage        *string `json:"whoCanMessage" binding:"omitempty,oneof=everyone friends nobody"`
+	FriendListVisibility *string `json:"friendListVisibility" binding:"omitempty,oneof=everyone friends nobody"`
+	ShowOnlineStatus     *bool   `json:"showOnlineStatus"`
+	SearchableByUID      *bool   `json:"searchableByUid"`
+}
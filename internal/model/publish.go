@@ -4,9 +4,16 @@ import (
 	"time"
 )
 
+// 媒体文件类型
+const (
+	MediaTypePhoto = "photo"
+	MediaTypeVideo = "video"
+)
+
 // MediaFile 媒体文件模型
 type MediaFile struct {
 	ID        string    `json:"id" gorm:"primaryKey;size:50"`
+	UserID    uint      `json:"userId" gorm:"column:user_id;not null;default:0;index"` // 上传者ID，上线登录前的历史数据为0
 	Type      string    `json:"type" gorm:"column:type;type:enum('photo','video');not null"`
 	URL       string    `json:"url" gorm:"column:url;size:255;not null"`
 	FilePath  string    `json:"-" gorm:"column:file_path;size:500;not null"` // 实际文件路径
@@ -18,7 +25,7 @@ type MediaFile struct {
 // Draft 草稿模型
 type Draft struct {
 	ID          string    `json:"id" gorm:"primaryKey;size:50"`
-	UserID      uint      `json:"userId" gorm:"column:user_id;not null;default:1"`
+	UserID      uint      `json:"userId" gorm:"column:user_id;not null;default:0"` // 作者ID，上线登录前的历史数据为0
 	Title       string    `json:"title" gorm:"column:title;size:255;not null"`
 	Description string    `json:"description" gorm:"column:description;type:text"`
 	MediaItems  string    `json:"-" gorm:"column:media_items;type:json"`       // JSON存储媒体项
@@ -34,7 +41,7 @@ type Draft struct {
 // Content 发布内容模型
 type Content struct {
 	ID          string    `json:"id" gorm:"primaryKey;size:50"`
-	UserID      uint      `json:"userId" gorm:"column:user_id;not null;default:1"`
+	UserID      uint      `json:"userId" gorm:"column:user_id;not null;default:0;index"` // 作者ID，上线登录前的历史数据为0
 	Title       string    `json:"title" gorm:"column:title;size:255;not null"`
 	Description string    `json:"description" gorm:"column:description;type:text"`
 	MediaItems  string    `json:"-" gorm:"column:media_items;type:json"`       // JSON存储媒体项
@@ -51,6 +58,31 @@ type Content struct {
 	UpdatedAt   time.Time `json:"updatedAt" gorm:"column:updated_at;not null"`
 }
 
+// 发布内容可见范围
+const (
+	VisibilityPublic  = "public"
+	VisibilityFriends = "friends"
+	VisibilityPrivate = "private"
+)
+
+// ContentResponse 发布内容响应，JSON存储的字段已解析
+type ContentResponse struct {
+	ID           string      `json:"id"`
+	UserID       uint        `json:"userId"`
+	Title        string      `json:"title"`
+	Description  string      `json:"description"`
+	MediaItems   []MediaItem `json:"mediaItems"`
+	Topics       []string    `json:"topics"`
+	Tags         []string    `json:"tags"`
+	Visibility   string      `json:"visibility"`
+	IsDaily      bool        `json:"isDaily"`
+	ViewCount    int64       `json:"viewCount"`
+	LikeCount    int64       `json:"likeCount"`
+	CommentCount int64       `json:"commentCount"`
+	ShareCount   int64       `json:"shareCount"`
+	CreatedAt    int64       `json:"createdAt"` // 发布时间（毫秒）
+}
+
 // MediaItem 前端媒体项结构
 type MediaItem struct {
 	ID   string `json:"id,omitempty"`
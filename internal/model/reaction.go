@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+// MessageReaction 消息表情回应，同一用户对同一消息的每种表情只记一次
+type MessageReaction struct {
+	ID        uint `json:"id" gorm:"primaryKey"`
+	MessageID uint `json:"messageId" gorm:"column:message_id;not null;uniqueIndex:idx_message_reaction"`
+	UserID    uint `json:"userId" gorm:"column:user_id;not null;uniqueIndex:idx_message_reaction"`
+	// 使用二进制排序规则，否则部分排序规则会把不同的emoji视为相同
+	Emoji     string    `json:"emoji" gorm:"type:varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin;not null;uniqueIndex:idx_message_reaction"`
+	CreatedAt time.Time `json:"createdAt" gorm:"not null"`
+}
+
+// ReactionRequest 添加表情回应请求
+type ReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required,max=16"`
+}
+
+// ReactionSummary 按表情聚合的回应，按首次回应的时间排序
+type ReactionSummary struct {
+	Emoji       string `json:"emoji"`
+	Count       int    `json:"count"`
+	ReactedByMe bool   `json:"reactedByMe"`
+}
+
+// ReactionEvent 表情回应变更推送
+type ReactionEvent struct {
+	MessageID uint   `json:"messageId"`
+	SessionID string `json:"sessionId"`
+	UserID    uint   `json:"userId"`
+	Emoji     string `json:"emoji"`
+	Added     bool   `json:"added"` // false表示取消回应
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -16,13 +17,43 @@ type Session struct {
 	LastMessage   Message   `json:"-" gorm:"foreignKey:LastMessageID"`
 }
 
+// SessionIDFor 生成两个用户之间与方向无关的会话ID，较小的用户ID在前
+func SessionIDFor(userID, peerID uint) string {
+	if userID > peerID {
+		userID, peerID = peerID, userID
+	}
+	return fmt.Sprintf("chat_%d_%d", userID, peerID)
+}
+
 // UnreadMessage 未读消息模型
+// 单聊按(UserID, SenderID)计数，GroupID为0；群聊按(UserID, GroupID)计数，SenderID为最后一条消息的发送者
 type UnreadMessage struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	UserID    uint      `json:"userId" gorm:"column:user_id;not null"`
 	SenderID  uint      `json:"senderId" gorm:"column:sender_id;not null"`
+	GroupID   uint      `json:"groupId" gorm:"column:group_id;default:0;index"`
 	Count     int       `json:"count" gorm:"default:0"`
 	UpdatedAt time.Time `json:"updatedAt" gorm:"not null"`
 	User      User      `json:"-" gorm:"foreignKey:UserID"`
 	Sender    User      `json:"-" gorm:"foreignKey:SenderID"`
-} 
\ No newline at end of file
+} 
+// ReadCursor 会话阅读游标，记录参与者在会话中最后送达和最后已读的消息ID
+// 单聊和群聊通用，按(SessionID, UserID)唯一
+type ReadCursor struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	SessionID       string     `json:"sessionId" gorm:"column:session_id;size:50;not null;uniqueIndex:idx_read_cursor"`
+	UserID          uint       `json:"userId" gorm:"column:user_id;not null;uniqueIndex:idx_read_cursor"`
+	LastDeliveredID uint       `json:"lastDeliveredId" gorm:"column:last_delivered_id;default:0"`
+	LastReadID      uint       `json:"lastReadId" gorm:"column:last_read_id;default:0"`
+	DeliveredAt     *time.Time `json:"deliveredAt" gorm:"column:delivered_at"`
+	ReadAt          *time.Time `json:"readAt" gorm:"column:read_at"`
+}
+
+// ReadCursorResponse 阅读游标响应，时间为毫秒时间戳
+type ReadCursorResponse struct {
+	UserID          uint  `json:"userId"`
+	LastDeliveredID uint  `json:"lastDeliveredId"`
+	LastReadID      uint  `json:"lastReadId"`
+	DeliveredAt     int64 `json:"deliveredAt"`
+	ReadAt          int64 `json:"readAt"`
+}
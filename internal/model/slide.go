@@ -11,6 +11,7 @@ type SlideItem struct {
 	ContentType string    `json:"contentType" gorm:"column:content_type;type:enum('video','picture');not null"`
 	Title       string    `json:"title" gorm:"type:text;not null"`
 	Author      string    `json:"author" gorm:"size:100;not null"`
+	UserID      uint      `json:"userId" gorm:"column:user_id;default:0;index"` // 发布者用户ID，历史数据只记录了作者昵称，无法确定发布者，为0
 	Likes       int64     `json:"likes" gorm:"default:0"`
 	Comments    int64     `json:"comments" gorm:"default:0"`
 	Stars       int64     `json:"stars" gorm:"default:0"`
@@ -47,6 +48,8 @@ type SlideItemResponse struct {
 	ContentType string   `json:"contentType"`
 	Title       string   `json:"title"`
 	Author      string   `json:"author"`
+	UserID      uint     `json:"userId"`
+	IsFollowing bool     `json:"isFollowing"` // 当前用户是否关注了发布者
 	Likes       int64    `json:"likes"`
 	Comments    int64    `json:"comments"`
 	Stars       int64    `json:"stars"`
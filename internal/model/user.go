@@ -4,16 +4,44 @@ import (
 	"time"
 )
 
+// 账号类型，取值与好友类型一致
+const (
+	AccountTypeNormal = FriendTypeNormal
+	AccountTypeAIBot  = FriendTypeAIBot
+	AccountTypeSystem = FriendTypeSystem // 系统账号，可由管理员以其名义群发公告
+)
+
+// 用户性别
+const (
+	GenderUnknown = "unknown"
+	GenderMale    = "male"
+	GenderFemale  = "female"
+)
+
+// BirthdayLayout 生日的日期格式
+const BirthdayLayout = "2006-01-02"
+
 // User 用户模型
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	UID       uint      `json:"uid" gorm:"not null"`
-	Nickname  string    `json:"nickname" gorm:"size:100;not null"`
-	Avatar    string    `json:"avatar" gorm:"size:255;not null"`
-	Status    string    `json:"status" gorm:"type:enum('online','offline','away');default:'offline'"`
-	LastSeen  string    `json:"lastSeen" gorm:"column:last_seen;size:100"`
-	Signature string    `json:"signature" gorm:"size:255"`
-	CreatedAt time.Time `json:"createdAt" gorm:"not null"`
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	UID            uint       `json:"uid" gorm:"not null;uniqueIndex:idx_user_uid"`
+	Nickname       string     `json:"nickname" gorm:"size:100;not null"`
+	Avatar         string     `json:"avatar" gorm:"size:255;not null"`
+	Status         string     `json:"status" gorm:"type:enum('online','offline','away');default:'offline'"`
+	LastSeen       *time.Time `json:"lastSeen" gorm:"column:last_seen_at"`
+	Signature      string     `json:"signature" gorm:"size:255"`
+	Gender         string     `json:"gender" gorm:"type:enum('unknown','male','female');default:'unknown'"`
+	Birthday       *time.Time `json:"birthday" gorm:"type:date"`
+	Region         string     `json:"region" gorm:"size:100"`
+	FollowerCount  int64      `json:"followerCount" gorm:"column:follower_count;not null;default:0"`
+	FollowingCount int64      `json:"followingCount" gorm:"column:following_count;not null;default:0"`
+	AccountType    string     `json:"accountType" gorm:"column:account_type;type:enum('normal','aibot','system');default:'normal';index"`
+	CreatedAt      time.Time  `json:"createdAt" gorm:"not null"`
+}
+
+// IsOfficial 是否为官方账号（系统账号或AI机器人）
+func (u *User) IsOfficial() bool {
+	return u.AccountType == AccountTypeSystem || u.AccountType == AccountTypeAIBot
 }
 
 // UserResponse 用户响应模型
@@ -23,8 +51,37 @@ type UserResponse struct {
 	Nickname  string `json:"nickname"`
 	Avatar    string `json:"avatar"`
 	Status    string `json:"status"`
-	LastSeen  string `json:"lastSeen"`
+	LastSeen  int64  `json:"lastSeen"` // 最后活跃时间（毫秒），0表示从未上线
 	Signature string `json:"signature"`
+	Gender    string `json:"gender"`
+	Birthday  string `json:"birthday"` // 生日（YYYY-MM-DD），未设置为空
+	Region    string `json:"region"`
+}
+
+// UpdateProfileRequest 更新个人资料请求，未传的字段保持不变
+// avatar必须是自己通过上传接口上传的图片地址，birthday格式为YYYY-MM-DD，传空字符串表示清除
+type UpdateProfileRequest struct {
+	Nickname  *string `json:"nickname" binding:"omitempty,min=1,max=100"`
+	Avatar    *string `json:"avatar" binding:"omitempty,max=255"`
+	Signature *string `json:"signature" binding:"omitempty,max=255"`
+	Gender    *string `json:"gender" binding:"omitempty,oneof=unknown male female"`
+	Birthday  *string `json:"birthday"`
+	Region    *string `json:"region" binding:"omitempty,max=100"`
+}
+
+// ProfileResponse 用户公开主页响应
+type ProfileResponse struct {
+	User           *UserResponse      `json:"user"`
+	IsSelf         bool               `json:"isSelf"`
+	IsFriend       bool               `json:"isFriend"`
+	IsFollowing    bool               `json:"isFollowing"`  // 当前用户是否关注了该用户
+	IsFollowedBy   bool               `json:"isFollowedBy"` // 该用户是否关注了当前用户
+	FollowerCount  int64              `json:"followerCount"`
+	FollowingCount int64              `json:"followingCount"`
+	ContentCount   int64              `json:"contentCount"` // 当前用户可见的发布内容数
+	BlogCount      int64              `json:"blogCount"`
+	Contents       []*ContentResponse `json:"contents"` // 最近的发布内容
+	Blogs          []Blog             `json:"blogs"`    // 最近的博客
 }
 
 // FriendResponse 好友响应模型
@@ -34,6 +91,21 @@ type FriendResponse struct {
 	Avatar     string `json:"avatar"`
 	Online     bool   `json:"online"`
 	IsOfficial bool   `json:"isOfficial"`
-	LastActive string `json:"lastActive"`
+	LastActive int64  `json:"lastActive"` // 最后活跃时间（毫秒）
 	FriendType string `json:"friendType"`
-} 
\ No newline at end of file
+}
+
+// 用户在线状态
+const (
+	PresenceOnline  = "online"
+	PresenceAway    = "away"
+	PresenceOffline = "offline"
+)
+
+// PresenceResponse 用户在线状态响应
+type PresenceResponse struct {
+	UserID   uint   `json:"userId"`
+	Status   string `json:"status"`
+	Online   bool   `json:"online"`
+	LastSeen int64  `json:"lastSeen"`
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"ticktok-service/internal/model"
+)
+
+// HTTPResponder 调用兼容OpenAI Chat Completions接口的大模型服务生成回复
+type HTTPResponder struct {
+	url          string
+	apiKey       string
+	model        string
+	systemPrompt string
+	client       *http.Client
+}
+
+// chatCompletionRequest Chat Completions请求体
+type chatCompletionRequest struct {
+	Model    string          `json:"model"`
+	Messages []model.BotTurn `json:"messages"`
+}
+
+// chatCompletionResponse Chat Completions响应体中用到的字段
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message model.BotTurn `json:"message"`
+	} `json:"choices"`
+}
+
+// NewHTTPResponder 创建大模型回复器，systemPrompt为空时使用以机器人昵称生成的默认提示词
+// 超时由调用方通过context控制
+func NewHTTPResponder(url, apiKey, modelName, systemPrompt string) *HTTPResponder {
+	return &HTTPResponder{
+		url:          url,
+		apiKey:       apiKey,
+		model:        modelName,
+		systemPrompt: systemPrompt,
+		client:       &http.Client{},
+	}
+}
+
+// Reply 将系统提示词、历史对话和最新消息发送给大模型，返回第一条候选回复
+func (r *HTTPResponder) Reply(ctx context.Context, req *model.BotRequest) (string, error) {
+	prompt := r.systemPrompt
+	if prompt == "" {
+		prompt = "你是" + req.BotName + "，一个友好的聊天助手，请用简洁的中文回复用户。"
+	}
+
+	messages := make([]model.BotTurn, 0, len(req.History)+2)
+	messages = append(messages, model.BotTurn{Role: "system", Content: prompt})
+	messages = append(messages, req.History...)
+	messages = append(messages, model.BotTurn{Role: model.BotRoleUser, Content: req.Content})
+
+	body, err := json.Marshal(&chatCompletionRequest{Model: r.model, Messages: messages})
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if r.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("大模型服务返回状态码%d: %s", resp.StatusCode, detail)
+	}
+
+	var result chatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if len(result.Choices) == 0 {
+		return "", errors.New("大模型服务没有返回回复")
+	}
+	return result.Choices[0].Message.Content, nil
+}
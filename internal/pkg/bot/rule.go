@@ -0,0 +1,40 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"ticktok-service/internal/model"
+)
+
+// Rule 关键词回复规则
+type Rule struct {
+	Keyword string
+	Reply   string
+}
+
+// RuleResponder 按关键词规则回复，没有命中时回复fallback或原样复述，用于本地开发和测试
+type RuleResponder struct {
+	rules    []Rule
+	fallback string
+}
+
+// NewRuleResponder 创建规则回复器，规则按顺序匹配
+func NewRuleResponder(rules []Rule, fallback string) *RuleResponder {
+	return &RuleResponder{
+		rules:    rules,
+		fallback: fallback,
+	}
+}
+
+// Reply 返回第一条关键词出现在消息中的规则的回复
+func (r *RuleResponder) Reply(ctx context.Context, req *model.BotRequest) (string, error) {
+	for _, rule := range r.rules {
+		if rule.Keyword != "" && strings.Contains(req.Content, rule.Keyword) {
+			return rule.Reply, nil
+		}
+	}
+	if r.fallback != "" {
+		return r.fallback, nil
+	}
+	return "你说：" + req.Content, nil
+}
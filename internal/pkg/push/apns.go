@@ -0,0 +1,145 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	apnsProductionHost = "https://api.push.apple.com"
+	apnsSandboxHost    = "https://api.sandbox.push.apple.com"
+	// 苹果要求鉴权令牌在1小时内刷新，且刷新间隔不少于20分钟
+	apnsTokenTTL = 50 * time.Minute
+)
+
+// apnsProvider 基于令牌鉴权（.p8密钥）的APNs推送渠道，使用HTTP/2接口
+type apnsProvider struct {
+	key    *ecdsa.PrivateKey
+	keyID  string
+	teamID string
+	topic  string
+	host   string
+	client *http.Client
+
+	mu        sync.Mutex
+	token     string
+	tokenTime time.Time
+}
+
+// NewAPNsProvider 创建APNs推送渠道，topic为应用的Bundle ID
+func NewAPNsProvider(keyFile, keyID, teamID, topic string, production bool) (Provider, error) {
+	pem, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("解析APNs密钥失败: %w", err)
+	}
+
+	host := apnsSandboxHost
+	if production {
+		host = apnsProductionHost
+	}
+	return &apnsProvider{
+		key:    key,
+		keyID:  keyID,
+		teamID: teamID,
+		topic:  topic,
+		host:   host,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name 渠道名称
+func (p *apnsProvider) Name() string {
+	return ProviderAPNs
+}
+
+// Send 发送通知，Data中的键值放在aps之外供客户端读取
+func (p *apnsProvider) Send(ctx context.Context, token string, notification *Notification) error {
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{
+				"title": notification.Title,
+				"body":  notification.Body,
+			},
+			"sound": "default",
+		},
+	}
+	for key, value := range notification.Data {
+		payload[key] = value
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	authToken, err := p.authToken()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.host+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("authorization", "bearer "+authToken)
+	req.Header.Set("apns-topic", p.topic)
+	req.Header.Set("apns-push-type", "alert")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var result struct {
+		Reason string `json:"reason"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	switch {
+	case resp.StatusCode == http.StatusGone,
+		result.Reason == "BadDeviceToken", result.Reason == "DeviceTokenNotForTopic":
+		return ErrInvalidToken
+	default:
+		return fmt.Errorf("APNs返回状态码%d: %s", resp.StatusCode, result.Reason)
+	}
+}
+
+// authToken 获取鉴权令牌，过期前复用同一个
+func (p *apnsProvider) authToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Since(p.tokenTime) < apnsTokenTTL {
+		return p.token, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.keyID
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", err
+	}
+
+	p.token = signed
+	p.tokenTime = now
+	return signed, nil
+}
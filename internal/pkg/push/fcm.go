@@ -0,0 +1,172 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	fcmScope    = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmEndpoint = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+)
+
+// fcmCredentials Firebase服务账号密钥文件中用到的字段
+type fcmCredentials struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// fcmProvider 基于HTTP v1接口的FCM推送渠道，使用服务账号换取访问令牌
+type fcmProvider struct {
+	credentials fcmCredentials
+	key         *rsa.PrivateKey
+	client      *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMProvider 创建FCM推送渠道，credentialsFile为服务账号的JSON密钥文件
+func NewFCMProvider(credentialsFile string) (Provider, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var credentials fcmCredentials
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("解析FCM服务账号失败: %w", err)
+	}
+	if credentials.ProjectID == "" || credentials.ClientEmail == "" || credentials.TokenURI == "" {
+		return nil, fmt.Errorf("FCM服务账号缺少project_id、client_email或token_uri")
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(credentials.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("解析FCM私钥失败: %w", err)
+	}
+
+	return &fcmProvider{
+		credentials: credentials,
+		key:         key,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name 渠道名称
+func (p *fcmProvider) Name() string {
+	return ProviderFCM
+}
+
+// Send 发送通知，FCM返回UNREGISTERED表示令牌已失效
+func (p *fcmProvider) Send(ctx context.Context, token string, notification *Notification) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": token,
+			"notification": map[string]string{
+				"title": notification.Title,
+				"body":  notification.Body,
+			},
+			"data": notification.Data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	accessToken, err := p.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf(fcmEndpoint, p.credentials.ProjectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode == http.StatusNotFound || strings.Contains(string(detail), "UNREGISTERED") {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("FCM返回状态码%d: %s", resp.StatusCode, detail)
+}
+
+// token 获取访问令牌，到期前一分钟刷新
+func (p *fcmProvider) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Before(p.expiresAt.Add(-time.Minute)) {
+		return p.accessToken, nil
+	}
+
+	// 用服务账号私钥签名的JWT换取OAuth2访问令牌
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   p.credentials.ClientEmail,
+		"scope": fcmScope,
+		"aud":   p.credentials.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(p.key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.credentials.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("获取FCM访问令牌失败，状态码%d", resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	p.accessToken = result.AccessToken
+	p.expiresAt = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	return p.accessToken, nil
+}
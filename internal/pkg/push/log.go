@@ -0,0 +1,44 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+)
+
+// logProvider 将通知写入日志，用于开发和测试环境
+type logProvider struct {
+	logger *log.Logger
+}
+
+// NewLogProvider 创建日志推送渠道，file为空时写入标准日志，否则以JSON行追加到文件
+func NewLogProvider(file string) (Provider, error) {
+	if file == "" {
+		return &logProvider{logger: log.Default()}, nil
+	}
+
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &logProvider{logger: log.New(f, "", 0)}, nil
+}
+
+// Name 渠道名称
+func (p *logProvider) Name() string {
+	return ProviderLog
+}
+
+// Send 记录一条通知
+func (p *logProvider) Send(ctx context.Context, token string, notification *Notification) error {
+	line, err := json.Marshal(struct {
+		Token string `json:"token"`
+		*Notification
+	}{token, notification})
+	if err != nil {
+		return err
+	}
+	p.logger.Println(string(line))
+	return nil
+}
@@ -0,0 +1,75 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"log"
+	"ticktok-service/config"
+)
+
+// 推送渠道名称，与设备令牌登记时的provider对应
+const (
+	ProviderAPNs    = "apns"
+	ProviderFCM     = "fcm"
+	ProviderWebhook = "webhook"
+	ProviderLog     = "log"
+)
+
+// ErrInvalidToken 设备令牌已失效，调用方应删除该令牌
+var ErrInvalidToken = errors.New("设备令牌已失效")
+
+// Notification 推送通知内容
+type Notification struct {
+	Title string            `json:"title"`
+	Body  string            `json:"body"`
+	Data  map[string]string `json:"data,omitempty"` // 客户端点击通知后的跳转参数
+}
+
+// Provider 推送渠道
+type Provider interface {
+	// Name 渠道名称
+	Name() string
+	// Send 向单个设备发送通知，令牌失效时返回ErrInvalidToken
+	Send(ctx context.Context, token string, notification *Notification) error
+}
+
+// LoadProviders 按配置创建已启用的推送渠道，以渠道名称为键
+// 配置有误的渠道记录日志后跳过，不影响其他渠道
+func LoadProviders() map[string]Provider {
+	pushConfig := config.AppConfig.Push
+	providers := make(map[string]Provider)
+
+	if pushConfig.APNs.KeyFile != "" {
+		provider, err := NewAPNsProvider(pushConfig.APNs.KeyFile, pushConfig.APNs.KeyID,
+			pushConfig.APNs.TeamID, pushConfig.APNs.Topic, pushConfig.APNs.Production)
+		if err != nil {
+			log.Printf("初始化APNs推送失败: %v", err)
+		} else {
+			providers[ProviderAPNs] = provider
+		}
+	}
+
+	if pushConfig.FCM.CredentialsFile != "" {
+		provider, err := NewFCMProvider(pushConfig.FCM.CredentialsFile)
+		if err != nil {
+			log.Printf("初始化FCM推送失败: %v", err)
+		} else {
+			providers[ProviderFCM] = provider
+		}
+	}
+
+	if pushConfig.Webhook.URL != "" {
+		providers[ProviderWebhook] = NewWebhookProvider(pushConfig.Webhook.URL, pushConfig.Webhook.Secret)
+	}
+
+	if pushConfig.Log.Enabled {
+		provider, err := NewLogProvider(pushConfig.Log.File)
+		if err != nil {
+			log.Printf("初始化日志推送失败: %v", err)
+		} else {
+			providers[ProviderLog] = provider
+		}
+	}
+
+	return providers
+}
@@ -0,0 +1,77 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// webhookProvider 将通知以JSON POST到自建的推送网关
+type webhookProvider struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// webhookPayload 推送网关收到的请求体
+type webhookPayload struct {
+	Token string `json:"token"`
+	*Notification
+}
+
+// NewWebhookProvider 创建Webhook推送渠道
+// secret不为空时，请求头X-Signature为请求体的HMAC-SHA256签名（十六进制）
+func NewWebhookProvider(url, secret string) Provider {
+	return &webhookProvider{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name 渠道名称
+func (p *webhookProvider) Name() string {
+	return ProviderWebhook
+}
+
+// Send 发送通知，网关返回404或410表示令牌已失效
+func (p *webhookProvider) Send(ctx context.Context, token string, notification *Notification) error {
+	body, err := json.Marshal(&webhookPayload{Token: token, Notification: notification})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.secret != "" {
+		mac := hmac.New(sha256.New, []byte(p.secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusGone:
+		return ErrInvalidToken
+	default:
+		return fmt.Errorf("推送网关返回状态码%d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,76 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"ticktok-service/config"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// 令牌类型
+const (
+	TypeAccess  = "access"
+	TypeRefresh = "refresh"
+)
+
+// ErrInvalidToken 令牌无效或已过期
+var ErrInvalidToken = errors.New("令牌无效或已过期")
+
+// Claims JWT载荷
+type Claims struct {
+	UserID    uint   `json:"uid"`
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// Generate 为用户签发指定类型的令牌，返回令牌字符串和载荷
+func Generate(userID uint, tokenType string) (string, *Claims, error) {
+	jwtConfig := config.AppConfig.JWT
+
+	ttl := jwtConfig.AccessTokenTTL
+	if tokenType == TypeRefresh {
+		ttl = jwtConfig.RefreshTokenTTL
+	}
+
+	now := time.Now()
+	claims := &Claims{
+		UserID:    userID,
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    jwtConfig.Issuer,
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(jwtConfig.Secret))
+	if err != nil {
+		return "", nil, fmt.Errorf("签发令牌失败: %w", err)
+	}
+
+	return signed, claims, nil
+}
+
+// Parse 解析并校验令牌，要求令牌类型与期望一致
+func Parse(tokenString, expectedType string) (*Claims, error) {
+	claims := &Claims{}
+	parsed, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(config.AppConfig.JWT.Secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !parsed.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.TokenType != expectedType || claims.UserID == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
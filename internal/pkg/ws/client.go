@@ -0,0 +1,139 @@
+package ws
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// 写超时
+	writeWait = 10 * time.Second
+	// 等待客户端pong的超时时间
+	pongWait = 60 * time.Second
+	// 服务端发送ping的周期，必须小于pongWait
+	pingPeriod = (pongWait * 9) / 10
+	// 单条客户端消息的最大长度
+	maxMessageSize = 4096
+	// 每个连接的发送缓冲区大小
+	sendBufferSize = 256
+)
+
+// Client 单个WebSocket连接
+type Client struct {
+	hub       *Hub
+	userID    uint
+	conn      *websocket.Conn
+	send      chan []byte
+	closeOnce sync.Once
+}
+
+// Serve 接管已升级的WebSocket连接，阻塞直至连接断开
+func (h *Hub) Serve(conn *websocket.Conn, userID uint) {
+	client := &Client{
+		hub:    h,
+		userID: userID,
+		conn:   conn,
+		send:   make(chan []byte, sendBufferSize),
+	}
+	if h.register(client) && h.listener != nil {
+		h.listener.UserConnected(userID)
+	}
+
+	go client.writePump()
+	client.readPump()
+}
+
+// closeSlow 关闭连接，读写协程随之退出
+func (c *Client) closeSlow() {
+	c.closeOnce.Do(func() {
+		c.conn.Close()
+	})
+}
+
+// readPump 读取客户端消息，处理心跳并将业务事件分发给注册的处理器
+func (c *Client) readPump() {
+	defer func() {
+		if c.hub.unregister(c) && c.hub.listener != nil {
+			c.hub.listener.UserDisconnected(c.userID)
+		}
+		c.closeSlow()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		// 任何客户端消息都视为活跃，刷新读超时
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		if c.hub.listener != nil {
+			c.hub.listener.UserActive(c.userID)
+		}
+
+		var event inboundEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			continue
+		}
+
+		if event.Type == EventPing {
+			c.reply(NewEvent(EventPong, nil))
+			continue
+		}
+		if handler, ok := c.hub.handlers[event.Type]; ok {
+			handler(c.userID, event.Data)
+		}
+	}
+}
+
+// reply 向当前连接发送事件，仅在读协程中调用，此时连接尚未注销
+func (c *Client) reply(event *Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	select {
+	case c.send <- payload:
+	default:
+		c.closeSlow()
+	}
+}
+
+// writePump 将发送缓冲区的消息写入连接，并定期发送ping
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.closeSlow()
+	}()
+
+	for {
+		select {
+		case payload, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				// 连接已注销
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
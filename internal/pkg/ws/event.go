@@ -0,0 +1,49 @@
+package ws
+
+import "encoding/json"
+
+// 推送事件类型
+const (
+	EventMessageNew          = "message.new"          // 新消息
+	EventMessageStatus       = "message.status"       // 消息状态变更
+	EventMessageRead         = "message.read"         // 已读回执
+	EventMessageDelivered    = "message.delivered"    // 送达回执
+	EventMessageRecall       = "message.recall"       // 消息撤回
+	EventMessageEdit         = "message.edit"         // 消息编辑
+	EventMessageReaction     = "message.reaction"     // 表情回应变更
+	EventChatTyping          = "chat.typing"          // 输入状态，客户端上报与服务端转发共用
+	EventConversationUpdated = "conversation.updated" // 会话设置变更，同步到用户的其他设备
+	EventFriendRequest       = "friend.request"       // 收到好友申请
+	EventFriendAccepted      = "friend.accepted"      // 好友申请已通过
+	EventPing                = "ping"                 // 客户端心跳
+	EventPong                = "pong"                 // 心跳响应
+)
+
+// Event 推送事件
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// NewEvent 创建推送事件
+func NewEvent(eventType string, data interface{}) *Event {
+	return &Event{
+		Type: eventType,
+		Data: data,
+	}
+}
+
+// inboundEvent 客户端发来的事件，Data保留原始JSON交给对应的处理器解析
+type inboundEvent struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// EventHandlerFunc 处理客户端发来的业务事件
+// 在连接的读协程中同步执行，实现方应尽快返回
+type EventHandlerFunc func(userID uint, data json.RawMessage)
+
+// HandleEvent 注册客户端事件的处理器，须在开始接受连接前调用
+func (h *Hub) HandleEvent(eventType string, handler EventHandlerFunc) {
+	h.handlers[eventType] = handler
+}
@@ -0,0 +1,96 @@
+package ws
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+)
+
+// Hub 管理所有在线WebSocket连接，同一用户可同时在多个设备上在线
+type Hub struct {
+	mu       sync.RWMutex
+	clients  map[uint]map[*Client]struct{}
+	listener PresenceListener
+	handlers map[string]EventHandlerFunc
+}
+
+// NewHub 创建连接中心
+func NewHub() *Hub {
+	return &Hub{
+		clients:  make(map[uint]map[*Client]struct{}),
+		handlers: make(map[string]EventHandlerFunc),
+	}
+}
+
+// register 登记连接，返回是否为该用户的第一个在线设备
+func (h *Hub) register(client *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	first := len(h.clients[client.userID]) == 0
+	if h.clients[client.userID] == nil {
+		h.clients[client.userID] = make(map[*Client]struct{})
+	}
+	h.clients[client.userID][client] = struct{}{}
+	return first
+}
+
+// unregister 注销连接并关闭其发送通道，返回该用户是否已没有在线设备
+func (h *Hub) unregister(client *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	devices, ok := h.clients[client.userID]
+	if !ok {
+		return false
+	}
+	if _, ok := devices[client]; !ok {
+		return false
+	}
+
+	delete(devices, client)
+	close(client.send)
+	if len(devices) == 0 {
+		delete(h.clients, client.userID)
+		return true
+	}
+	return false
+}
+
+// IsOnline 判断用户是否至少有一个在线连接
+func (h *Hub) IsOnline(userID uint) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients[userID]) > 0
+}
+
+// DisconnectUser 断开用户的所有在线连接，读协程退出时注销连接
+func (h *Hub) DisconnectUser(userID uint) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients[userID] {
+		client.closeSlow()
+	}
+}
+
+// SendToUser 向用户的所有在线设备推送事件，用户不在线时直接忽略
+func (h *Hub) SendToUser(userID uint, event *Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("序列化推送事件失败: %v", err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for client := range h.clients[userID] {
+		select {
+		case client.send <- payload:
+		default:
+			// 发送缓冲区已满，说明客户端消费过慢，由写协程关闭连接
+			client.closeSlow()
+		}
+	}
+}
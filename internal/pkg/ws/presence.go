@@ -0,0 +1,17 @@
+package ws
+
+// PresenceListener 接收用户连接状态变化的回调
+// 回调在连接的读协程中同步执行，实现方应尽快返回
+type PresenceListener interface {
+	// UserConnected 用户的第一个设备建立连接
+	UserConnected(userID uint)
+	// UserDisconnected 用户的最后一个设备断开连接
+	UserDisconnected(userID uint)
+	// UserActive 用户通过任意设备发送了消息或心跳
+	UserActive(userID uint)
+}
+
+// SetPresenceListener 设置连接状态监听器，须在开始接受连接前调用
+func (h *Hub) SetPresenceListener(listener PresenceListener) {
+	h.listener = listener
+}
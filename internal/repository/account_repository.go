@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"errors"
+	"ticktok-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AccountRepository 账号注销与个人数据导出数据仓库接口
+type AccountRepository interface {
+	GetDeletion(userID uint) (*model.AccountDeletion, error)
+	SaveDeletion(deletion *model.AccountDeletion) error
+	CancelDeletion(userID uint) (bool, error)
+	GetDueDeletions(now time.Time, limit int) ([]*model.AccountDeletion, error)
+	PurgeUser(userID uint, now time.Time, removeFiles func(paths []string) error) error
+	CreateExport(export *model.DataExport) error
+	GetExport(id uint) (*model.DataExport, error)
+	GetExports(userID uint) ([]*model.DataExport, error)
+	UpdateExport(export *model.DataExport) error
+	HasPendingExport(userID uint) (bool, error)
+	FailPendingExports(reason string) error
+	GetExpiredExports(now time.Time) ([]*model.DataExport, error)
+	GetArchive(userID uint) (*model.AccountArchive, error)
+}
+
+// errUnknownOwner 用户ID为0表示作者未知的历史数据，不能按用户清除
+var errUnknownOwner = errors.New("用户ID为0，不能清除作者未知的数据")
+
+// accountRepository 账号注销与个人数据导出数据仓库实现
+type accountRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountRepository 创建账号注销与个人数据导出数据仓库
+func NewAccountRepository(db *gorm.DB) AccountRepository {
+	return &accountRepository{
+		db: db,
+	}
+}
+
+// GetDeletion 获取用户的注销申请
+func (r *accountRepository) GetDeletion(userID uint) (*model.AccountDeletion, error) {
+	var deletion model.AccountDeletion
+	if err := r.db.Where("user_id = ?", userID).First(&deletion).Error; err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// SaveDeletion 保存注销申请，已存在时覆盖
+func (r *accountRepository) SaveDeletion(deletion *model.AccountDeletion) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(deletion).Error
+}
+
+// CancelDeletion 撤销仍在冷静期内的注销申请，返回是否有申请被撤销
+func (r *accountRepository) CancelDeletion(userID uint) (bool, error) {
+	result := r.db.Where("user_id = ? AND completed_at IS NULL", userID).Delete(&model.AccountDeletion{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetDueDeletions 获取冷静期已结束、尚未执行的注销申请
+func (r *accountRepository) GetDueDeletions(now time.Time, limit int) ([]*model.AccountDeletion, error) {
+	var deletions []*model.AccountDeletion
+	if err := r.db.Where("completed_at IS NULL AND scheduled_at <= ?", now).
+		Order("scheduled_at").
+		Limit(limit).
+		Find(&deletions).Error; err != nil {
+		return nil, err
+	}
+	return deletions, nil
+}
+
+// PurgeUser 在一个事务中注销账号：匿名化用户资料，清空其发送的消息内容，退出所在的群聊，
+// 删除草稿、发布内容、博客、轮播内容、上传记录、登录凭证和社交关系，并把隐私设置改为谁都不能联系
+// 先锁定仍未完成的注销申请，申请已被撤销时返回gorm.ErrRecordNotFound且不做任何修改；
+// 磁盘上的上传文件和导出压缩包在确认申请有效后交给removeFiles删除，删除失败时整个事务回滚
+func (r *accountRepository) PurgeUser(userID uint, now time.Time, removeFiles func(paths []string) error) error {
+	if userID == 0 {
+		return errUnknownOwner
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定注销申请，与撤销互斥
+		var deletion model.AccountDeletion
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND completed_at IS NULL", userID).
+			First(&deletion).Error; err != nil {
+			return err
+		}
+
+		var paths []string
+		if err := tx.Model(&model.MediaFile{}).Where("user_id = ?", userID).Pluck("file_path", &paths).Error; err != nil {
+			return err
+		}
+		var exportPaths []string
+		if err := tx.Model(&model.DataExport{}).Where("user_id = ? AND file_path <> ''", userID).Pluck("file_path", &exportPaths).Error; err != nil {
+			return err
+		}
+		if err := removeFiles(append(paths, exportPaths...)); err != nil {
+			return err
+		}
+
+		// 逐个退出群聊，群主退出时转让或解散
+		var groupIDs []uint
+		if err := tx.Model(&model.GroupMember{}).Where("user_id = ?", userID).Pluck("group_id", &groupIDs).Error; err != nil {
+			return err
+		}
+		for _, groupID := range groupIDs {
+			if err := leaveGroup(tx, groupID, userID); err != nil {
+				return err
+			}
+		}
+
+		// 博客和轮播内容连同图片、标签、评论一起删除
+		blogIDs := tx.Model(&model.Blog{}).Select("id").Scopes(authoredBy(userID))
+		itemIDs := tx.Model(&model.SlideItem{}).Select("item_id").Where("user_id = ?", userID)
+		postDeletes := []struct {
+			model interface{}
+			query string
+			arg   interface{}
+		}{
+			{&model.BlogImage{}, "blog_id IN (?)", blogIDs},
+			{&model.BlogTag{}, "blog_id IN (?)", blogIDs},
+			{&model.Comment{}, "blog_id IN (?)", blogIDs},
+			{&model.SlideItemLabel{}, "item_id IN (?)", itemIDs},
+			{&model.SlideAlbumImage{}, "item_id IN (?)", itemIDs},
+		}
+		for _, d := range postDeletes {
+			if err := tx.Where(d.query, d.arg).Delete(d.model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Scopes(authoredBy(userID)).Delete(&model.Blog{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.SlideItem{}).Error; err != nil {
+			return err
+		}
+
+		// 逐条取消关注，同步扣减对方的粉丝数和关注数
+		var follows []*model.UserFollow
+		if err := tx.Where("user_id = ? OR following_id = ?", userID, userID).Find(&follows).Error; err != nil {
+			return err
+		}
+		for _, follow := range follows {
+			if err := removeFollow(tx, follow.UserID, follow.FollowingID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("user_id = ? OR author_id = ?", userID, userID).Delete(&model.FeedItem{}).Error; err != nil {
+			return err
+		}
+
+		// 编辑历史中保存着消息的旧内容，先于消息清空
+		if err := tx.Where("message_id IN (?)", tx.Model(&model.Message{}).Select("id").Where("sender_id = ?", userID)).
+			Delete(&model.MessageEdit{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Message{}).Where("sender_id = ?", userID).Updates(map[string]interface{}{
+			"content":  "",
+			"payload":  "",
+			"caption":  "",
+			"duration": "",
+		}).Error; err != nil {
+			return err
+		}
+
+		params := map[string]interface{}{"id": userID}
+		deletes := []struct {
+			model interface{}
+			query string
+		}{
+			{&model.Draft{}, "user_id = @id"},
+			{&model.Content{}, "user_id = @id"},
+			{&model.MediaFile{}, "user_id = @id"},
+			{&model.Friendship{}, "user_id = @id OR friend_id = @id"},
+			{&model.FriendRequest{}, "from_user_id = @id OR to_user_id = @id"},
+			{&model.UserBlock{}, "user_id = @id OR blocked_id = @id"},
+			{&model.ConversationMute{}, "user_id = @id OR peer_id = @id"},
+			{&model.ConversationSetting{}, "user_id = @id"},
+			{&model.UnreadMessage{}, "user_id = @id"},
+			{&model.MessageReaction{}, "user_id = @id"},
+			{&model.DeviceToken{}, "user_id = @id"},
+			{&model.NotificationPreference{}, "user_id = @id"},
+			{&model.RefreshToken{}, "user_id = @id"},
+			{&model.UserCredential{}, "user_id = @id"},
+			{&model.DataExport{}, "user_id = @id"},
+		}
+		for _, d := range deletes {
+			if err := tx.Where(d.query, params).Delete(d.model).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&model.PrivacySetting{
+			UserID:               userID,
+			WhoCanMessage:        model.PrivacyNobody,
+			FriendListVisibility: model.PrivacyNobody,
+			ShowOnlineStatus:     false,
+			SearchableByUID:      false,
+			UpdatedAt:            now,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"nickname":     model.DeletedUserNickname,
+			"avatar":       "",
+			"signature":    "",
+			"gender":       model.GenderUnknown,
+			"birthday":     nil,
+			"region":       "",
+			"status":       model.PresenceOffline,
+			"last_seen_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&deletion).Update("completed_at", now).Error
+	})
+}
+
+// CreateExport 创建个人数据导出任务
+func (r *accountRepository) CreateExport(export *model.DataExport) error {
+	return r.db.Create(export).Error
+}
+
+// GetExport 根据ID获取个人数据导出任务
+func (r *accountRepository) GetExport(id uint) (*model.DataExport, error) {
+	var export model.DataExport
+	if err := r.db.Where("id = ?", id).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// GetExports 获取用户的个人数据导出任务，最新的在前
+func (r *accountRepository) GetExports(userID uint) ([]*model.DataExport, error) {
+	var exports []*model.DataExport
+	if err := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&exports).Error; err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+// UpdateExport 保存导出任务的状态
+func (r *accountRepository) UpdateExport(export *model.DataExport) error {
+	return r.db.Save(export).Error
+}
+
+// HasPendingExport 用户是否有正在生成的导出任务
+func (r *accountRepository) HasPendingExport(userID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.DataExport{}).
+		Where("user_id = ? AND status = ?", userID, model.DataExportPending).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FailPendingExports 将上次运行遗留的未完成导出任务标记为失败
+func (r *accountRepository) FailPendingExports(reason string) error {
+	return r.db.Model(&model.DataExport{}).Where("status = ?", model.DataExportPending).Updates(map[string]interface{}{
+		"status":      model.DataExportFailed,
+		"error":       reason,
+		"finished_at": time.Now(),
+	}).Error
+}
+
+// GetExpiredExports 获取压缩包已过期但尚未清理的导出任务
+func (r *accountRepository) GetExpiredExports(now time.Time) ([]*model.DataExport, error) {
+	var exports []*model.DataExport
+	if err := r.db.Where("status = ? AND expires_at <= ?", model.DataExportReady, now).Find(&exports).Error; err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+// GetArchive 读取个人数据导出中直接来自各表的数据，以及有过单聊的用户和所在的群
+func (r *accountRepository) GetArchive(userID uint) (*model.AccountArchive, error) {
+	archive := &model.AccountArchive{}
+	var user model.User
+	if err := r.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, err
+	}
+	archive.User = &user
+
+	var credential model.UserCredential
+	if err := r.db.Where("user_id = ?", userID).Limit(1).Find(&credential).Error; err != nil {
+		return nil, err
+	}
+	archive.Username = credential.Username
+
+	params := map[string]interface{}{"id": userID}
+	queries := []struct {
+		dest  interface{}
+		query string
+	}{
+		{&archive.Devices, "user_id = @id"},
+		{&archive.Friends, "user_id = @id"},
+		{&archive.FriendRequests, "from_user_id = @id OR to_user_id = @id"},
+		{&archive.Following, "user_id = @id"},
+		{&archive.Followers, "following_id = @id"},
+		{&archive.Blocks, "user_id = @id"},
+		{&archive.Mutes, "user_id = @id"},
+		{&archive.ConversationSettings, "user_id = @id"},
+		{&archive.GroupMemberships, "user_id = @id"},
+		{&archive.Reactions, "user_id = @id"},
+		{&archive.Drafts, "user_id = @id"},
+		{&archive.Contents, "user_id = @id"},
+		{&archive.MediaFiles, "user_id = @id"},
+	}
+	for _, q := range queries {
+		if err := r.db.Where(q.query, params).Order("id").Find(q.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.db.Preload("Images").Preload("Tags").Preload("Comments").
+		Scopes(authoredBy(userID)).Order("id").Find(&archive.Blogs).Error; err != nil {
+		return nil, err
+	}
+
+	var sessions []*model.Session
+	if err := r.db.Where("user1_id = ? OR user2_id = ?", userID, userID).Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		peerID := session.User1ID
+		if peerID == userID {
+			peerID = session.User2ID
+		}
+		archive.ChatPeerIDs = append(archive.ChatPeerIDs, peerID)
+	}
+
+	if err := r.db.Where("id IN (?)", r.db.Model(&model.GroupMember{}).Select("group_id").Where("user_id = ?", userID)).
+		Order("id").Find(&archive.Groups).Error; err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
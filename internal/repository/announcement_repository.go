@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"ticktok-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AnnouncementRepository 系统公告数据仓库接口
+type AnnouncementRepository interface {
+	CreateAnnouncement(announcement *model.Announcement) error
+	GetAnnouncementByID(id uint) (*model.Announcement, error)
+	GetAnnouncements(page, pageSize int) ([]*model.Announcement, int64, error)
+	UpdateAnnouncement(id uint, fields map[string]interface{}) error
+	FindRecipientIDs(senderID uint, segment *model.AnnouncementSegment, afterID uint, limit int) ([]uint, error)
+	DeliverAnnouncement(template *model.Message, recipientIDs []uint) ([]*model.Message, error)
+}
+
+// announcementRepository 系统公告数据仓库实现
+type announcementRepository struct {
+	db *gorm.DB
+}
+
+// NewAnnouncementRepository 创建系统公告数据仓库
+func NewAnnouncementRepository(db *gorm.DB) AnnouncementRepository {
+	return &announcementRepository{
+		db: db,
+	}
+}
+
+// CreateAnnouncement 创建公告记录
+func (r *announcementRepository) CreateAnnouncement(announcement *model.Announcement) error {
+	announcement.CreatedAt = time.Now()
+	return r.db.Create(announcement).Error
+}
+
+// GetAnnouncementByID 根据ID获取公告
+func (r *announcementRepository) GetAnnouncementByID(id uint) (*model.Announcement, error) {
+	var announcement model.Announcement
+	if err := r.db.Where("id = ?", id).First(&announcement).Error; err != nil {
+		return nil, err
+	}
+	return &announcement, nil
+}
+
+// GetAnnouncements 分页获取公告，按创建时间倒序
+func (r *announcementRepository) GetAnnouncements(page, pageSize int) ([]*model.Announcement, int64, error) {
+	var total int64
+	if err := r.db.Model(&model.Announcement{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var announcements []*model.Announcement
+	if err := r.db.Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&announcements).Error; err != nil {
+		return nil, 0, err
+	}
+	return announcements, total, nil
+}
+
+// UpdateAnnouncement 更新公告的群发进度
+func (r *announcementRepository) UpdateAnnouncement(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.Announcement{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// FindRecipientIDs 按ID顺序查找afterID之后符合范围的一批接收者
+// 只发给普通用户，屏蔽了系统账号的用户会被排除
+func (r *announcementRepository) FindRecipientIDs(senderID uint, segment *model.AnnouncementSegment, afterID uint, limit int) ([]uint, error) {
+	query := r.db.Model(&model.User{}).
+		Where("id > ? AND account_type = ?", afterID, model.AccountTypeNormal).
+		Where("id NOT IN (?)", r.db.Model(&model.UserBlock{}).Select("user_id").Where("blocked_id = ?", senderID))
+	if len(segment.UserIDs) > 0 {
+		query = query.Where("id IN ?", segment.UserIDs)
+	}
+	if segment.RegisteredAfter > 0 {
+		query = query.Where("created_at >= ?", time.UnixMilli(segment.RegisteredAfter))
+	}
+	if segment.RegisteredBefore > 0 {
+		query = query.Where("created_at < ?", time.UnixMilli(segment.RegisteredBefore))
+	}
+	if segment.ActiveSince > 0 {
+		query = query.Where("last_seen_at >= ?", time.UnixMilli(segment.ActiveSince))
+	}
+
+	var ids []uint
+	if err := query.Order("id ASC").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// DeliverAnnouncement 以template为模板给一批接收者各写入一条单聊消息
+// 消息、会话和未读计数都批量写入，开启免打扰的接收者只保存消息，不增加未读数
+func (r *announcementRepository) DeliverAnnouncement(template *model.Message, recipientIDs []uint) ([]*model.Message, error) {
+	if len(recipientIDs) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	senderID := template.SenderID
+	messages := make([]*model.Message, 0, len(recipientIDs))
+	for _, receiverID := range recipientIDs {
+		message := *template
+		message.SessionID = model.SessionIDFor(senderID, receiverID)
+		message.ReceiverID = receiverID
+		message.CreatedAt = now
+		messages = append(messages, &message)
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 插入消息，批量插入后会回填各条消息的ID
+		if err := tx.Omit(clause.Associations).Create(&messages).Error; err != nil {
+			return err
+		}
+
+		// 创建或更新会话的最后消息
+		sessions := make([]*model.Session, 0, len(messages))
+		for _, message := range messages {
+			user1ID, user2ID := message.SenderID, message.ReceiverID
+			if user1ID > user2ID {
+				user1ID, user2ID = user2ID, user1ID
+			}
+			sessions = append(sessions, &model.Session{
+				ID:            message.SessionID,
+				User1ID:       user1ID,
+				User2ID:       user2ID,
+				LastMessageID: message.ID,
+				UpdatedAt:     now,
+			})
+		}
+		if err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"last_message_id", "updated_at"}),
+		}).Create(&sessions).Error; err != nil {
+			return err
+		}
+
+		return raiseUnreadCounts(tx, senderID, recipientIDs, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// raiseUnreadCounts 将一批接收者来自senderID的单聊未读数各加1
+// 已有未读记录的一次性更新，没有记录的批量创建
+func raiseUnreadCounts(tx *gorm.DB, senderID uint, recipientIDs []uint, now time.Time) error {
+	var mutedIDs []uint
+	if err := tx.Model(&model.ConversationMute{}).
+		Where("user_id IN ? AND peer_id = ? AND group_id = 0", recipientIDs, senderID).
+		Pluck("user_id", &mutedIDs).Error; err != nil {
+		return err
+	}
+	var existingIDs []uint
+	if err := tx.Model(&model.UnreadMessage{}).
+		Where("user_id IN ? AND sender_id = ? AND group_id = 0", recipientIDs, senderID).
+		Pluck("user_id", &existingIDs).Error; err != nil {
+		return err
+	}
+
+	skip := make(map[uint]bool, len(mutedIDs)+len(existingIDs))
+	for _, id := range mutedIDs {
+		skip[id] = true
+	}
+	var raiseIDs []uint
+	for _, id := range existingIDs {
+		if !skip[id] {
+			raiseIDs = append(raiseIDs, id)
+		}
+		skip[id] = true
+	}
+
+	if len(raiseIDs) > 0 {
+		if err := tx.Model(&model.UnreadMessage{}).
+			Where("user_id IN ? AND sender_id = ? AND group_id = 0", raiseIDs, senderID).
+			Updates(map[string]interface{}{
+				"count":      gorm.Expr("count + 1"),
+				"updated_at": now,
+			}).Error; err != nil {
+			return err
+		}
+	}
+
+	var counters []*model.UnreadMessage
+	for _, id := range recipientIDs {
+		if skip[id] {
+			continue
+		}
+		counters = append(counters, &model.UnreadMessage{
+			UserID:    id,
+			SenderID:  senderID,
+			Count:     1,
+			UpdatedAt: now,
+		})
+	}
+	if len(counters) == 0 {
+		return nil
+	}
+	return tx.Omit(clause.Associations).Create(&counters).Error
+}
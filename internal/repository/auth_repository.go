@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"errors"
+	"ticktok-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuthRepository 认证数据仓库接口
+type AuthRepository interface {
+	CreateUserWithCredential(user *model.User, credential *model.UserCredential) error
+	GetCredentialByUsername(username string) (*model.UserCredential, error)
+	GetCredentialByUserID(userID uint) (*model.UserCredential, error)
+	CreateRefreshToken(token *model.RefreshToken) error
+	RotateRefreshToken(tokenID string, userID uint, next *model.RefreshToken) error
+	RevokeRefreshToken(tokenID string) error
+	RevokeUserRefreshTokens(userID uint) error
+}
+
+// uidAllocateRetries 并发注册导致UID冲突时的最大重试次数
+const uidAllocateRetries = 5
+
+// errUIDConflict 分配的UID已被并发注册的用户占用
+var errUIDConflict = errors.New("UID冲突")
+
+// authRepository 认证数据仓库实现
+type authRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthRepository 创建认证数据仓库
+func NewAuthRepository(db *gorm.DB) AuthRepository {
+	return &authRepository{
+		db: db,
+	}
+}
+
+// CreateUserWithCredential 在同一事务中创建用户及其登录凭证
+// UID按当前最大值加一分配，被并发注册的用户占用时由唯一索引拒绝并重新分配
+// 用户名已被占用时返回gorm.ErrDuplicatedKey
+func (r *authRepository) CreateUserWithCredential(user *model.User, credential *model.UserCredential) error {
+	var err error
+	for i := 0; i < uidAllocateRetries; i++ {
+		if err = r.createUserWithCredential(user, credential); !errors.Is(err, errUIDConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// createUserWithCredential 分配UID并创建用户及其登录凭证
+func (r *authRepository) createUserWithCredential(user *model.User, credential *model.UserCredential) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 分配新的UID
+		var maxUID uint
+		if err := tx.Model(&model.User{}).Select("COALESCE(MAX(uid), 100000)").Scan(&maxUID).Error; err != nil {
+			return err
+		}
+		user.UID = maxUID + 1
+		user.CreatedAt = time.Now()
+
+		if err := tx.Create(user).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return errUIDConflict
+			}
+			return err
+		}
+
+		now := time.Now()
+		credential.UserID = user.ID
+		credential.CreatedAt = now
+		credential.UpdatedAt = now
+		return tx.Create(credential).Error
+	})
+}
+
+// GetCredentialByUsername 根据用户名获取登录凭证
+func (r *authRepository) GetCredentialByUsername(username string) (*model.UserCredential, error) {
+	var credential model.UserCredential
+	if err := r.db.Preload("User").Where("username = ?", username).First(&credential).Error; err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// GetCredentialByUserID 根据用户ID获取登录凭证
+func (r *authRepository) GetCredentialByUserID(userID uint) (*model.UserCredential, error) {
+	var credential model.UserCredential
+	if err := r.db.Where("user_id = ?", userID).First(&credential).Error; err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+// CreateRefreshToken 保存刷新令牌
+func (r *authRepository) CreateRefreshToken(token *model.RefreshToken) error {
+	token.CreatedAt = time.Now()
+	return r.db.Create(token).Error
+}
+
+// RotateRefreshToken 在同一事务中作废旧的刷新令牌并保存新的刷新令牌
+// 旧令牌不存在、已作废或不属于该用户时返回gorm.ErrRecordNotFound，并发使用同一令牌时只有一个请求成功
+func (r *authRepository) RotateRefreshToken(tokenID string, userID uint, next *model.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.RefreshToken{}).
+			Where("token_id = ? AND user_id = ? AND revoked = ?", tokenID, userID, false).
+			Update("revoked", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		next.CreatedAt = time.Now()
+		return tx.Create(next).Error
+	})
+}
+
+// RevokeRefreshToken 注销指定刷新令牌
+func (r *authRepository) RevokeRefreshToken(tokenID string) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("token_id = ?", tokenID).
+		Update("revoked", true).Error
+}
+
+// RevokeUserRefreshTokens 注销用户的全部刷新令牌
+func (r *authRepository) RevokeUserRefreshTokens(userID uint) error {
+	return r.db.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked = ?", userID, false).
+		Update("revoked", true).Error
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"ticktok-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BlockRepository 屏蔽与免打扰数据仓库接口
+type BlockRepository interface {
+	BlockUser(userID, blockedID uint) error
+	UnblockUser(userID, blockedID uint) error
+	GetBlocks(userID uint) ([]*model.UserBlock, error)
+	GetBlockedIDs(userID uint) ([]uint, error)
+	IsBlockedEither(userID, otherID uint) (bool, error)
+	GetBlockedEitherIDs(userID uint, otherIDs []uint) ([]uint, error)
+	Mute(userID, peerID, groupID uint) error
+	Unmute(userID, peerID, groupID uint) error
+	GetMutes(userID uint) ([]*model.ConversationMute, error)
+	IsMuted(userID, peerID, groupID uint) (bool, error)
+}
+
+// blockRepository 屏蔽与免打扰数据仓库实现
+type blockRepository struct {
+	db *gorm.DB
+}
+
+// NewBlockRepository 创建屏蔽与免打扰数据仓库
+func NewBlockRepository(db *gorm.DB) BlockRepository {
+	return &blockRepository{
+		db: db,
+	}
+}
+
+// BlockUser 屏蔽用户，并撤销双方之间待处理的好友申请
+func (r *blockRepository) BlockUser(userID, blockedID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		block := model.UserBlock{
+			UserID:    userID,
+			BlockedID: blockedID,
+			CreatedAt: time.Now(),
+		}
+		if err := tx.Where("user_id = ? AND blocked_id = ?", userID, blockedID).
+			FirstOrCreate(&block).Error; err != nil {
+			return err
+		}
+
+		// 屏蔽后双方互相取消关注
+		if err := removeFollow(tx, userID, blockedID); err != nil {
+			return err
+		}
+		if err := removeFollow(tx, blockedID, userID); err != nil {
+			return err
+		}
+
+		return tx.Model(&model.FriendRequest{}).
+			Where("((from_user_id = ? AND to_user_id = ?) OR (from_user_id = ? AND to_user_id = ?)) AND status = ?",
+				userID, blockedID, blockedID, userID, model.FriendRequestPending).
+			Update("status", model.FriendRequestCancelled).Error
+	})
+}
+
+// UnblockUser 取消屏蔽
+func (r *blockRepository) UnblockUser(userID, blockedID uint) error {
+	return r.db.Where("user_id = ? AND blocked_id = ?", userID, blockedID).
+		Delete(&model.UserBlock{}).Error
+}
+
+// GetBlocks 获取用户的屏蔽列表
+func (r *blockRepository) GetBlocks(userID uint) ([]*model.UserBlock, error) {
+	var blocks []*model.UserBlock
+	if err := r.db.Preload("Blocked").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// GetBlockedIDs 获取用户屏蔽的所有用户ID
+func (r *blockRepository) GetBlockedIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.UserBlock{}).
+		Where("user_id = ?", userID).
+		Pluck("blocked_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// IsBlockedEither 判断两个用户之间是否有任意一方屏蔽了对方
+func (r *blockRepository) IsBlockedEither(userID, otherID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.UserBlock{}).
+		Where("(user_id = ? AND blocked_id = ?) OR (user_id = ? AND blocked_id = ?)",
+			userID, otherID, otherID, userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetBlockedEitherIDs 批量判断屏蔽关系，返回otherIDs中与userID之间有任意一方屏蔽了对方的用户ID
+func (r *blockRepository) GetBlockedEitherIDs(userID uint, otherIDs []uint) ([]uint, error) {
+	if len(otherIDs) == 0 {
+		return nil, nil
+	}
+	var blocks []*model.UserBlock
+	if err := r.db.Where("(user_id = ? AND blocked_id IN ?) OR (user_id IN ? AND blocked_id = ?)",
+		userID, otherIDs, otherIDs, userID).
+		Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(blocks))
+	for _, block := range blocks {
+		if block.UserID == userID {
+			ids = append(ids, block.BlockedID)
+		} else {
+			ids = append(ids, block.UserID)
+		}
+	}
+	return ids, nil
+}
+
+// Mute 开启会话免打扰
+func (r *blockRepository) Mute(userID, peerID, groupID uint) error {
+	mute := model.ConversationMute{
+		UserID:    userID,
+		PeerID:    peerID,
+		GroupID:   groupID,
+		CreatedAt: time.Now(),
+	}
+	return r.db.Where("user_id = ? AND peer_id = ? AND group_id = ?", userID, peerID, groupID).
+		FirstOrCreate(&mute).Error
+}
+
+// Unmute 关闭会话免打扰
+func (r *blockRepository) Unmute(userID, peerID, groupID uint) error {
+	return r.db.Where("user_id = ? AND peer_id = ? AND group_id = ?", userID, peerID, groupID).
+		Delete(&model.ConversationMute{}).Error
+}
+
+// GetMutes 获取用户开启免打扰的所有会话
+func (r *blockRepository) GetMutes(userID uint) ([]*model.ConversationMute, error) {
+	var mutes []*model.ConversationMute
+	if err := r.db.Where("user_id = ?", userID).Find(&mutes).Error; err != nil {
+		return nil, err
+	}
+	return mutes, nil
+}
+
+// IsMuted 判断用户是否对会话开启了免打扰，单聊时groupID为0，群聊时peerID为0
+func (r *blockRepository) IsMuted(userID, peerID, groupID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.ConversationMute{}).
+		Where("user_id = ? AND peer_id = ? AND group_id = ?", userID, peerID, groupID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// excludeAuthors 排除指定用户发布的内容，用于按屏蔽列表过滤内容列表
+func excludeAuthors(userIDs []uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(userIDs) == 0 {
+			return db
+		}
+		return db.Where("user_id NOT IN ?", userIDs)
+	}
+}
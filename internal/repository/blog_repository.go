@@ -2,14 +2,20 @@ package repository
 
 import (
 	"fmt"
+	"strconv"
 	"ticktok-service/internal/model"
+
+	"gorm.io/gorm"
 )
 
 // BlogRepository 博客仓库接口
 type BlogRepository interface {
-	GetBlogs(page, pageSize int) ([]model.Blog, int64, error)
+	GetBlogs(page, pageSize int, excludeUserIDs []uint) ([]model.Blog, int64, error)
 	GetBlogByID(id uint) (*model.Blog, error)
-	SearchBlogs(keyword string, page, pageSize int) ([]model.Blog, int64, error)
+	SearchBlogs(keyword string, page, pageSize int, excludeUserIDs []uint) ([]model.Blog, int64, error)
+	GetUserBlogs(userID uint, limit int) ([]model.Blog, error)
+	CountUserBlogs(userID uint) (int64, error)
+	GetBlogsByIDs(ids []uint) ([]model.Blog, error)
 }
 
 // blogRepository 博客仓库实现
@@ -20,19 +26,19 @@ func NewBlogRepository() BlogRepository {
 	return &blogRepository{}
 }
 
-// GetBlogs 获取博客列表
-func (r *blogRepository) GetBlogs(page, pageSize int) ([]model.Blog, int64, error) {
+// GetBlogs 获取博客列表，excludeUserIDs发布的博客会被过滤
+func (r *blogRepository) GetBlogs(page, pageSize int, excludeUserIDs []uint) ([]model.Blog, int64, error) {
 	var blogs []model.Blog
 	var count int64
 
 	// 获取总数
-	if err := model.DB.Model(&model.Blog{}).Count(&count).Error; err != nil {
+	if err := model.DB.Model(&model.Blog{}).Scopes(excludeBlogAuthors(excludeUserIDs)).Count(&count).Error; err != nil {
 		return nil, 0, fmt.Errorf("计算博客总数失败: %w", err)
 	}
 
 	// 分页查询博客
 	offset := (page - 1) * pageSize
-	if err := model.DB.Offset(offset).Limit(pageSize).Find(&blogs).Error; err != nil {
+	if err := model.DB.Scopes(excludeBlogAuthors(excludeUserIDs)).Offset(offset).Limit(pageSize).Find(&blogs).Error; err != nil {
 		return nil, 0, fmt.Errorf("获取博客列表失败: %w", err)
 	}
 
@@ -61,12 +67,12 @@ func (r *blogRepository) GetBlogByID(id uint) (*model.Blog, error) {
 	return &blog, nil
 }
 
-// SearchBlogs 搜索博客
-func (r *blogRepository) SearchBlogs(keyword string, page, pageSize int) ([]model.Blog, int64, error) {
+// SearchBlogs 搜索博客，excludeUserIDs发布的博客会被过滤
+func (r *blogRepository) SearchBlogs(keyword string, page, pageSize int, excludeUserIDs []uint) ([]model.Blog, int64, error) {
 	var blogs []model.Blog
 	var count int64
 
-	query := model.DB.Model(&model.Blog{}).Where(
+	query := model.DB.Model(&model.Blog{}).Scopes(excludeBlogAuthors(excludeUserIDs)).Where(
 		"title LIKE ? OR content LIKE ?", 
 		"%"+keyword+"%", 
 		"%"+keyword+"%",
@@ -91,4 +97,73 @@ func (r *blogRepository) SearchBlogs(keyword string, page, pageSize int) ([]mode
 	}
 
 	return blogs, count, nil
-} 
\ No newline at end of file
+} 
+
+// GetUserBlogs 获取用户最近发布的博客
+func (r *blogRepository) GetUserBlogs(userID uint, limit int) ([]model.Blog, error) {
+	var blogs []model.Blog
+	if err := model.DB.Preload("Images").Preload("Tags").Preload("Comments").
+		Scopes(authoredBy(userID)).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&blogs).Error; err != nil {
+		return nil, fmt.Errorf("获取用户博客失败: %w", err)
+	}
+	return blogs, nil
+}
+
+// CountUserBlogs 统计用户发布的博客数
+func (r *blogRepository) CountUserBlogs(userID uint) (int64, error) {
+	var count int64
+	if err := model.DB.Model(&model.Blog{}).Scopes(authoredBy(userID)).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("计算用户博客数失败: %w", err)
+	}
+	return count, nil
+}
+
+// GetBlogsByIDs 根据ID批量获取博客
+func (r *blogRepository) GetBlogsByIDs(ids []uint) ([]model.Blog, error) {
+	var blogs []model.Blog
+	if len(ids) == 0 {
+		return blogs, nil
+	}
+	if err := model.DB.Preload("Images").Preload("Tags").Preload("Comments").
+		Where("id IN ?", ids).
+		Find(&blogs).Error; err != nil {
+		return nil, fmt.Errorf("批量获取博客失败: %w", err)
+	}
+	return blogs, nil
+}
+
+// blogAuthorCondition 博客的发布者条件，参数依次为用户ID和对应的authorId字符串
+// 未执行 backfill-post-authors 的历史博客user_id为0，按数字authorId匹配，与Blog.PublisherID一致
+const blogAuthorCondition = "(user_id IN (?) OR (user_id = 0 AND author_id IN (?)))"
+
+// blogPublisherColumn 博客发布者用户ID的列表达式，user_id为0时取数字authorId
+const blogPublisherColumn = "IF(user_id = 0, CAST(author_id AS UNSIGNED), user_id)"
+
+// authoredBy 按发布者筛选博客
+func authoredBy(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(blogAuthorCondition, []uint{userID}, blogAuthorKeys([]uint{userID}))
+	}
+}
+
+// excludeBlogAuthors 排除指定用户发布的博客，用于按屏蔽列表过滤博客列表
+func excludeBlogAuthors(userIDs []uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(userIDs) == 0 {
+			return db
+		}
+		return db.Where("NOT "+blogAuthorCondition, userIDs, blogAuthorKeys(userIDs))
+	}
+}
+
+// blogAuthorKeys 将用户ID转换为历史博客authorId的字符串形式
+func blogAuthorKeys(userIDs []uint) []string {
+	keys := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		keys = append(keys, strconv.FormatUint(uint64(id), 10))
+	}
+	return keys
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"ticktok-service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ContentRepository 发布内容数据仓库接口
+type ContentRepository interface {
+	GetUserContents(userID uint, visibilities []string, limit int) ([]*model.Content, error)
+	CountUserContents(userID uint, visibilities []string) (int64, error)
+	GetContentsByIDs(ids []string) ([]*model.Content, error)
+}
+
+// contentRepository 发布内容数据仓库实现
+type contentRepository struct {
+	db *gorm.DB
+}
+
+// NewContentRepository 创建发布内容数据仓库
+func NewContentRepository(db *gorm.DB) ContentRepository {
+	return &contentRepository{
+		db: db,
+	}
+}
+
+// GetUserContents 获取用户最近发布的内容，只返回指定可见范围的内容
+func (r *contentRepository) GetUserContents(userID uint, visibilities []string, limit int) ([]*model.Content, error) {
+	var contents []*model.Content
+	if err := r.db.Where("user_id = ? AND visibility IN ?", userID, visibilities).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&contents).Error; err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+// CountUserContents 统计用户指定可见范围的发布内容数
+func (r *contentRepository) CountUserContents(userID uint, visibilities []string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.Content{}).
+		Where("user_id = ? AND visibility IN ?", userID, visibilities).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetContentsByIDs 根据ID批量获取发布内容
+func (r *contentRepository) GetContentsByIDs(ids []string) ([]*model.Content, error) {
+	var contents []*model.Content
+	if len(ids) == 0 {
+		return contents, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&contents).Error; err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
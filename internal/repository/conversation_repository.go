@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"ticktok-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ConversationRepository 会话设置数据仓库接口
+type ConversationRepository interface {
+	GetSettings(userID uint) ([]*model.ConversationSetting, error)
+	GetSetting(userID uint, sessionID string) (*model.ConversationSetting, error)
+	SaveSetting(setting *model.ConversationSetting) error
+	ClearConversation(setting *model.ConversationSetting) error
+	GetLastMessageID(sessionID string) (uint, error)
+}
+
+// conversationRepository 会话设置数据仓库实现
+type conversationRepository struct {
+	db *gorm.DB
+}
+
+// NewConversationRepository 创建会话设置数据仓库
+func NewConversationRepository(db *gorm.DB) ConversationRepository {
+	return &conversationRepository{
+		db: db,
+	}
+}
+
+// GetSettings 获取用户的所有会话设置
+func (r *conversationRepository) GetSettings(userID uint) ([]*model.ConversationSetting, error) {
+	var settings []*model.ConversationSetting
+	if err := r.db.Where("user_id = ?", userID).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// GetSetting 获取用户对某个会话的设置
+func (r *conversationRepository) GetSetting(userID uint, sessionID string) (*model.ConversationSetting, error) {
+	var setting model.ConversationSetting
+	if err := r.db.Where("user_id = ? AND session_id = ?", userID, sessionID).First(&setting).Error; err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// SaveSetting 保存会话设置，已存在时覆盖
+func (r *conversationRepository) SaveSetting(setting *model.ConversationSetting) error {
+	setting.UpdatedAt = time.Now()
+	return saveSetting(r.db, setting)
+}
+
+// ClearConversation 删除会话：隐藏当前最后一条及之前的消息，清除置顶、归档和草稿，并清零未读数
+func (r *conversationRepository) ClearConversation(setting *model.ConversationSetting) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		lastID, err := lastSessionMessageID(tx, setting.SessionID, 0)
+		if err != nil {
+			return err
+		}
+
+		setting.ClearedMessageID = lastID
+		setting.PinnedAt = nil
+		setting.Archived = false
+		setting.ArchivedMessageID = 0
+		setting.Draft = ""
+		setting.DraftUpdatedAt = nil
+		setting.UpdatedAt = now
+		if err := saveSetting(tx, setting); err != nil {
+			return err
+		}
+
+		unread := tx.Model(&model.UnreadMessage{}).Where("user_id = ?", setting.UserID)
+		if setting.GroupID > 0 {
+			unread = unread.Where("group_id = ?", setting.GroupID)
+		} else {
+			unread = unread.Where("sender_id = ? AND group_id = 0", setting.PeerID)
+		}
+		return unread.Updates(map[string]interface{}{
+			"count":      0,
+			"updated_at": now,
+		}).Error
+	})
+}
+
+// GetLastMessageID 获取会话中最新一条消息的ID，没有消息时返回0
+func (r *conversationRepository) GetLastMessageID(sessionID string) (uint, error) {
+	return lastSessionMessageID(r.db, sessionID, 0)
+}
+
+// saveSetting 按用户和会话插入或覆盖会话设置
+func saveSetting(db *gorm.DB, setting *model.ConversationSetting) error {
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "session_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"pinned_at", "archived", "archived_message_id", "cleared_message_id",
+			"draft", "draft_updated_at", "updated_at",
+		}),
+	}).Create(setting).Error
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"sort"
+	"ticktok-service/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FeedRepository 关注时间线数据仓库接口
+type FeedRepository interface {
+	AddFeedItems(items []*model.FeedItem) error
+	GetInbox(userID uint, query *model.FeedQuery) ([]*model.FeedItem, error)
+	GetAuthorPosts(authorIDs []uint, postTypes []string, query *model.FeedQuery) ([]*model.FeedItem, error)
+	GetFollowingPosts(userID uint, postTypes []string, query *model.FeedQuery) ([]*model.FeedItem, error)
+}
+
+// feedRepository 关注时间线数据仓库实现
+type feedRepository struct {
+	db *gorm.DB
+}
+
+// NewFeedRepository 创建关注时间线数据仓库
+func NewFeedRepository(db *gorm.DB) FeedRepository {
+	return &feedRepository{
+		db: db,
+	}
+}
+
+// AddFeedItems 批量写入收件箱，已存在的条目会被忽略
+func (r *feedRepository) AddFeedItems(items []*model.FeedItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(items, 500).Error
+}
+
+// GetInbox 按发布时间倒序读取用户的收件箱
+func (r *feedRepository) GetInbox(userID uint, query *model.FeedQuery) ([]*model.FeedItem, error) {
+	var items []*model.FeedItem
+	if err := r.db.Where("user_id = ?", userID).
+		Scopes(feedWindow("published_at", query)).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetAuthorPosts 按发布时间倒序直接从作品表读取指定作者的postTypes类型的作品，不包含仅自己可见的发布内容
+func (r *feedRepository) GetAuthorPosts(authorIDs []uint, postTypes []string, query *model.FeedQuery) ([]*model.FeedItem, error) {
+	if len(authorIDs) == 0 {
+		return nil, nil
+	}
+	return r.getPosts(authorIDs, blogAuthorKeys(authorIDs), postTypes, query)
+}
+
+// GetFollowingPosts 按发布时间倒序直接从作品表读取userID关注的所有人的postTypes类型的作品
+func (r *feedRepository) GetFollowingPosts(userID uint, postTypes []string, query *model.FeedQuery) ([]*model.FeedItem, error) {
+	following := r.db.Model(&model.UserFollow{}).Select("following_id").Where("user_id = ?", userID)
+	followingKeys := r.db.Model(&model.UserFollow{}).Select("CAST(following_id AS CHAR)").Where("user_id = ?", userID)
+	return r.getPosts(following, followingKeys, postTypes, query)
+}
+
+// getPosts 读取authorIDs发布的postTypes类型的作品，合并后按发布时间倒序
+// authorIDs为用户ID列表或子查询，authorKeys为对应的字符串形式，用于匹配未补齐user_id的历史博客
+func (r *feedRepository) getPosts(authorIDs, authorKeys interface{}, postTypes []string, query *model.FeedQuery) ([]*model.FeedItem, error) {
+	wanted := make(map[string]bool, len(postTypes))
+	for _, postType := range postTypes {
+		wanted[postType] = true
+	}
+
+	sources := []struct {
+		postType string
+		db       *gorm.DB
+	}{
+		{model.PostTypeSlide, r.db.Model(&model.SlideItem{}).Select("item_id AS post_id, user_id AS author_id, created_at AS published_at").
+			Where("user_id IN (?)", authorIDs)},
+		{model.PostTypeContent, r.db.Model(&model.Content{}).Select("id AS post_id, user_id AS author_id, created_at AS published_at").
+			Where("user_id IN (?) AND visibility <> ?", authorIDs, model.VisibilityPrivate)},
+		{model.PostTypeBlog, r.db.Model(&model.Blog{}).Select("CAST(id AS CHAR) AS post_id, "+blogPublisherColumn+" AS author_id, created_at AS published_at").
+			Where(blogAuthorCondition, authorIDs, authorKeys)},
+	}
+
+	var posts []*model.FeedItem
+	for _, source := range sources {
+		if !wanted[source.postType] {
+			continue
+		}
+		var items []*model.FeedItem
+		if err := source.db.
+			Scopes(feedWindow("created_at", query)).
+			Scan(&items).Error; err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			item.PostType = source.postType
+		}
+		posts = append(posts, items...)
+	}
+
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].PublishedAt.After(posts[j].PublishedAt)
+	})
+	if query.At.IsZero() && len(posts) > query.Limit {
+		posts = posts[:query.Limit]
+	}
+	return posts, nil
+}
+
+// feedWindow 按时间线查询条件筛选发布时间
+func feedWindow(column string, query *model.FeedQuery) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !query.At.IsZero() {
+			return db.Where(column+" = ?", query.At)
+		}
+		if !query.Before.IsZero() {
+			db = db.Where(column+" < ?", query.Before)
+		}
+		return db.Order(column + " DESC").Limit(query.Limit)
+	}
+}
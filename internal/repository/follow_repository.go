@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"ticktok-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FollowRepository 关注关系数据仓库接口
+type FollowRepository interface {
+	Follow(userID, followingID uint) error
+	Unfollow(userID, followingID uint) error
+	IsFollowing(userID, followingID uint) (bool, error)
+	GetFollowingSet(userID uint, targetIDs []uint) (map[uint]bool, error)
+	GetFollowerSet(userID uint, sourceIDs []uint) (map[uint]bool, error)
+	GetFollowers(userID uint, page, pageSize int) ([]*model.UserFollow, int64, error)
+	GetFollowing(userID uint, page, pageSize int) ([]*model.UserFollow, int64, error)
+	GetFollowerIDs(userID, afterID uint, limit int) ([]uint, error)
+	GetBigFollowingIDs(userID uint, minFollowers int64) ([]uint, error)
+	GetFollowsAfter(afterID uint, limit int) ([]*model.UserFollow, error)
+}
+
+// followRepository 关注关系数据仓库实现
+type followRepository struct {
+	db *gorm.DB
+}
+
+// NewFollowRepository 创建关注关系数据仓库
+func NewFollowRepository(db *gorm.DB) FollowRepository {
+	return &followRepository{
+		db: db,
+	}
+}
+
+// Follow 关注用户，已关注时不做任何操作，新增关注时同步更新双方的关注数和粉丝数
+func (r *followRepository) Follow(userID, followingID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		follow := model.UserFollow{
+			UserID:      userID,
+			FollowingID: followingID,
+			CreatedAt:   time.Now(),
+		}
+		result := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(&follow)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).
+			UpdateColumn("following_count", gorm.Expr("following_count + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.User{}).Where("id = ?", followingID).
+			UpdateColumn("follower_count", gorm.Expr("follower_count + 1")).Error
+	})
+}
+
+// Unfollow 取消关注，未关注时不做任何操作
+func (r *followRepository) Unfollow(userID, followingID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return removeFollow(tx, userID, followingID)
+	})
+}
+
+// IsFollowing 判断userID是否关注了followingID
+func (r *followRepository) IsFollowing(userID, followingID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.UserFollow{}).
+		Where("user_id = ? AND following_id = ?", userID, followingID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetFollowingSet 获取targetIDs中被userID关注的用户
+func (r *followRepository) GetFollowingSet(userID uint, targetIDs []uint) (map[uint]bool, error) {
+	following := make(map[uint]bool)
+	if len(targetIDs) == 0 {
+		return following, nil
+	}
+
+	var ids []uint
+	if err := r.db.Model(&model.UserFollow{}).
+		Where("user_id = ? AND following_id IN ?", userID, targetIDs).
+		Pluck("following_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		following[id] = true
+	}
+	return following, nil
+}
+
+// GetFollowerSet 获取sourceIDs中关注了userID的用户
+func (r *followRepository) GetFollowerSet(userID uint, sourceIDs []uint) (map[uint]bool, error) {
+	followers := make(map[uint]bool)
+	if len(sourceIDs) == 0 {
+		return followers, nil
+	}
+
+	var ids []uint
+	if err := r.db.Model(&model.UserFollow{}).
+		Where("following_id = ? AND user_id IN ?", userID, sourceIDs).
+		Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		followers[id] = true
+	}
+	return followers, nil
+}
+
+// GetFollowers 分页获取用户的粉丝，按关注时间倒序
+func (r *followRepository) GetFollowers(userID uint, page, pageSize int) ([]*model.UserFollow, int64, error) {
+	return r.findFollows("following_id", userID, "Follower", page, pageSize)
+}
+
+// GetFollowing 分页获取用户关注的人，按关注时间倒序
+func (r *followRepository) GetFollowing(userID uint, page, pageSize int) ([]*model.UserFollow, int64, error) {
+	return r.findFollows("user_id", userID, "Following", page, pageSize)
+}
+
+// findFollows 按关注者或被关注者分页查询关注关系，并加载列表中展示的一方
+func (r *followRepository) findFollows(column string, userID uint, preload string, page, pageSize int) ([]*model.UserFollow, int64, error) {
+	var total int64
+	if err := r.db.Model(&model.UserFollow{}).Where(column+" = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var follows []*model.UserFollow
+	if err := r.db.Preload(preload).
+		Where(column+" = ?", userID).
+		Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&follows).Error; err != nil {
+		return nil, 0, err
+	}
+	return follows, total, nil
+}
+
+// GetFollowerIDs 按用户ID顺序分批获取粉丝ID，afterID为上一批的最后一个ID
+func (r *followRepository) GetFollowerIDs(userID, afterID uint, limit int) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.UserFollow{}).
+		Where("following_id = ? AND user_id > ?", userID, afterID).
+		Order("user_id ASC").
+		Limit(limit).
+		Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetBigFollowingIDs 获取用户关注的粉丝数不少于minFollowers的账号
+func (r *followRepository) GetBigFollowingIDs(userID uint, minFollowers int64) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.UserFollow{}).
+		Joins("JOIN users ON users.id = user_follows.following_id").
+		Where("user_follows.user_id = ? AND users.follower_count >= ?", userID, minFollowers).
+		Pluck("user_follows.following_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetFollowsAfter 按ID顺序分批获取全部关注关系，afterID为上一批的最后一个ID
+func (r *followRepository) GetFollowsAfter(afterID uint, limit int) ([]*model.UserFollow, error) {
+	var follows []*model.UserFollow
+	if err := r.db.Preload("Following").
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&follows).Error; err != nil {
+		return nil, err
+	}
+	return follows, nil
+}
+
+// removeFollow 删除关注关系，删除成功时同步扣减双方的关注数和粉丝数，并清除收件箱中对方的作品
+func removeFollow(tx *gorm.DB, userID, followingID uint) error {
+	result := tx.Where("user_id = ? AND following_id = ?", userID, followingID).Delete(&model.UserFollow{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+
+	if err := tx.Where("user_id = ? AND author_id = ?", userID, followingID).Delete(&model.FeedItem{}).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&model.User{}).Where("id = ? AND following_count > 0", userID).
+		UpdateColumn("following_count", gorm.Expr("following_count - 1")).Error; err != nil {
+		return err
+	}
+	return tx.Model(&model.User{}).Where("id = ? AND follower_count > 0", followingID).
+		UpdateColumn("follower_count", gorm.Expr("follower_count - 1")).Error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"ticktok-service/internal/model"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -9,6 +10,17 @@ import (
 // FriendRepository 好友数据仓库接口
 type FriendRepository interface {
 	GetFriendsByUserID(userID uint) ([]*model.Friendship, error)
+	IsFriend(userID, friendID uint) (bool, error)
+	GetFriendship(userID, friendID uint) (*model.Friendship, error)
+	GetFriendTypes(userID uint, friendIDs []uint) (map[uint]string, error)
+	DeleteFriendship(userID, friendID uint) error
+	CreateFriendRequest(request *model.FriendRequest) error
+	GetFriendRequestByID(id uint) (*model.FriendRequest, error)
+	GetPendingRequest(fromUserID, toUserID uint) (*model.FriendRequest, error)
+	GetReceivedRequests(userID uint) ([]*model.FriendRequest, error)
+	GetSentRequests(userID uint) ([]*model.FriendRequest, error)
+	UpdateFriendRequestStatus(id uint, status string) error
+	AcceptFriendRequest(request *model.FriendRequest) error
 }
 
 // friendRepository 好友数据仓库实现
@@ -30,4 +42,149 @@ func (r *friendRepository) GetFriendsByUserID(userID uint) ([]*model.Friendship,
 		return nil, err
 	}
 	return friendships, nil
-} 
\ No newline at end of file
+}
+
+// IsFriend 判断friendID是否在userID的好友列表中
+func (r *friendRepository) IsFriend(userID, friendID uint) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.Friendship{}).
+		Where("user_id = ? AND friend_id = ?", userID, friendID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetFriendship 获取userID一侧与friendID的好友关系
+func (r *friendRepository) GetFriendship(userID, friendID uint) (*model.Friendship, error) {
+	var friendship model.Friendship
+	if err := r.db.Where("user_id = ? AND friend_id = ?", userID, friendID).First(&friendship).Error; err != nil {
+		return nil, err
+	}
+	return &friendship, nil
+}
+
+// GetFriendTypes 批量获取userID一侧与friendIDs的好友类型，键为好友ID，不是好友的不在结果中
+func (r *friendRepository) GetFriendTypes(userID uint, friendIDs []uint) (map[uint]string, error) {
+	types := make(map[uint]string, len(friendIDs))
+	if len(friendIDs) == 0 {
+		return types, nil
+	}
+
+	var friendships []*model.Friendship
+	if err := r.db.Select("friend_id", "friend_type").
+		Where("user_id = ? AND friend_id IN ?", userID, friendIDs).
+		Find(&friendships).Error; err != nil {
+		return nil, err
+	}
+	for _, friendship := range friendships {
+		types[friendship.FriendID] = friendship.FriendType
+	}
+	return types, nil
+}
+
+// DeleteFriendship 删除双方的好友关系
+func (r *friendRepository) DeleteFriendship(userID, friendID uint) error {
+	return r.db.Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
+		userID, friendID, friendID, userID).
+		Delete(&model.Friendship{}).Error
+}
+
+// CreateFriendRequest 创建好友申请
+func (r *friendRepository) CreateFriendRequest(request *model.FriendRequest) error {
+	return r.db.Create(request).Error
+}
+
+// GetFriendRequestByID 根据ID获取好友申请
+func (r *friendRepository) GetFriendRequestByID(id uint) (*model.FriendRequest, error) {
+	var request model.FriendRequest
+	if err := r.db.Preload("FromUser").Preload("ToUser").First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetPendingRequest 获取fromUserID发给toUserID的待处理申请
+func (r *friendRepository) GetPendingRequest(fromUserID, toUserID uint) (*model.FriendRequest, error) {
+	var request model.FriendRequest
+	if err := r.db.Preload("FromUser").Preload("ToUser").
+		Where("from_user_id = ? AND to_user_id = ? AND status = ?", fromUserID, toUserID, model.FriendRequestPending).
+		First(&request).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetReceivedRequests 获取用户收到的待处理申请
+func (r *friendRepository) GetReceivedRequests(userID uint) ([]*model.FriendRequest, error) {
+	var requests []*model.FriendRequest
+	if err := r.db.Preload("FromUser").Preload("ToUser").
+		Where("to_user_id = ? AND status = ?", userID, model.FriendRequestPending).
+		Order("created_at DESC").
+		Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// GetSentRequests 获取用户发出的待处理申请
+func (r *friendRepository) GetSentRequests(userID uint) ([]*model.FriendRequest, error) {
+	var requests []*model.FriendRequest
+	if err := r.db.Preload("FromUser").Preload("ToUser").
+		Where("from_user_id = ? AND status = ?", userID, model.FriendRequestPending).
+		Order("created_at DESC").
+		Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// UpdateFriendRequestStatus 将待处理的好友申请改为指定状态，申请已被处理时返回gorm.ErrRecordNotFound
+func (r *friendRepository) UpdateFriendRequestStatus(id uint, status string) error {
+	return settlePendingRequest(r.db, id, status)
+}
+
+// AcceptFriendRequest 通过好友申请，并为双方各创建一条好友关系
+// 申请已被撤销、拒绝或通过时返回gorm.ErrRecordNotFound，不创建好友关系
+func (r *friendRepository) AcceptFriendRequest(request *model.FriendRequest) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := settlePendingRequest(tx, request.ID, model.FriendRequestAccepted); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		pairs := [][2]uint{
+			{request.FromUserID, request.ToUserID},
+			{request.ToUserID, request.FromUserID},
+		}
+		for _, pair := range pairs {
+			friendship := model.Friendship{
+				UserID:     pair[0],
+				FriendID:   pair[1],
+				FriendType: "normal",
+				CreatedAt:  now,
+			}
+			// 已存在的关系（如单向的历史数据）保持不变
+			if err := tx.Where("user_id = ? AND friend_id = ?", pair[0], pair[1]).
+				FirstOrCreate(&friendship).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// settlePendingRequest 只在申请仍待处理时修改状态，并发的通过、拒绝和撤销只有一个生效
+func settlePendingRequest(db *gorm.DB, id uint, status string) error {
+	result := db.Model(&model.FriendRequest{}).
+		Where("id = ? AND status = ?", id, model.FriendRequestPending).
+		Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+package repository
+
+import (
+	"ticktok-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GroupRepository 群聊数据仓库接口
+type GroupRepository interface {
+	CreateGroup(group *model.ChatGroup, memberIDs []uint) error
+	GetGroupByID(groupID uint) (*model.ChatGroup, error)
+	GetGroupsByUserID(userID uint) ([]*model.ChatGroup, error)
+	GetMember(groupID, userID uint) (*model.GroupMember, error)
+	GetMembers(groupID uint) ([]*model.GroupMember, error)
+	GetMemberIDs(groupID uint) ([]uint, error)
+	CountMembers(groupID uint) (int64, error)
+	AddMembers(groupID uint, userIDs []uint) error
+	RemoveMember(groupID, userID uint) error
+	LeaveGroup(groupID, userID uint) error
+	UpdateMemberRole(groupID, userID uint, role string) error
+	CreateGroupMessage(message *model.Message) error
+	GetGroupChatHistory(groupID uint, query *model.ChatHistoryQuery) ([]*model.Message, error)
+	GetMessagesByIDs(ids []uint) ([]*model.Message, error)
+	MarkGroupAsRead(userID, groupID uint) error
+	GetUnreadCounts(userID uint) (map[uint]int, error)
+}
+
+// groupRepository 群聊数据仓库实现
+type groupRepository struct {
+	db *gorm.DB
+}
+
+// NewGroupRepository 创建群聊数据仓库
+func NewGroupRepository(db *gorm.DB) GroupRepository {
+	return &groupRepository{
+		db: db,
+	}
+}
+
+// CreateGroup 创建群聊，创建者成为群主，memberIDs为其余初始成员
+func (r *groupRepository) CreateGroup(group *model.ChatGroup, memberIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		group.CreatedAt = now
+		group.UpdatedAt = now
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+
+		members := []*model.GroupMember{{
+			GroupID:  group.ID,
+			UserID:   group.OwnerID,
+			Role:     model.GroupRoleOwner,
+			JoinedAt: now,
+		}}
+		if err := tx.Create(&members).Error; err != nil {
+			return err
+		}
+		if err := createUnreadCounters(tx, group.ID, group.OwnerID, []uint{group.OwnerID}); err != nil {
+			return err
+		}
+
+		return addMembers(tx, group.ID, group.OwnerID, memberIDs)
+	})
+}
+
+// GetGroupByID 根据ID获取群聊
+func (r *groupRepository) GetGroupByID(groupID uint) (*model.ChatGroup, error) {
+	var group model.ChatGroup
+	if err := r.db.Where("id = ?", groupID).First(&group).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// GetGroupsByUserID 获取用户加入的所有群聊
+func (r *groupRepository) GetGroupsByUserID(userID uint) ([]*model.ChatGroup, error) {
+	var groups []*model.ChatGroup
+	if err := r.db.Where("id IN (?)",
+		r.db.Model(&model.GroupMember{}).Select("group_id").Where("user_id = ?", userID),
+	).Order("updated_at DESC").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// GetMember 获取群成员
+func (r *groupRepository) GetMember(groupID, userID uint) (*model.GroupMember, error) {
+	var member model.GroupMember
+	if err := r.db.Where("group_id = ? AND user_id = ?", groupID, userID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetMembers 获取群成员列表，按加入时间排序
+func (r *groupRepository) GetMembers(groupID uint) ([]*model.GroupMember, error) {
+	var members []*model.GroupMember
+	if err := r.db.Preload("User").
+		Where("group_id = ?", groupID).
+		Order("joined_at ASC, id ASC").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// GetMemberIDs 获取群成员ID列表
+func (r *groupRepository) GetMemberIDs(groupID uint) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&model.GroupMember{}).
+		Where("group_id = ?", groupID).
+		Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// CountMembers 统计群成员数量
+func (r *groupRepository) CountMembers(groupID uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.GroupMember{}).Where("group_id = ?", groupID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// AddMembers 添加群成员，已在群内的用户会被忽略
+func (r *groupRepository) AddMembers(groupID uint, userIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var group model.ChatGroup
+		if err := tx.Select("owner_id").Where("id = ?", groupID).First(&group).Error; err != nil {
+			return err
+		}
+		return addMembers(tx, groupID, group.OwnerID, userIDs)
+	})
+}
+
+// RemoveMember 移除群成员及其未读计数
+func (r *groupRepository) RemoveMember(groupID, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return removeMember(tx, groupID, userID)
+	})
+}
+
+// LeaveGroup 在同一事务中退出群聊，群主退出时同时转让群主或解散群聊
+// 不是群成员时返回gorm.ErrRecordNotFound
+func (r *groupRepository) LeaveGroup(groupID, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return leaveGroup(tx, groupID, userID)
+	})
+}
+
+// UpdateMemberRole 设置成员角色
+func (r *groupRepository) UpdateMemberRole(groupID, userID uint, role string) error {
+	return r.db.Model(&model.GroupMember{}).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Update("role", role).Error
+}
+
+// CreateGroupMessage 创建群消息，并为除发送者外的所有成员增加未读计数
+func (r *groupRepository) CreateGroupMessage(message *model.Message) error {
+	message.CreatedAt = time.Now()
+	message.SessionID = model.GroupSessionID(message.GroupID)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// 插入消息
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+
+		// 更新群聊最后消息
+		if err := tx.Model(&model.ChatGroup{}).
+			Where("id = ?", message.GroupID).
+			Updates(map[string]interface{}{
+				"last_message_id": message.ID,
+				"updated_at":      time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+
+		// 成员入群时已创建未读记录，这里一次性扇出到所有成员，开启免打扰的成员不增加未读数
+		mutedUsers := tx.Model(&model.ConversationMute{}).
+			Select("user_id").
+			Where("group_id = ?", message.GroupID)
+		return tx.Model(&model.UnreadMessage{}).
+			Where("group_id = ? AND user_id <> ?", message.GroupID, message.SenderID).
+			Updates(map[string]interface{}{
+				"count":      gorm.Expr("CASE WHEN user_id IN (?) THEN count ELSE count + 1 END", mutedUsers),
+				"sender_id":  message.SenderID,
+				"updated_at": time.Now(),
+			}).Error
+	})
+}
+
+// GetGroupChatHistory 按消息ID游标获取群聊历史记录，规则同MessageRepository.GetChatHistory
+func (r *groupRepository) GetGroupChatHistory(groupID uint, query *model.ChatHistoryQuery) ([]*model.Message, error) {
+	return findMessagesByCursor(r.db.Where("session_id = ?", model.GroupSessionID(groupID)), query)
+}
+
+// GetMessagesByIDs 批量获取消息
+func (r *groupRepository) GetMessagesByIDs(ids []uint) ([]*model.Message, error) {
+	var messages []*model.Message
+	if len(ids) == 0 {
+		return messages, nil
+	}
+	if err := r.db.Preload("Sender").Where("id IN ?", ids).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// MarkGroupAsRead 清零用户在群内的未读计数，记录保留用于后续扇出
+func (r *groupRepository) MarkGroupAsRead(userID, groupID uint) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.UnreadMessage{}).
+			Where("user_id = ? AND group_id = ?", userID, groupID).
+			Updates(map[string]interface{}{
+				"count":      0,
+				"updated_at": now,
+			}).Error; err != nil {
+			return err
+		}
+
+		// 阅读游标推进到群内最新一条消息
+		sessionID := model.GroupSessionID(groupID)
+		lastID, err := lastSessionMessageID(tx, sessionID, 0)
+		if err != nil || lastID == 0 {
+			return err
+		}
+		return advanceReadCursor(tx, sessionID, userID, lastID, true, now)
+	})
+}
+
+// GetUnreadCounts 获取用户在各群的未读数量，键为群ID
+func (r *groupRepository) GetUnreadCounts(userID uint) (map[uint]int, error) {
+	var rows []*model.UnreadMessage
+	if err := r.db.Select("group_id", "count").
+		Where("user_id = ? AND group_id <> 0", userID).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.GroupID] = row.Count
+	}
+	return counts, nil
+}
+
+// addMembers 在事务中添加成员并创建对应的群未读记录
+func addMembers(tx *gorm.DB, groupID, ownerID uint, userIDs []uint) error {
+	var existing []uint
+	if err := tx.Model(&model.GroupMember{}).
+		Where("group_id = ?", groupID).
+		Pluck("user_id", &existing).Error; err != nil {
+		return err
+	}
+	seen := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		seen[id] = true
+	}
+
+	now := time.Now()
+	var members []*model.GroupMember
+	var newIDs []uint
+	for _, id := range userIDs {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		members = append(members, &model.GroupMember{
+			GroupID:  groupID,
+			UserID:   id,
+			Role:     model.GroupRoleMember,
+			JoinedAt: now,
+		})
+		newIDs = append(newIDs, id)
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&members).Error; err != nil {
+		return err
+	}
+	return createUnreadCounters(tx, groupID, ownerID, newIDs)
+}
+
+// createUnreadCounters 为新成员创建计数为0的群未读记录
+func createUnreadCounters(tx *gorm.DB, groupID, senderID uint, userIDs []uint) error {
+	now := time.Now()
+	counters := make([]*model.UnreadMessage, 0, len(userIDs))
+	for _, id := range userIDs {
+		counters = append(counters, &model.UnreadMessage{
+			UserID:    id,
+			SenderID:  senderID,
+			GroupID:   groupID,
+			Count:     0,
+			UpdatedAt: now,
+		})
+	}
+	return tx.Create(&counters).Error
+}
+
+// leaveGroup 在事务中退出群聊，先锁定群聊以免并发退出时群主转让冲突
+// 群主退出时将群主转让给最早加入的管理员，没有管理员则转给最早加入的成员；最后一人退出时解散群聊
+func leaveGroup(tx *gorm.DB, groupID, userID uint) error {
+	var group model.ChatGroup
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", groupID).
+		First(&group).Error; err != nil {
+		return err
+	}
+
+	var members []*model.GroupMember
+	if err := tx.Where("group_id = ?", groupID).
+		Order("joined_at ASC, id ASC").
+		Find(&members).Error; err != nil {
+		return err
+	}
+
+	var self, successor *model.GroupMember
+	for _, m := range members {
+		if m.UserID == userID {
+			self = m
+			continue
+		}
+		if m.Role == model.GroupRoleAdmin && (successor == nil || successor.Role != model.GroupRoleAdmin) {
+			successor = m
+		}
+		if successor == nil {
+			successor = m
+		}
+	}
+	if self == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	if self.Role == model.GroupRoleOwner {
+		if successor == nil {
+			return deleteGroup(tx, groupID)
+		}
+		if err := tx.Model(&model.GroupMember{}).
+			Where("group_id = ? AND user_id = ?", groupID, successor.UserID).
+			Update("role", model.GroupRoleOwner).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.ChatGroup{}).
+			Where("id = ?", groupID).
+			Update("owner_id", successor.UserID).Error; err != nil {
+			return err
+		}
+	}
+
+	return removeMember(tx, groupID, userID)
+}
+
+// removeMember 在事务中移除群成员及其未读计数
+func removeMember(tx *gorm.DB, groupID, userID uint) error {
+	if err := tx.Where("group_id = ? AND user_id = ?", groupID, userID).
+		Delete(&model.UnreadMessage{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("group_id = ? AND user_id = ?", groupID, userID).
+		Delete(&model.GroupMember{}).Error
+}
+
+// deleteGroup 在事务中解散群聊，删除成员和未读计数，群消息保留
+func deleteGroup(tx *gorm.DB, groupID uint) error {
+	if err := tx.Where("group_id = ?", groupID).Delete(&model.UnreadMessage{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("group_id = ?", groupID).Delete(&model.GroupMember{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&model.ChatGroup{}, groupID).Error
+}
@@ -1,20 +1,34 @@
 package repository
 
 import (
-	"fmt"
 	"ticktok-service/internal/model"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // MessageRepository 消息数据仓库接口
 type MessageRepository interface {
 	GetLastMessages(userID uint) ([]*model.Message, error)
-	GetChatHistory(userID, friendID uint) ([]*model.Message, error)
+	GetChatHistory(userID, friendID uint, query *model.ChatHistoryQuery) ([]*model.Message, error)
 	CreateMessage(message *model.Message) error
-	MarkMessagesAsRead(userID, friendID uint) error
-	GetUnreadCount(userID, senderID uint) (int, error)
+	MarkMessagesAsRead(userID, friendID, lastMessageID uint) (uint, error)
+	MarkMessagesAsDelivered(userID, friendID, lastMessageID uint) (uint, error)
+	GetReadCursors(sessionID string) ([]*model.ReadCursor, error)
+	GetUnreadCounts(userID uint) (map[uint]int, error)
+	MergeDuplicateSessions() (int, error)
+	GetMessageByID(id uint) (*model.Message, error)
+	RecallMessage(id uint) error
+	EditMessage(id uint, content string) error
+	GetMessageEdits(id uint) ([]*model.MessageEdit, error)
+	SearchMessages(userID uint, match string, query *model.MessageSearchQuery) ([]*model.Message, error)
+	GetContextMessages(sessionID string, messageID, since uint, n int) ([]*model.Message, []*model.Message, error)
+	AddReaction(reaction *model.MessageReaction) error
+	RemoveReaction(messageID, userID uint, emoji string) error
+	GetReactions(messageIDs []uint) ([]*model.MessageReaction, error)
+	GetSessionMessages(sessionID string, afterID uint, limit int) ([]*model.Message, error)
+	GetMediaFiles(urls []string) ([]*model.MediaFile, error)
 }
 
 // messageRepository 消息数据仓库实现
@@ -38,28 +52,55 @@ func (r *messageRepository) GetLastMessages(userID uint) ([]*model.Message, erro
 		INNER JOIN (
 			SELECT MAX(id) as id, session_id
 			FROM messages
-			WHERE sender_id = ? OR receiver_id = ?
+			WHERE (sender_id = ? OR receiver_id = ?) AND group_id = 0
 			GROUP BY session_id
 		) AS latest ON latest.id = m.id
 		ORDER BY m.timestamp DESC
 	`
-	if err := r.db.Raw(query, userID, userID).Preload("Sender").Find(&messages).Error; err != nil {
+	if err := r.db.Raw(query, userID, userID).Find(&messages).Error; err != nil {
 		return nil, err
 	}
 	return messages, nil
 }
 
-// GetChatHistory 获取聊天历史记录
-func (r *messageRepository) GetChatHistory(userID, friendID uint) ([]*model.Message, error) {
+// GetChatHistory 按消息ID游标获取聊天历史记录，结果按时间正序排列
+// 返回至多query.Limit+1条记录，多出的一条用于判断是否还有更多数据
+func (r *messageRepository) GetChatHistory(userID, friendID uint, query *model.ChatHistoryQuery) ([]*model.Message, error) {
+	return findMessagesByCursor(r.db.Where("session_id = ?", model.SessionIDFor(userID, friendID)), query)
+}
+
+// findMessagesByCursor 在db限定的消息范围内按游标查询，同时加载被引用的消息
+func findMessagesByCursor(db *gorm.DB, query *model.ChatHistoryQuery) ([]*model.Message, error) {
+	db = db.Preload("ReplyTo.Sender")
+	if query.Since > 0 {
+		db = db.Where("id > ?", query.Since)
+	}
 	var messages []*model.Message
-	sessionID1 := fmt.Sprintf("chat_%d_%d", userID, friendID)
-	sessionID2 := fmt.Sprintf("chat_%d_%d", friendID, userID)
+	if query.After > 0 {
+		// 加载更新的消息
+		if err := db.Where("id > ?", query.After).
+			Order("id ASC").
+			Limit(query.Limit + 1).
+			Find(&messages).Error; err != nil {
+			return nil, err
+		}
+		return messages, nil
+	}
 	
-	if err := r.db.Where("(session_id = ? OR session_id = ?)", sessionID1, sessionID2).
-		Order("timestamp ASC").
+	// 加载更早的消息，默认从最新一条开始
+	if query.Before > 0 {
+		db = db.Where("id < ?", query.Before)
+	}
+	if err := db.Order("id DESC").
+		Limit(query.Limit + 1).
 		Find(&messages).Error; err != nil {
 		return nil, err
 	}
+	
+	// 倒序查询的结果翻转为正序
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
 	return messages, nil
 }
 
@@ -68,7 +109,7 @@ func (r *messageRepository) CreateMessage(message *model.Message) error {
 	message.CreatedAt = time.Now()
 	// 如果未设置会话ID，则自动生成
 	if message.SessionID == "" {
-		message.SessionID = fmt.Sprintf("chat_%d_%d", message.SenderID, message.ReceiverID)
+		message.SessionID = model.SessionIDFor(message.SenderID, message.ReceiverID)
 	}
 	
 	tx := r.db.Begin()
@@ -84,10 +125,14 @@ func (r *messageRepository) CreateMessage(message *model.Message) error {
 	var session model.Session
 	if err := tx.Where("id = ?", sessionID).First(&session).Error; err != nil {
 		// 会话不存在，创建新会话
+		user1ID, user2ID := message.SenderID, message.ReceiverID
+		if user1ID > user2ID {
+			user1ID, user2ID = user2ID, user1ID
+		}
 		session = model.Session{
 			ID:            sessionID,
-			User1ID:       message.SenderID,
-			User2ID:       message.ReceiverID,
+			User1ID:       user1ID,
+			User2ID:       user2ID,
 			LastMessageID: message.ID,
 			UpdatedAt:     time.Now(),
 		}
@@ -106,9 +151,21 @@ func (r *messageRepository) CreateMessage(message *model.Message) error {
 		}
 	}
 	
+	// 接收者开启免打扰时只保存消息，不增加未读数
+	var muted int64
+	if err := tx.Model(&model.ConversationMute{}).
+		Where("user_id = ? AND peer_id = ? AND group_id = 0", message.ReceiverID, message.SenderID).
+		Count(&muted).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if muted > 0 {
+		return tx.Commit().Error
+	}
+	
 	// 更新未读消息计数
 	var unreadMessage model.UnreadMessage
-	result := tx.Where("user_id = ? AND sender_id = ?", message.ReceiverID, message.SenderID).First(&unreadMessage)
+	result := tx.Where("user_id = ? AND sender_id = ? AND group_id = 0", message.ReceiverID, message.SenderID).First(&unreadMessage)
 	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
 		tx.Rollback()
 		return result.Error
@@ -140,41 +197,406 @@ func (r *messageRepository) CreateMessage(message *model.Message) error {
 	return tx.Commit().Error
 }
 
-// MarkMessagesAsRead 标记消息为已读
-func (r *messageRepository) MarkMessagesAsRead(userID, friendID uint) error {
-	tx := r.db.Begin()
+// MarkMessagesAsRead 将好友发来的、ID不超过lastMessageID的消息标记为已读，lastMessageID为0表示全部
+// 同时推进当前用户的阅读游标并重算未读数，返回游标推进到的消息ID，会话中没有消息时返回0
+func (r *messageRepository) MarkMessagesAsRead(userID, friendID, lastMessageID uint) (uint, error) {
+	sessionID := model.SessionIDFor(userID, friendID)
+	now := time.Now()
 	
-	// 更新消息状态
-	sessionID1 := fmt.Sprintf("chat_%d_%d", friendID, userID)
-	sessionID2 := fmt.Sprintf("chat_%d_%d", userID, friendID)
+	var lastID uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		lastID, err = lastSessionMessageID(tx, sessionID, lastMessageID)
+		if err != nil || lastID == 0 {
+			return err
+		}
+		
+		// 更新消息状态，没有送达时间的一并补上
+		if err := tx.Model(&model.Message{}).
+			Where("session_id = ? AND sender_id = ? AND id <= ? AND read_at IS NULL", sessionID, friendID, lastID).
+			Updates(map[string]interface{}{
+				"status":       "read",
+				"read_at":      now,
+				"delivered_at": gorm.Expr("COALESCE(delivered_at, ?)", now),
+			}).Error; err != nil {
+			return err
+		}
+		
+		if err := advanceReadCursor(tx, sessionID, userID, lastID, true, now); err != nil {
+			return err
+		}
+		
+		// 删除会话时清除的消息不计入未读
+		var clearedID uint
+		if err := tx.Model(&model.ConversationSetting{}).
+			Select("cleared_message_id").
+			Where("user_id = ? AND session_id = ?", userID, sessionID).
+			Limit(1).
+			Scan(&clearedID).Error; err != nil {
+			return err
+		}
+		
+		// 未读数改为游标之后仍未读的消息数，只减不增：免打扰期间收到的消息本就不计入未读
+		return tx.Model(&model.UnreadMessage{}).
+			Where("user_id = ? AND sender_id = ? AND group_id = 0", userID, friendID).
+			Updates(map[string]interface{}{
+				"count": gorm.Expr("LEAST(count, (?))", tx.Model(&model.Message{}).
+					Select("COUNT(*)").
+					Where("session_id = ? AND sender_id = ? AND id > ? AND read_at IS NULL", sessionID, friendID, clearedID)),
+				"updated_at": now,
+			}).Error
+	})
+	return lastID, err
+}
+
+// MarkMessagesAsDelivered 将好友发来的、ID不超过lastMessageID的消息标记为已送达，lastMessageID为0表示全部
+// 已读的消息状态保持不变，返回送达游标推进到的消息ID
+func (r *messageRepository) MarkMessagesAsDelivered(userID, friendID, lastMessageID uint) (uint, error) {
+	sessionID := model.SessionIDFor(userID, friendID)
+	now := time.Now()
 	
-	if err := tx.Model(&model.Message{}).
-		Where("(session_id = ? OR session_id = ?) AND sender_id = ? AND status <> ?", 
-			sessionID1, sessionID2, friendID, "read").
-		Update("status", "read").Error; err != nil {
-		tx.Rollback()
-		return err
+	var lastID uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		lastID, err = lastSessionMessageID(tx, sessionID, lastMessageID)
+		if err != nil || lastID == 0 {
+			return err
+		}
+		
+		if err := tx.Model(&model.Message{}).
+			Where("session_id = ? AND sender_id = ? AND id <= ? AND delivered_at IS NULL", sessionID, friendID, lastID).
+			Updates(map[string]interface{}{
+				"status":       gorm.Expr("IF(status = ?, ?, status)", "sent", "delivered"),
+				"delivered_at": now,
+			}).Error; err != nil {
+			return err
+		}
+		
+		return advanceReadCursor(tx, sessionID, userID, lastID, false, now)
+	})
+	return lastID, err
+}
+
+// GetReadCursors 获取会话中所有参与者的阅读游标
+func (r *messageRepository) GetReadCursors(sessionID string) ([]*model.ReadCursor, error) {
+	var cursors []*model.ReadCursor
+	if err := r.db.Where("session_id = ?", sessionID).Find(&cursors).Error; err != nil {
+		return nil, err
+	}
+	return cursors, nil
+}
+
+// lastSessionMessageID 获取会话中不超过upTo的最大消息ID，upTo为0时取最新一条
+func lastSessionMessageID(db *gorm.DB, sessionID string, upTo uint) (uint, error) {
+	query := db.Model(&model.Message{}).
+		Select("COALESCE(MAX(id), 0)").
+		Where("session_id = ?", sessionID)
+	if upTo > 0 {
+		query = query.Where("id <= ?", upTo)
 	}
 	
-	// 重置未读计数
-	if err := tx.Where("user_id = ? AND sender_id = ?", userID, friendID).
-		Delete(&model.UnreadMessage{}).Error; err != nil {
-		tx.Rollback()
-		return err
+	var lastID uint
+	if err := query.Scan(&lastID).Error; err != nil {
+		return 0, err
+	}
+	return lastID, nil
+}
+
+// advanceReadCursor 推进用户在会话中的送达游标，read为true时同时推进已读游标
+// 游标只前进不后退，时间只在游标前进时更新
+func advanceReadCursor(db *gorm.DB, sessionID string, userID, messageID uint, read bool, now time.Time) error {
+	cursor := model.ReadCursor{
+		SessionID:       sessionID,
+		UserID:          userID,
+		LastDeliveredID: messageID,
+		DeliveredAt:     &now,
+	}
+	// MySQL按从左到右的顺序赋值，时间必须在游标之前更新才能与旧游标比较
+	set := clause.Set{
+		{Column: clause.Column{Name: "delivered_at"}, Value: gorm.Expr("IF(? > last_delivered_id, ?, delivered_at)", messageID, now)},
+		{Column: clause.Column{Name: "last_delivered_id"}, Value: gorm.Expr("GREATEST(last_delivered_id, ?)", messageID)},
+	}
+	if read {
+		cursor.LastReadID = messageID
+		cursor.ReadAt = &now
+		set = append(set,
+			clause.Assignment{Column: clause.Column{Name: "read_at"}, Value: gorm.Expr("IF(? > last_read_id, ?, read_at)", messageID, now)},
+			clause.Assignment{Column: clause.Column{Name: "last_read_id"}, Value: gorm.Expr("GREATEST(last_read_id, ?)", messageID)},
+		)
 	}
 	
-	return tx.Commit().Error
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "session_id"}, {Name: "user_id"}},
+		DoUpdates: set,
+	}).Create(&cursor).Error
 }
 
-// GetUnreadCount 获取未读消息数量
-func (r *messageRepository) GetUnreadCount(userID, senderID uint) (int, error) {
-	var unreadMessage model.UnreadMessage
-	result := r.db.Select("count").Where("user_id = ? AND sender_id = ?", userID, senderID).First(&unreadMessage)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return 0, nil
+// GetUnreadCounts 获取用户各单聊会话的未读数量，键为对方用户ID
+func (r *messageRepository) GetUnreadCounts(userID uint) (map[uint]int, error) {
+	var rows []*model.UnreadMessage
+	if err := r.db.Select("sender_id", "count").
+		Where("user_id = ? AND group_id = 0 AND count > 0", userID).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.SenderID] += row.Count
+	}
+	return counts, nil
+}
+
+// MergeDuplicateSessions 将旧版按方向生成的会话合并为与方向无关的会话
+// 先把所有消息的session_id改写为规范ID，再按规范ID重建会话记录，返回被删除的旧会话数量
+func (r *messageRepository) MergeDuplicateSessions() (int, error) {
+	removed := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 改写消息的会话ID
+		if err := tx.Exec(`
+			UPDATE messages
+			SET session_id = CONCAT('chat_', LEAST(sender_id, receiver_id), '_', GREATEST(sender_id, receiver_id))
+			WHERE group_id = 0
+		`).Error; err != nil {
+			return err
+		}
+
+		var sessions []*model.Session
+		if err := tx.Order("updated_at DESC").Find(&sessions).Error; err != nil {
+			return err
+		}
+
+		// 按规范ID分组，保留每组最近更新的时间
+		canonical := make(map[string]*model.Session)
+		var obsolete []string
+		for _, session := range sessions {
+			id := model.SessionIDFor(session.User1ID, session.User2ID)
+			if session.ID != id {
+				obsolete = append(obsolete, session.ID)
+			}
+			if _, ok := canonical[id]; ok {
+				continue
+			}
+			user1ID, user2ID := session.User1ID, session.User2ID
+			if user1ID > user2ID {
+				user1ID, user2ID = user2ID, user1ID
+			}
+			canonical[id] = &model.Session{
+				ID:        id,
+				User1ID:   user1ID,
+				User2ID:   user2ID,
+				UpdatedAt: session.UpdatedAt,
+			}
+		}
+
+		if len(obsolete) > 0 {
+			if err := tx.Where("id IN ?", obsolete).Delete(&model.Session{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// 写入规范会话并指向该会话的最后一条消息
+		for id, session := range canonical {
+			var lastMessageID uint
+			if err := tx.Model(&model.Message{}).
+				Select("COALESCE(MAX(id), 0)").
+				Where("session_id = ?", id).
+				Scan(&lastMessageID).Error; err != nil {
+				return err
+			}
+			session.LastMessageID = lastMessageID
+			if err := tx.Omit(clause.Associations).Save(session).Error; err != nil {
+				return err
+			}
 		}
-		return 0, result.Error
+
+		removed = len(obsolete)
+		return nil
+	})
+	return removed, err
+}
+
+// GetMessageByID 根据ID获取消息
+func (r *messageRepository) GetMessageByID(id uint) (*model.Message, error) {
+	var message model.Message
+	if err := r.db.Where("id = ?", id).First(&message).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// RecallMessage 撤回消息，清空内容和附带的结构化数据并删除编辑历史
+func (r *messageRepository) RecallMessage(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Message{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"recalled": true,
+				"content":  "",
+				"caption":  "",
+				"payload":  "",
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Where("message_id = ?", id).Delete(&model.MessageEdit{}).Error
+	})
+}
+
+// EditMessage 编辑消息内容，编辑前的内容写入编辑历史
+// 锁定未撤回的消息后再修改，与撤回互斥，消息已撤回时返回gorm.ErrRecordNotFound
+func (r *messageRepository) EditMessage(id uint, content string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var message model.Message
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "content").
+			Where("id = ? AND recalled = ?", id, false).
+			First(&message).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&model.MessageEdit{
+			MessageID:  id,
+			OldContent: message.Content,
+			EditedAt:   time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&model.Message{}).
+			Where("id = ? AND recalled = ?", id, false).
+			Updates(map[string]interface{}{
+				"content": content,
+				"edited":  true,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// GetMessageEdits 获取消息的编辑历史，按编辑时间正序
+func (r *messageRepository) GetMessageEdits(id uint) ([]*model.MessageEdit, error) {
+	var edits []*model.MessageEdit
+	if err := r.db.Where("message_id = ?", id).Order("edited_at ASC, id ASC").Find(&edits).Error; err != nil {
+		return nil, err
+	}
+	return edits, nil
+}
+
+// SearchMessages 在用户参与的单聊和所在群聊中全文搜索消息，match为BOOLEAN MODE的检索式
+// 用户删除会话前的消息不参与搜索，按ID倒序返回，多返回一条用于判断是否还有更多
+func (r *messageRepository) SearchMessages(userID uint, match string, query *model.MessageSearchQuery) ([]*model.Message, error) {
+	db := r.db.Where("MATCH(content, caption) AGAINST(? IN BOOLEAN MODE)", match).
+		Where("recalled = ?", false).
+		Where("(group_id = 0 AND (sender_id = ? OR receiver_id = ?)) OR group_id IN (?)",
+			userID, userID,
+			r.db.Model(&model.GroupMember{}).Select("group_id").Where("user_id = ?", userID)).
+		Where("id > COALESCE((?), 0)",
+			r.db.Model(&model.ConversationSetting{}).
+				Select("cleared_message_id").
+				Where("user_id = ? AND session_id = messages.session_id", userID))
+
+	if query.FriendID > 0 {
+		db = db.Where("session_id = ?", model.SessionIDFor(userID, query.FriendID))
+	}
+	if query.GroupID > 0 {
+		db = db.Where("session_id = ?", model.GroupSessionID(query.GroupID))
+	}
+	if query.Type != "" {
+		db = db.Where("type = ?", query.Type)
+	}
+	if !query.Start.IsZero() {
+		db = db.Where("created_at >= ?", query.Start)
+	}
+	if !query.End.IsZero() {
+		db = db.Where("created_at <= ?", query.End)
+	}
+	if query.Before > 0 {
+		db = db.Where("id < ?", query.Before)
+	}
+
+	var messages []*model.Message
+	if err := db.Order("id DESC").Limit(query.Limit + 1).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// GetContextMessages 获取同一会话中紧邻messageID的前后各n条消息，均按时间正序，只返回ID大于since的消息
+func (r *messageRepository) GetContextMessages(sessionID string, messageID, since uint, n int) ([]*model.Message, []*model.Message, error) {
+	if n <= 0 {
+		return nil, nil, nil
+	}
+
+	var before, after []*model.Message
+	if err := r.db.Where("session_id = ? AND id < ? AND id > ?", sessionID, messageID, since).
+		Order("id DESC").
+		Limit(n).
+		Find(&before).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := r.db.Where("session_id = ? AND id > ?", sessionID, messageID).
+		Order("id ASC").
+		Limit(n).
+		Find(&after).Error; err != nil {
+		return nil, nil, err
+	}
+
+	// 倒序查询的结果翻转为正序
+	for i, j := 0, len(before)-1; i < j; i, j = i+1, j-1 {
+		before[i], before[j] = before[j], before[i]
+	}
+	return before, after, nil
+}
+
+// AddReaction 添加表情回应，重复添加时忽略
+func (r *messageRepository) AddReaction(reaction *model.MessageReaction) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction).Error
+}
+
+// RemoveReaction 取消表情回应
+func (r *messageRepository) RemoveReaction(messageID, userID uint, emoji string) error {
+	return r.db.Where("message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
+		Delete(&model.MessageReaction{}).Error
+}
+
+// GetReactions 批量获取消息的表情回应，按回应时间正序
+func (r *messageRepository) GetReactions(messageIDs []uint) ([]*model.MessageReaction, error) {
+	var reactions []*model.MessageReaction
+	if len(messageIDs) == 0 {
+		return reactions, nil
+	}
+	if err := r.db.Where("message_id IN ?", messageIDs).Order("id ASC").Find(&reactions).Error; err != nil {
+		return nil, err
+	}
+	return reactions, nil
+}
+
+// GetSessionMessages 按ID正序获取会话中afterID之后的一批消息，用于逐批导出
+func (r *messageRepository) GetSessionMessages(sessionID string, afterID uint, limit int) ([]*model.Message, error) {
+	var messages []*model.Message
+	if err := r.db.Preload("Sender").
+		Where("session_id = ? AND id > ?", sessionID, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// GetMediaFiles 根据访问地址批量获取上传的媒体文件
+func (r *messageRepository) GetMediaFiles(urls []string) ([]*model.MediaFile, error) {
+	var files []*model.MediaFile
+	if len(urls) == 0 {
+		return files, nil
+	}
+	if err := r.db.Where("url IN ?", urls).Find(&files).Error; err != nil {
+		return nil, err
 	}
-	return unreadMessage.Count, nil
-} 
\ No newline at end of file
+	return files, nil
+}
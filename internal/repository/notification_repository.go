@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"ticktok-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository 推送令牌与推送偏好数据仓库接口
+type NotificationRepository interface {
+	SaveDeviceToken(token *model.DeviceToken) error
+	DeleteDeviceToken(userID uint, token string) error
+	DeleteToken(token string) error
+	GetDeviceTokens(userID uint) ([]*model.DeviceToken, error)
+	GetPreference(userID uint) (*model.NotificationPreference, error)
+	SavePreference(preference *model.NotificationPreference) error
+}
+
+// notificationRepository 推送令牌与推送偏好数据仓库实现
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository 创建推送令牌与推送偏好数据仓库
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+// SaveDeviceToken 登记设备令牌，令牌已存在时改为归属当前用户
+func (r *notificationRepository) SaveDeviceToken(token *model.DeviceToken) error {
+	now := time.Now()
+	token.CreatedAt = now
+	token.UpdatedAt = now
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "provider", "updated_at"}),
+	}).Create(token).Error
+}
+
+// DeleteDeviceToken 删除用户登记的设备令牌
+func (r *notificationRepository) DeleteDeviceToken(userID uint, token string) error {
+	return r.db.Where("user_id = ? AND token = ?", userID, token).Delete(&model.DeviceToken{}).Error
+}
+
+// DeleteToken 删除已失效的设备令牌
+func (r *notificationRepository) DeleteToken(token string) error {
+	return r.db.Where("token = ?", token).Delete(&model.DeviceToken{}).Error
+}
+
+// GetDeviceTokens 获取用户的所有设备令牌
+func (r *notificationRepository) GetDeviceTokens(userID uint) ([]*model.DeviceToken, error) {
+	var tokens []*model.DeviceToken
+	if err := r.db.Where("user_id = ?", userID).Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// GetPreference 获取用户的推送偏好，没有记录时返回全部开启的默认偏好
+func (r *notificationRepository) GetPreference(userID uint) (*model.NotificationPreference, error) {
+	preference := model.NotificationPreference{
+		UserID:         userID,
+		Enabled:        true,
+		Messages:       true,
+		GroupMessages:  true,
+		FriendRequests: true,
+		ShowPreview:    true,
+	}
+	if err := r.db.Where("user_id = ?", userID).Limit(1).Find(&preference).Error; err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+// SavePreference 保存用户的推送偏好
+func (r *notificationRepository) SavePreference(preference *model.NotificationPreference) error {
+	preference.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(preference).Error
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"ticktok-service/config"
+	"ticktok-service/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PrivacyRepository 隐私设置数据仓库接口
+type PrivacyRepository interface {
+	GetSetting(userID uint) (*model.PrivacySetting, error)
+	GetSettings(userIDs []uint) (map[uint]*model.PrivacySetting, error)
+	SaveSetting(setting *model.PrivacySetting) error
+}
+
+// privacyRepository 隐私设置数据仓库实现
+type privacyRepository struct {
+	db *gorm.DB
+}
+
+// NewPrivacyRepository 创建隐私设置数据仓库
+func NewPrivacyRepository(db *gorm.DB) PrivacyRepository {
+	return &privacyRepository{
+		db: db,
+	}
+}
+
+// GetSetting 获取用户的隐私设置，没有记录时返回默认设置
+func (r *privacyRepository) GetSetting(userID uint) (*model.PrivacySetting, error) {
+	setting := defaultPrivacySetting(userID)
+	if err := r.db.Where("user_id = ?", userID).Limit(1).Find(setting).Error; err != nil {
+		return nil, err
+	}
+	return setting, nil
+}
+
+// GetSettings 批量获取用户的隐私设置，键为用户ID，没有记录的用户使用默认设置
+func (r *privacyRepository) GetSettings(userIDs []uint) (map[uint]*model.PrivacySetting, error) {
+	settings := make(map[uint]*model.PrivacySetting, len(userIDs))
+	if len(userIDs) == 0 {
+		return settings, nil
+	}
+
+	var saved []*model.PrivacySetting
+	if err := r.db.Where("user_id IN ?", userIDs).Find(&saved).Error; err != nil {
+		return nil, err
+	}
+	for _, setting := range saved {
+		settings[setting.UserID] = setting
+	}
+	for _, userID := range userIDs {
+		if _, ok := settings[userID]; !ok {
+			settings[userID] = defaultPrivacySetting(userID)
+		}
+	}
+	return settings, nil
+}
+
+// SaveSetting 保存用户的隐私设置
+func (r *privacyRepository) SaveSetting(setting *model.PrivacySetting) error {
+	setting.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(setting).Error
+}
+
+// defaultPrivacySetting 默认隐私设置：好友列表仅好友可见，在线状态和UID查找开启
+// 是否接收陌生人消息沿用chat.allow_stranger_messages配置
+func defaultPrivacySetting(userID uint) *model.PrivacySetting {
+	whoCanMessage := model.PrivacyFriends
+	if config.AppConfig.Chat.AllowStrangerMessages {
+		whoCanMessage = model.PrivacyEveryone
+	}
+	return &model.PrivacySetting{
+		UserID:               userID,
+		WhoCanMessage:        whoCanMessage,
+		FriendListVisibility: model.PrivacyFriends,
+		ShowOnlineStatus:     true,
+		SearchableByUID:      true,
+	}
+}
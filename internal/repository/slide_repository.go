@@ -8,10 +8,11 @@ import (
 
 // SlideRepository 轮播内容数据仓库接口
 type SlideRepository interface {
-	GetSlideItems(startIndex, pageSize int) ([]*model.SlideItem, int64, error)
+	GetSlideItems(startIndex, pageSize int, excludeUserIDs []uint) ([]*model.SlideItem, int64, error)
 	GetSlideItemByItemID(itemID string) (*model.SlideItem, error)
-	GetSlideItemsByType(contentType string, startIndex, pageSize int) ([]*model.SlideItem, int64, error)
-	SearchSlideItems(keyword string, startIndex, pageSize int) ([]*model.SlideItem, int64, error)
+	GetSlideItemsByType(contentType string, startIndex, pageSize int, excludeUserIDs []uint) ([]*model.SlideItem, int64, error)
+	SearchSlideItems(keyword string, startIndex, pageSize int, excludeUserIDs []uint) ([]*model.SlideItem, int64, error)
+	GetSlideItemsByItemIDs(itemIDs []string) ([]*model.SlideItem, error)
 }
 
 // slideRepository 轮播内容数据仓库实现
@@ -26,13 +27,13 @@ func NewSlideRepository(db *gorm.DB) SlideRepository {
 	}
 }
 
-// GetSlideItems 获取轮播内容列表
-func (r *slideRepository) GetSlideItems(startIndex, pageSize int) ([]*model.SlideItem, int64, error) {
+// GetSlideItems 获取轮播内容列表，excludeUserIDs发布的内容会被过滤
+func (r *slideRepository) GetSlideItems(startIndex, pageSize int, excludeUserIDs []uint) ([]*model.SlideItem, int64, error) {
 	var items []*model.SlideItem
 	var total int64
 	
 	// 查询总记录数
-	if err := r.db.Model(&model.SlideItem{}).Count(&total).Error; err != nil {
+	if err := r.db.Model(&model.SlideItem{}).Scopes(excludeAuthors(excludeUserIDs)).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 	
@@ -41,6 +42,7 @@ func (r *slideRepository) GetSlideItems(startIndex, pageSize int) ([]*model.Slid
 		Preload("Album", func(db *gorm.DB) *gorm.DB {
 			return db.Order("sort_order ASC")
 		}).
+		Scopes(excludeAuthors(excludeUserIDs)).
 		Offset(startIndex).
 		Limit(pageSize).
 		Order("created_at DESC").
@@ -68,13 +70,14 @@ func (r *slideRepository) GetSlideItemByItemID(itemID string) (*model.SlideItem,
 	return &item, nil
 }
 
-// GetSlideItemsByType 根据内容类型获取轮播内容
-func (r *slideRepository) GetSlideItemsByType(contentType string, startIndex, pageSize int) ([]*model.SlideItem, int64, error) {
+// GetSlideItemsByType 根据内容类型获取轮播内容，excludeUserIDs发布的内容会被过滤
+func (r *slideRepository) GetSlideItemsByType(contentType string, startIndex, pageSize int, excludeUserIDs []uint) ([]*model.SlideItem, int64, error) {
 	var items []*model.SlideItem
 	var total int64
 	
 	// 查询指定类型的总记录数
 	if err := r.db.Model(&model.SlideItem{}).
+		Scopes(excludeAuthors(excludeUserIDs)).
 		Where("content_type = ?", contentType).
 		Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -85,6 +88,7 @@ func (r *slideRepository) GetSlideItemsByType(contentType string, startIndex, pa
 		Preload("Album", func(db *gorm.DB) *gorm.DB {
 			return db.Order("sort_order ASC")
 		}).
+		Scopes(excludeAuthors(excludeUserIDs)).
 		Where("content_type = ?", contentType).
 		Offset(startIndex).
 		Limit(pageSize).
@@ -96,8 +100,8 @@ func (r *slideRepository) GetSlideItemsByType(contentType string, startIndex, pa
 	return items, total, nil
 }
 
-// SearchSlideItems 搜索轮播内容
-func (r *slideRepository) SearchSlideItems(keyword string, startIndex, pageSize int) ([]*model.SlideItem, int64, error) {
+// SearchSlideItems 搜索轮播内容，excludeUserIDs发布的内容会被过滤
+func (r *slideRepository) SearchSlideItems(keyword string, startIndex, pageSize int, excludeUserIDs []uint) ([]*model.SlideItem, int64, error) {
 	var items []*model.SlideItem
 	var total int64
 	
@@ -109,6 +113,7 @@ func (r *slideRepository) SearchSlideItems(keyword string, startIndex, pageSize
 	
 	// 查询匹配条件的总记录数
 	if err := r.db.Model(&model.SlideItem{}).
+		Scopes(excludeAuthors(excludeUserIDs)).
 		Where("title LIKE ? OR author LIKE ? OR item_id IN (?)", 
 			"%"+keyword+"%", "%"+keyword+"%", subQuery).
 		Count(&total).Error; err != nil {
@@ -120,6 +125,7 @@ func (r *slideRepository) SearchSlideItems(keyword string, startIndex, pageSize
 		Preload("Album", func(db *gorm.DB) *gorm.DB {
 			return db.Order("sort_order ASC")
 		}).
+		Scopes(excludeAuthors(excludeUserIDs)).
 		Where("title LIKE ? OR author LIKE ? OR item_id IN (?)", 
 			"%"+keyword+"%", "%"+keyword+"%", subQuery).
 		Offset(startIndex).
@@ -130,4 +136,21 @@ func (r *slideRepository) SearchSlideItems(keyword string, startIndex, pageSize
 	}
 	
 	return items, total, nil
-} 
\ No newline at end of file
+}
+
+// GetSlideItemsByItemIDs 根据ItemID批量获取轮播内容
+func (r *slideRepository) GetSlideItemsByItemIDs(itemIDs []string) ([]*model.SlideItem, error) {
+	var items []*model.SlideItem
+	if len(itemIDs) == 0 {
+		return items, nil
+	}
+	if err := r.db.Preload("Labels").
+		Preload("Album", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order ASC")
+		}).
+		Where("item_id IN ?", itemIDs).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
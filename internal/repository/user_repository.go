@@ -2,6 +2,7 @@ package repository
 
 import (
 	"ticktok-service/internal/model"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -9,7 +10,12 @@ import (
 // UserRepository 用户数据仓库接口
 type UserRepository interface {
 	GetUserByID(id uint) (*model.User, error)
+	GetUserByUID(uid uint) (*model.User, error)
 	GetUsersByIDs(ids []uint) ([]*model.User, error)
+	UpdatePresence(userID uint, status string, lastSeen time.Time) error
+	ResetPresence() error
+	UpdateProfile(userID uint, fields map[string]interface{}) error
+	GetMediaFileByURL(url string) (*model.MediaFile, error)
 }
 
 // userRepository 用户数据仓库实现
@@ -33,6 +39,15 @@ func (r *userRepository) GetUserByID(id uint) (*model.User, error) {
 	return &user, nil
 }
 
+// GetUserByUID 根据UID获取用户
+func (r *userRepository) GetUserByUID(uid uint) (*model.User, error) {
+	var user model.User
+	if err := r.db.Where("uid = ?", uid).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // GetUsersByIDs 批量获取用户
 func (r *userRepository) GetUsersByIDs(ids []uint) ([]*model.User, error) {
 	var users []*model.User
@@ -40,4 +55,35 @@ func (r *userRepository) GetUsersByIDs(ids []uint) ([]*model.User, error) {
 		return nil, err
 	}
 	return users, nil
-} 
\ No newline at end of file
+} 
+
+// UpdatePresence 更新用户在线状态和最后活跃时间
+func (r *userRepository) UpdatePresence(userID uint, status string, lastSeen time.Time) error {
+	return r.db.Model(&model.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"last_seen_at": lastSeen,
+		}).Error
+}
+
+// ResetPresence 将所有非离线用户置为离线，服务启动时没有任何在线连接
+func (r *userRepository) ResetPresence() error {
+	return r.db.Model(&model.User{}).
+		Where("status <> ?", model.PresenceOffline).
+		Update("status", model.PresenceOffline).Error
+}
+
+// UpdateProfile 更新用户资料的指定字段
+func (r *userRepository) UpdateProfile(userID uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.User{}).Where("id = ?", userID).Updates(fields).Error
+}
+
+// GetMediaFileByURL 根据访问地址获取上传的媒体文件
+func (r *userRepository) GetMediaFileByURL(url string) (*model.MediaFile, error) {
+	var file model.MediaFile
+	if err := r.db.Where("url = ?", url).First(&file).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"ticktok-service/internal/model"
+	"time"
+)
+
+// writeArchive 把用户的全部个人数据写入path处的zip压缩包，返回压缩包大小
+// 资料和社交关系等按类别写成JSON文件，聊天记录按会话写入messages目录，上传的文件原样写入media目录
+func (s *accountService) writeArchive(path string, userID uint, baseURL string) (int64, error) {
+	archive, err := s.accountRepo.GetArchive(userID)
+	if err != nil {
+		return 0, err
+	}
+	privacy, err := s.privacyRepo.GetSetting(userID)
+	if err != nil {
+		return 0, err
+	}
+	preference, err := s.notificationRepo.GetPreference(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return 0, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	entries := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", map[string]interface{}{
+			"user":                   archive.User,
+			"username":               archive.Username,
+			"privacy":                privacy,
+			"notificationPreference": preference,
+			"devices":                archive.Devices,
+		}},
+		{"social.json", map[string]interface{}{
+			"friends":        archive.Friends,
+			"friendRequests": archive.FriendRequests,
+			"following":      archive.Following,
+			"followers":      archive.Followers,
+			"blocks":         archive.Blocks,
+		}},
+		{"conversations.json", map[string]interface{}{
+			"settings":         archive.ConversationSettings,
+			"mutes":            archive.Mutes,
+			"groups":           archive.Groups,
+			"groupMemberships": archive.GroupMemberships,
+		}},
+		{"reactions.json", archive.Reactions},
+		{"drafts.json", draftArchivePosts(archive.Drafts)},
+		{"contents.json", contentArchivePosts(archive.Contents)},
+		{"blogs.json", archive.Blogs},
+	}
+	for _, entry := range entries {
+		if err := writeArchiveJSON(zw, entry.name, entry.data); err != nil {
+			return 0, err
+		}
+	}
+
+	media, err := writeArchiveMedia(zw, archive.MediaFiles, baseURL)
+	if err != nil {
+		return 0, err
+	}
+	if err := writeArchiveJSON(zw, "media.json", media); err != nil {
+		return 0, err
+	}
+	if err := s.writeArchiveChats(zw, archive, baseURL); err != nil {
+		return 0, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// writeArchiveChats 把用户参与的每个单聊和群聊的完整记录以JSON格式写入messages目录
+func (s *accountService) writeArchiveChats(zw *zip.Writer, archive *model.AccountArchive, baseURL string) error {
+	user := archive.User
+	exportedAt := time.Now().UnixMilli()
+
+	peers, err := s.userRepo.GetUsersByIDs(archive.ChatPeerIDs)
+	if err != nil {
+		return err
+	}
+	var headers []*model.ExportHeader
+	for _, peer := range peers {
+		header := &model.ExportHeader{
+			SessionID:  model.SessionIDFor(user.ID, peer.ID),
+			Title:      "与" + peer.Nickname + "的聊天记录",
+			ExportedBy: user.ID,
+			ExportedAt: exportedAt,
+			Participants: []*model.ExportParticipant{
+				{ID: user.ID, Nickname: user.Nickname},
+				{ID: peer.ID, Nickname: peer.Nickname},
+			},
+		}
+		if peer.ID == user.ID {
+			header.Participants = header.Participants[:1]
+		}
+		headers = append(headers, header)
+	}
+
+	for _, group := range archive.Groups {
+		members, err := s.groupRepo.GetMembers(group.ID)
+		if err != nil {
+			return err
+		}
+		header := &model.ExportHeader{
+			SessionID:    model.GroupSessionID(group.ID),
+			Title:        group.Name + " 群聊记录",
+			IsGroup:      true,
+			ExportedBy:   user.ID,
+			ExportedAt:   exportedAt,
+			Participants: make([]*model.ExportParticipant, 0, len(members)),
+		}
+		for _, member := range members {
+			header.Participants = append(header.Participants, &model.ExportParticipant{
+				ID:       member.UserID,
+				Nickname: member.User.Nickname,
+			})
+		}
+		headers = append(headers, header)
+	}
+
+	for _, header := range headers {
+		export, err := newChatExport(s.messageRepo, header, model.ExportFormatJSON, baseURL, 0)
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create("messages/" + header.SessionID + ".json")
+		if err != nil {
+			return err
+		}
+		for {
+			more, err := export.Next(w)
+			if err != nil {
+				return err
+			}
+			if !more {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// writeArchiveMedia 把用户上传的文件写入media目录，磁盘上已不存在的文件只记录信息
+func writeArchiveMedia(zw *zip.Writer, files []*model.MediaFile, baseURL string) ([]*model.ArchiveMedia, error) {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	media := make([]*model.ArchiveMedia, 0, len(files))
+	for _, file := range files {
+		item := &model.ArchiveMedia{
+			ID:        file.ID,
+			Type:      file.Type,
+			URL:       file.URL,
+			FileName:  file.FileName,
+			FileSize:  file.FileSize,
+			CreatedAt: file.CreatedAt.UnixMilli(),
+		}
+		if strings.HasPrefix(file.URL, "/") {
+			item.URL = baseURL + file.URL
+		}
+		media = append(media, item)
+
+		src, err := os.Open(file.FilePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		item.Path = "media/" + file.Type + "/" + file.ID + filepath.Ext(file.FilePath)
+		w, err := zw.Create(item.Path)
+		if err == nil {
+			_, err = io.Copy(w, src)
+		}
+		src.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return media, nil
+}
+
+// writeArchiveJSON 把数据以缩进的JSON格式写入压缩包中的name文件
+func writeArchiveJSON(zw *zip.Writer, name string, data interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// draftArchivePosts 转换导出的草稿
+func draftArchivePosts(drafts []*model.Draft) []*model.ArchivePost {
+	posts := make([]*model.ArchivePost, 0, len(drafts))
+	for _, draft := range drafts {
+		posts = append(posts, toArchivePost(&model.Content{
+			ID:          draft.ID,
+			Title:       draft.Title,
+			Description: draft.Description,
+			MediaItems:  draft.MediaItems,
+			Topics:      draft.Topics,
+			Mentions:    draft.Mentions,
+			Tags:        draft.Tags,
+			Visibility:  draft.Visibility,
+			IsDaily:     draft.IsDaily,
+			CreatedAt:   draft.CreatedAt,
+			UpdatedAt:   draft.UpdatedAt,
+		}))
+	}
+	return posts
+}
+
+// contentArchivePosts 转换导出的发布内容
+func contentArchivePosts(contents []*model.Content) []*model.ArchivePost {
+	posts := make([]*model.ArchivePost, 0, len(contents))
+	for _, content := range contents {
+		posts = append(posts, toArchivePost(content))
+	}
+	return posts
+}
+
+// toArchivePost 将Content转换为ArchivePost，解析JSON存储的字段
+func toArchivePost(content *model.Content) *model.ArchivePost {
+	post := &model.ArchivePost{
+		ID:          content.ID,
+		Title:       content.Title,
+		Description: content.Description,
+		Visibility:  content.Visibility,
+		IsDaily:     content.IsDaily,
+		CreatedAt:   content.CreatedAt.UnixMilli(),
+		UpdatedAt:   content.UpdatedAt.UnixMilli(),
+	}
+	json.Unmarshal([]byte(content.MediaItems), &post.MediaItems)
+	json.Unmarshal([]byte(content.Topics), &post.Topics)
+	json.Unmarshal([]byte(content.Mentions), &post.Mentions)
+	json.Unmarshal([]byte(content.Tags), &post.Tags)
+	return post
+}
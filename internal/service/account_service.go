@@ -0,0 +1,333 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"ticktok-service/config"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
+	"ticktok-service/internal/repository"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// 账号注销与个人数据导出的默认配置
+const (
+	defaultDeletionGracePeriod  = 30 * 24 * time.Hour
+	defaultAccountSweepInterval = time.Hour
+	defaultExportDir            = "./exports"
+	defaultExportTTL            = 7 * 24 * time.Hour
+)
+
+// deletionBatchSize 每次检查时最多执行的注销数量
+const deletionBatchSize = 100
+
+// 账号相关错误
+var (
+	ErrDeletionPending  = errors.New("账号已在注销冷静期内")
+	ErrDeletionNotFound = errors.New("没有可撤销的注销申请")
+	ErrExportInProgress = errors.New("已有正在生成的数据导出，请稍后再试")
+	ErrExportNotFound   = errors.New("数据导出不存在")
+	ErrExportNotReady   = errors.New("数据导出尚未完成或已过期")
+)
+
+// AccountService 账号注销与个人数据导出服务接口
+type AccountService interface {
+	Start()
+	RequestDeletion(userID uint, req *model.DeleteAccountRequest) (*model.AccountDeletionResponse, error)
+	GetDeletion(userID uint) (*model.AccountDeletionResponse, error)
+	CancelDeletion(userID uint) error
+	CreateExport(userID uint, baseURL string) (*model.DataExportResponse, error)
+	GetExports(userID uint) ([]*model.DataExportResponse, error)
+	GetExportFile(userID, exportID uint) (string, string, error)
+}
+
+// accountService 账号注销与个人数据导出服务实现
+type accountService struct {
+	accountRepo      repository.AccountRepository
+	authRepo         repository.AuthRepository
+	userRepo         repository.UserRepository
+	groupRepo        repository.GroupRepository
+	messageRepo      repository.MessageRepository
+	privacyRepo      repository.PrivacyRepository
+	notificationRepo repository.NotificationRepository
+	hub              *ws.Hub
+}
+
+// NewAccountService 创建账号注销与个人数据导出服务
+func NewAccountService(db *gorm.DB, hub *ws.Hub) AccountService {
+	return &accountService{
+		accountRepo:      repository.NewAccountRepository(db),
+		authRepo:         repository.NewAuthRepository(db),
+		userRepo:         repository.NewUserRepository(db),
+		groupRepo:        repository.NewGroupRepository(db),
+		messageRepo:      repository.NewMessageRepository(db),
+		privacyRepo:      repository.NewPrivacyRepository(db),
+		notificationRepo: repository.NewNotificationRepository(db),
+		hub:              hub,
+	}
+}
+
+// Start 启动后台协程：先把上次运行中断的导出标记为失败，再定期执行到期的注销并清理过期的导出文件
+func (s *accountService) Start() {
+	interval := config.AppConfig.Account.SweepInterval
+	if interval <= 0 {
+		interval = defaultAccountSweepInterval
+	}
+
+	go func() {
+		if err := s.accountRepo.FailPendingExports("服务重启，导出已中断"); err != nil {
+			log.Printf("重置未完成的数据导出失败: %v", err)
+		}
+
+		s.sweep(time.Now())
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			s.sweep(now)
+		}
+	}()
+}
+
+// RequestDeletion 申请注销账号，需要验证密码，冷静期结束前可以撤销
+func (s *accountService) RequestDeletion(userID uint, req *model.DeleteAccountRequest) (*model.AccountDeletionResponse, error) {
+	credential, err := s.authRepo.GetCredentialByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(credential.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if deletion, err := s.accountRepo.GetDeletion(userID); err == nil && deletion.CompletedAt == nil {
+		return nil, ErrDeletionPending
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	gracePeriod := config.AppConfig.Account.DeletionGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = defaultDeletionGracePeriod
+	}
+	now := time.Now()
+	deletion := &model.AccountDeletion{
+		UserID:      userID,
+		RequestedAt: now,
+		ScheduledAt: now.Add(gracePeriod),
+	}
+	if err := s.accountRepo.SaveDeletion(deletion); err != nil {
+		return nil, err
+	}
+	return toAccountDeletionResponse(deletion), nil
+}
+
+// GetDeletion 获取注销申请状态，没有申请时pending为false
+func (s *accountService) GetDeletion(userID uint) (*model.AccountDeletionResponse, error) {
+	deletion, err := s.accountRepo.GetDeletion(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.AccountDeletionResponse{}, nil
+		}
+		return nil, err
+	}
+	return toAccountDeletionResponse(deletion), nil
+}
+
+// CancelDeletion 撤销冷静期内的注销申请
+func (s *accountService) CancelDeletion(userID uint) error {
+	cancelled, err := s.accountRepo.CancelDeletion(userID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrDeletionNotFound
+	}
+	return nil
+}
+
+// CreateExport 创建个人数据导出，压缩包在后台生成，进度通过GetExports查询
+// baseURL用于把上传文件的相对地址补全为可直接访问的链接
+func (s *accountService) CreateExport(userID uint, baseURL string) (*model.DataExportResponse, error) {
+	pending, err := s.accountRepo.HasPendingExport(userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrExportInProgress
+	}
+
+	export := &model.DataExport{
+		UserID: userID,
+		Status: model.DataExportPending,
+	}
+	if err := s.accountRepo.CreateExport(export); err != nil {
+		return nil, err
+	}
+
+	go s.buildExport(export, baseURL)
+
+	return toDataExportResponse(export), nil
+}
+
+// GetExports 获取用户的个人数据导出记录，最新的在前
+func (s *accountService) GetExports(userID uint) ([]*model.DataExportResponse, error) {
+	exports, err := s.accountRepo.GetExports(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*model.DataExportResponse, 0, len(exports))
+	for _, export := range exports {
+		responses = append(responses, toDataExportResponse(export))
+	}
+	return responses, nil
+}
+
+// GetExportFile 获取可下载的导出压缩包，返回文件路径和下载文件名
+func (s *accountService) GetExportFile(userID, exportID uint) (string, string, error) {
+	export, err := s.accountRepo.GetExport(exportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", ErrExportNotFound
+		}
+		return "", "", err
+	}
+	if export.UserID != userID {
+		return "", "", ErrExportNotFound
+	}
+	if export.Status != model.DataExportReady || (export.ExpiresAt != nil && time.Now().After(*export.ExpiresAt)) {
+		return "", "", ErrExportNotReady
+	}
+
+	fileName := fmt.Sprintf("ticktok_data_%d_%s.zip", userID, export.CreatedAt.Format("20060102150405"))
+	return export.FilePath, fileName, nil
+}
+
+// buildExport 生成导出压缩包并更新任务状态，失败时删除写了一半的文件
+func (s *accountService) buildExport(export *model.DataExport, baseURL string) {
+	dir := config.AppConfig.Account.ExportDir
+	if dir == "" {
+		dir = defaultExportDir
+	}
+	ttl := config.AppConfig.Account.ExportTTL
+	if ttl <= 0 {
+		ttl = defaultExportTTL
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%d_%d.zip", export.UserID, export.ID))
+	size, err := s.writeArchive(path, export.UserID, baseURL)
+
+	now := time.Now()
+	export.FinishedAt = &now
+	if err != nil {
+		log.Printf("生成用户 %d 的数据导出失败: %v", export.UserID, err)
+		os.Remove(path)
+		reason := []rune(err.Error())
+		if len(reason) > 255 {
+			reason = reason[:255]
+		}
+		export.Status = model.DataExportFailed
+		export.Error = string(reason)
+	} else {
+		expiresAt := now.Add(ttl)
+		export.Status = model.DataExportReady
+		export.FilePath = path
+		export.FileSize = size
+		export.ExpiresAt = &expiresAt
+	}
+	if err := s.accountRepo.UpdateExport(export); err != nil {
+		log.Printf("更新数据导出 %d 状态失败: %v", export.ID, err)
+	}
+}
+
+// sweep 执行冷静期已结束的注销，并删除过期的导出文件
+func (s *accountService) sweep(now time.Time) {
+	deletions, err := s.accountRepo.GetDueDeletions(now, deletionBatchSize)
+	if err != nil {
+		log.Printf("获取到期的注销申请失败: %v", err)
+	}
+	for _, deletion := range deletions {
+		if err := s.deleteAccount(deletion.UserID, now); err != nil {
+			log.Printf("注销用户 %d 失败，下次检查时重试: %v", deletion.UserID, err)
+		}
+	}
+
+	exports, err := s.accountRepo.GetExpiredExports(now)
+	if err != nil {
+		log.Printf("获取过期的数据导出失败: %v", err)
+	}
+	for _, export := range exports {
+		if err := removeFile(export.FilePath); err != nil {
+			log.Printf("删除过期的数据导出 %d 失败: %v", export.ID, err)
+			continue
+		}
+		export.Status = model.DataExportExpired
+		export.FilePath = ""
+		if err := s.accountRepo.UpdateExport(export); err != nil {
+			log.Printf("更新数据导出 %d 状态失败: %v", export.ID, err)
+		}
+	}
+}
+
+// deleteAccount 注销账号：在事务中确认申请未被撤销后删除磁盘上的上传文件和导出压缩包，再匿名化账号并清除数据，
+// 完成后断开该用户的所有WebSocket连接；申请已被撤销时跳过，文件删除失败时不修改数据库，下次检查时整体重试
+func (s *accountService) deleteAccount(userID uint, now time.Time) error {
+	err := s.accountRepo.PurgeUser(userID, now, func(paths []string) error {
+		for _, path := range paths {
+			if err := removeFile(path); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.hub.DisconnectUser(userID)
+	return nil
+}
+
+// removeFile 删除文件，路径为空或文件已不存在时视为成功
+func removeFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// toAccountDeletionResponse 将AccountDeletion转换为AccountDeletionResponse
+func toAccountDeletionResponse(deletion *model.AccountDeletion) *model.AccountDeletionResponse {
+	return &model.AccountDeletionResponse{
+		Pending:     deletion.CompletedAt == nil,
+		RequestedAt: deletion.RequestedAt.UnixMilli(),
+		ScheduledAt: deletion.ScheduledAt.UnixMilli(),
+	}
+}
+
+// toDataExportResponse 将DataExport转换为DataExportResponse
+func toDataExportResponse(export *model.DataExport) *model.DataExportResponse {
+	return &model.DataExportResponse{
+		ID:         export.ID,
+		Status:     export.Status,
+		FileSize:   export.FileSize,
+		Error:      export.Error,
+		CreatedAt:  export.CreatedAt.UnixMilli(),
+		FinishedAt: unixMilli(export.FinishedAt),
+		ExpiresAt:  unixMilli(export.ExpiresAt),
+	}
+}
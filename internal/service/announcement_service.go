@@ -0,0 +1,219 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"ticktok-service/config"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
+	"ticktok-service/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultAnnouncementBatchSize 未配置时群发公告每批写入的接收者数量
+const defaultAnnouncementBatchSize = 1000
+
+// 公告相关错误
+var (
+	ErrNotSystemAccount     = errors.New("只能以系统账号的名义发送公告")
+	ErrAnnouncementNotFound = errors.New("公告不存在")
+)
+
+// AnnouncementService 系统公告服务接口
+type AnnouncementService interface {
+	Broadcast(adminID uint, req *model.AnnouncementRequest) (*model.AnnouncementResponse, error)
+	GetAnnouncement(id uint) (*model.AnnouncementResponse, error)
+	GetAnnouncements(page, pageSize int) ([]*model.AnnouncementResponse, int64, error)
+}
+
+// announcementService 系统公告服务实现
+type announcementService struct {
+	announcementRepo repository.AnnouncementRepository
+	userRepo         repository.UserRepository
+	resolver         *payloadResolver
+	hub              *ws.Hub
+}
+
+// NewAnnouncementService 创建系统公告服务
+func NewAnnouncementService(db *gorm.DB, hub *ws.Hub) AnnouncementService {
+	return &announcementService{
+		announcementRepo: repository.NewAnnouncementRepository(db),
+		userRepo:         repository.NewUserRepository(db),
+		resolver:         newPayloadResolver(db),
+		hub:              hub,
+	}
+}
+
+// Broadcast 以系统账号的名义群发公告，每个接收者收到一条来自该账号的单聊消息
+// 公告记录创建后立即返回，消息在后台分批写入，进度可通过GetAnnouncement查询
+func (s *announcementService) Broadcast(adminID uint, req *model.AnnouncementRequest) (*model.AnnouncementResponse, error) {
+	sender, err := s.userRepo.GetUserByID(req.SenderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if sender.AccountType != model.AccountTypeSystem {
+		return nil, ErrNotSystemAccount
+	}
+
+	// 按消息类型校验内容
+	content, err := s.resolver.resolve(req.Type, req.Content, req.Duration, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	segment, err := json.Marshal(&req.Segment)
+	if err != nil {
+		return nil, err
+	}
+	announcement := &model.Announcement{
+		SenderID:  sender.ID,
+		CreatedBy: adminID,
+		Type:      req.Type,
+		Content:   content.Content,
+		Payload:   content.Payload,
+		Caption:   req.Caption,
+		Duration:  req.Duration,
+		Segment:   string(segment),
+		Status:    model.AnnouncementStatusSending,
+	}
+	if err := s.announcementRepo.CreateAnnouncement(announcement); err != nil {
+		return nil, err
+	}
+
+	go s.deliver(announcement, req.Segment)
+
+	return toAnnouncementResponse(announcement), nil
+}
+
+// GetAnnouncement 获取公告及其群发进度
+func (s *announcementService) GetAnnouncement(id uint) (*model.AnnouncementResponse, error) {
+	announcement, err := s.announcementRepo.GetAnnouncementByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAnnouncementNotFound
+		}
+		return nil, err
+	}
+	return toAnnouncementResponse(announcement), nil
+}
+
+// GetAnnouncements 分页获取公告
+func (s *announcementService) GetAnnouncements(page, pageSize int) ([]*model.AnnouncementResponse, int64, error) {
+	announcements, total, err := s.announcementRepo.GetAnnouncements(page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*model.AnnouncementResponse, 0, len(announcements))
+	for _, announcement := range announcements {
+		responses = append(responses, toAnnouncementResponse(announcement))
+	}
+	return responses, total, nil
+}
+
+// deliver 按用户ID顺序分批写入公告消息，每批写入后推送给在线的接收者并更新进度
+// 进程在群发中途退出时公告停留在发送中状态，已写入的消息不会回滚
+func (s *announcementService) deliver(announcement *model.Announcement, segment model.AnnouncementSegment) {
+	batchSize := config.AppConfig.Admin.AnnouncementBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultAnnouncementBatchSize
+	}
+	template := &model.Message{
+		SenderID:  announcement.SenderID,
+		Type:      announcement.Type,
+		Content:   announcement.Content,
+		Payload:   announcement.Payload,
+		Caption:   announcement.Caption,
+		Duration:  announcement.Duration,
+		Timestamp: time.Now().UnixMilli(),
+		Status:    "sent",
+	}
+
+	var delivered int
+	var afterID uint
+	fail := func(err error) {
+		log.Printf("群发公告 %d 失败: %v", announcement.ID, err)
+		reason := []rune(err.Error())
+		if len(reason) > 255 {
+			reason = reason[:255]
+		}
+		if err := s.announcementRepo.UpdateAnnouncement(announcement.ID, map[string]interface{}{
+			"status":          model.AnnouncementStatusFailed,
+			"recipient_count": delivered,
+			"error":           string(reason),
+			"finished_at":     time.Now(),
+		}); err != nil {
+			log.Printf("更新公告 %d 状态失败: %v", announcement.ID, err)
+		}
+	}
+
+	for {
+		recipientIDs, err := s.announcementRepo.FindRecipientIDs(announcement.SenderID, &segment, afterID, batchSize)
+		if err != nil {
+			fail(err)
+			return
+		}
+		if len(recipientIDs) == 0 {
+			break
+		}
+
+		messages, err := s.announcementRepo.DeliverAnnouncement(template, recipientIDs)
+		if err != nil {
+			fail(err)
+			return
+		}
+		for _, message := range messages {
+			if s.hub.IsOnline(message.ReceiverID) {
+				s.hub.SendToUser(message.ReceiverID, ws.NewEvent(ws.EventMessageNew, toChatMessage(message, message.ReceiverID)))
+			}
+		}
+
+		delivered += len(recipientIDs)
+		afterID = recipientIDs[len(recipientIDs)-1]
+		if err := s.announcementRepo.UpdateAnnouncement(announcement.ID, map[string]interface{}{
+			"recipient_count": delivered,
+		}); err != nil {
+			log.Printf("更新公告 %d 进度失败: %v", announcement.ID, err)
+		}
+	}
+
+	if err := s.announcementRepo.UpdateAnnouncement(announcement.ID, map[string]interface{}{
+		"status":          model.AnnouncementStatusSent,
+		"recipient_count": delivered,
+		"finished_at":     time.Now(),
+	}); err != nil {
+		log.Printf("更新公告 %d 状态失败: %v", announcement.ID, err)
+	}
+}
+
+// toAnnouncementResponse 转换公告响应
+func toAnnouncementResponse(announcement *model.Announcement) *model.AnnouncementResponse {
+	segment := &model.AnnouncementSegment{}
+	if announcement.Segment != "" {
+		if err := json.Unmarshal([]byte(announcement.Segment), segment); err != nil {
+			log.Printf("解析公告 %d 的接收者范围失败: %v", announcement.ID, err)
+		}
+	}
+	return &model.AnnouncementResponse{
+		ID:             announcement.ID,
+		SenderID:       announcement.SenderID,
+		CreatedBy:      announcement.CreatedBy,
+		Type:           announcement.Type,
+		Content:        announcement.Content,
+		Caption:        announcement.Caption,
+		Duration:       announcement.Duration,
+		Payload:        rawPayload(announcement.Payload),
+		Segment:        segment,
+		Status:         announcement.Status,
+		RecipientCount: announcement.RecipientCount,
+		Error:          announcement.Error,
+		CreatedAt:      announcement.CreatedAt.UnixMilli(),
+		FinishedAt:     unixMilli(announcement.FinishedAt),
+	}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"errors"
+	"ticktok-service/config"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/token"
+	"ticktok-service/internal/repository"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// 认证相关错误
+var (
+	ErrUsernameTaken      = errors.New("用户名已被占用")
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
+)
+
+// AuthService 认证服务接口
+type AuthService interface {
+	Register(req *model.RegisterRequest) (*model.TokenResponse, error)
+	Login(req *model.LoginRequest) (*model.TokenResponse, error)
+	Refresh(refreshToken string) (*model.TokenResponse, error)
+	Logout(userID uint, refreshToken string) error
+}
+
+// authService 认证服务实现
+type authService struct {
+	authRepo repository.AuthRepository
+	userRepo repository.UserRepository
+}
+
+// NewAuthService 创建认证服务
+func NewAuthService(db *gorm.DB) AuthService {
+	return &authService{
+		authRepo: repository.NewAuthRepository(db),
+		userRepo: repository.NewUserRepository(db),
+	}
+}
+
+// Register 注册新用户并直接登录
+func (s *authService) Register(req *model.RegisterRequest) (*model.TokenResponse, error) {
+	// 检查用户名是否已存在
+	if _, err := s.authRepo.GetCredentialByUsername(req.Username); err == nil {
+		return nil, ErrUsernameTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// 哈希密码
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		Nickname: req.Nickname,
+		Avatar:   req.Avatar,
+		Status:   "offline",
+	}
+	credential := &model.UserCredential{
+		Username:     req.Username,
+		PasswordHash: string(hash),
+	}
+	if err := s.authRepo.CreateUserWithCredential(user, credential); err != nil {
+		// 并发注册同一用户名时由唯一索引拒绝
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+
+	return s.issueTokens(user)
+}
+
+// Login 校验用户名密码并签发令牌
+func (s *authService) Login(req *model.LoginRequest) (*model.TokenResponse, error) {
+	credential, err := s.authRepo.GetCredentialByUsername(req.Username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(credential.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.issueTokens(&credential.User)
+}
+
+// Refresh 使用刷新令牌换取新的令牌对，旧的刷新令牌随即作废
+func (s *authService) Refresh(refreshToken string) (*model.TokenResponse, error) {
+	claims, err := token.Parse(refreshToken, token.TypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, token.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	tokens, stored, err := s.generateTokens(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authRepo.RotateRefreshToken(claims.ID, claims.UserID, stored); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, token.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Logout 注销刷新令牌，未指定时注销该用户的全部刷新令牌
+func (s *authService) Logout(userID uint, refreshToken string) error {
+	if refreshToken == "" {
+		return s.authRepo.RevokeUserRefreshTokens(userID)
+	}
+
+	claims, err := token.Parse(refreshToken, token.TypeRefresh)
+	if err != nil {
+		return err
+	}
+	if claims.UserID != userID {
+		return token.ErrInvalidToken
+	}
+
+	return s.authRepo.RevokeRefreshToken(claims.ID)
+}
+
+// issueTokens 为用户签发访问令牌和刷新令牌
+func (s *authService) issueTokens(user *model.User) (*model.TokenResponse, error) {
+	tokens, stored, err := s.generateTokens(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authRepo.CreateRefreshToken(stored); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// generateTokens 生成访问令牌和刷新令牌，返回响应和待保存的刷新令牌记录
+func (s *authService) generateTokens(user *model.User) (*model.TokenResponse, *model.RefreshToken, error) {
+	accessToken, _, err := token.Generate(user.ID, token.TypeAccess)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, refreshClaims, err := token.Generate(user.ID, token.TypeRefresh)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stored := &model.RefreshToken{
+		UserID:    user.ID,
+		TokenID:   refreshClaims.ID,
+		ExpiresAt: refreshClaims.ExpiresAt.Time,
+	}
+
+	return &model.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(config.AppConfig.JWT.AccessTokenTTL / time.Second),
+		User:         *toUserResponse(user),
+	}, stored, nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// 屏蔽相关错误
+var (
+	ErrCannotBlockSelf = errors.New("不能屏蔽自己")
+	ErrUserBlocked     = errors.New("你与对方之间存在屏蔽关系")
+)
+
+// BlockService 屏蔽服务接口
+type BlockService interface {
+	BlockUser(userID, blockedID uint) error
+	UnblockUser(userID, blockedID uint) error
+	GetBlockedUsers(userID uint) ([]*model.BlockedUserResponse, error)
+}
+
+// blockService 屏蔽服务实现
+type blockService struct {
+	blockRepo repository.BlockRepository
+	userRepo  repository.UserRepository
+}
+
+// NewBlockService 创建屏蔽服务
+func NewBlockService(db *gorm.DB) BlockService {
+	return &blockService{
+		blockRepo: repository.NewBlockRepository(db),
+		userRepo:  repository.NewUserRepository(db),
+	}
+}
+
+// BlockUser 屏蔽用户，被屏蔽者无法再给当前用户发消息，其内容也不再出现在当前用户的列表中
+func (s *blockService) BlockUser(userID, blockedID uint) error {
+	if userID == blockedID {
+		return ErrCannotBlockSelf
+	}
+	if _, err := s.userRepo.GetUserByID(blockedID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	return s.blockRepo.BlockUser(userID, blockedID)
+}
+
+// UnblockUser 取消屏蔽
+func (s *blockService) UnblockUser(userID, blockedID uint) error {
+	return s.blockRepo.UnblockUser(userID, blockedID)
+}
+
+// GetBlockedUsers 获取屏蔽列表
+func (s *blockService) GetBlockedUsers(userID uint) ([]*model.BlockedUserResponse, error) {
+	blocks, err := s.blockRepo.GetBlocks(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*model.BlockedUserResponse, 0, len(blocks))
+	for _, block := range blocks {
+		responses = append(responses, &model.BlockedUserResponse{
+			ID:        block.BlockedID,
+			Nickname:  block.Blocked.Nickname,
+			Avatar:    block.Blocked.Avatar,
+			BlockedAt: block.CreatedAt.UnixMilli(),
+		})
+	}
+
+	return responses, nil
+}
+
+// hiddenAuthorIDs 获取viewerID屏蔽的用户ID，游客返回nil
+func hiddenAuthorIDs(blockRepo repository.BlockRepository, viewerID uint) ([]uint, error) {
+	if viewerID == 0 {
+		return nil, nil
+	}
+	return blockRepo.GetBlockedIDs(viewerID)
+}
@@ -7,34 +7,79 @@ import (
 
 // BlogService 博客服务接口
 type BlogService interface {
-	GetBlogs(page, pageSize int) ([]model.Blog, int64, error)
-	GetBlogByID(id uint) (*model.Blog, error)
-	SearchBlogs(keyword string, page, pageSize int) ([]model.Blog, int64, error)
+	GetBlogs(viewerID uint, page, pageSize int) ([]model.Blog, int64, error)
+	GetBlogByID(viewerID, id uint) (*model.Blog, error)
+	SearchBlogs(viewerID uint, keyword string, page, pageSize int) ([]model.Blog, int64, error)
 }
 
 // blogService 博客服务实现
 type blogService struct {
-	blogRepo repository.BlogRepository
+	blogRepo   repository.BlogRepository
+	blockRepo  repository.BlockRepository
+	followRepo repository.FollowRepository
 }
 
 // NewBlogService 创建新的博客服务
 func NewBlogService() BlogService {
 	return &blogService{
-		blogRepo: repository.NewBlogRepository(),
+		blogRepo:   repository.NewBlogRepository(),
+		blockRepo:  repository.NewBlockRepository(model.DB),
+		followRepo: repository.NewFollowRepository(model.DB),
 	}
 }
 
-// GetBlogs 获取博客列表
-func (s *blogService) GetBlogs(page, pageSize int) ([]model.Blog, int64, error) {
-	return s.blogRepo.GetBlogs(page, pageSize)
+// GetBlogs 获取博客列表，过滤当前用户屏蔽的作者，viewerID为0表示游客
+func (s *blogService) GetBlogs(viewerID uint, page, pageSize int) ([]model.Blog, int64, error) {
+	blockedIDs, err := hiddenAuthorIDs(s.blockRepo, viewerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	blogs, total, err := s.blogRepo.GetBlogs(page, pageSize, blockedIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return blogs, total, s.markFollowing(viewerID, blogs)
 }
 
-// GetBlogByID 根据ID获取博客
-func (s *blogService) GetBlogByID(id uint) (*model.Blog, error) {
-	return s.blogRepo.GetBlogByID(id)
+// GetBlogByID 根据ID获取博客，viewerID为0表示游客
+func (s *blogService) GetBlogByID(viewerID, id uint) (*model.Blog, error) {
+	blog, err := s.blogRepo.GetBlogByID(id)
+	if err != nil {
+		return nil, err
+	}
+	blogs := []model.Blog{*blog}
+	if err := s.markFollowing(viewerID, blogs); err != nil {
+		return nil, err
+	}
+	return &blogs[0], nil
 }
 
-// SearchBlogs 搜索博客
-func (s *blogService) SearchBlogs(keyword string, page, pageSize int) ([]model.Blog, int64, error) {
-	return s.blogRepo.SearchBlogs(keyword, page, pageSize)
+// SearchBlogs 搜索博客，过滤当前用户屏蔽的作者
+func (s *blogService) SearchBlogs(viewerID uint, keyword string, page, pageSize int) ([]model.Blog, int64, error) {
+	blockedIDs, err := hiddenAuthorIDs(s.blockRepo, viewerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	blogs, total, err := s.blogRepo.SearchBlogs(keyword, page, pageSize, blockedIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return blogs, total, s.markFollowing(viewerID, blogs)
+}
+
+// markFollowing 根据当前用户的关注关系设置博客的IsFollowing，user_id未补齐的历史博客按数字authorId补上发布者
+func (s *blogService) markFollowing(viewerID uint, blogs []model.Blog) error {
+	authorIDs := make([]uint, 0, len(blogs))
+	for i := range blogs {
+		blogs[i].UserID = blogs[i].PublisherID()
+		authorIDs = append(authorIDs, blogs[i].UserID)
+	}
+	following, err := followingAuthors(s.followRepo, viewerID, authorIDs)
+	if err != nil {
+		return err
+	}
+	for i := range blogs {
+		blogs[i].IsFollowing = following[blogs[i].UserID]
+	}
+	return nil
 } 
\ No newline at end of file
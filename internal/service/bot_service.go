@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"log"
+	"ticktok-service/config"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/bot"
+	"ticktok-service/internal/pkg/ws"
+	"ticktok-service/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// botFailureReply 生成回复失败时机器人发送的内容
+const botFailureReply = "抱歉，我暂时无法回答，请稍后再试。"
+
+// BotResponder 机器人回复器，根据对话生成机器人的回复
+type BotResponder interface {
+	Reply(ctx context.Context, req *model.BotRequest) (string, error)
+}
+
+// BotService 机器人运行时接口
+// 用户发给AI机器人好友的消息由后台协程交给回复器处理，回复作为机器人发送的普通消息保存和推送
+type BotService interface {
+	Start()
+	HandleMessage(message *model.Message)
+}
+
+// botService 机器人运行时实现
+type botService struct {
+	messageRepo repository.MessageRepository
+	userRepo    repository.UserRepository
+	responder   BotResponder
+	hub         *ws.Hub
+	notifier    NotificationService
+	queues      []chan *model.Message
+}
+
+// NewBotService 创建机器人运行时，按配置选择回复器
+func NewBotService(db *gorm.DB, hub *ws.Hub, notifier NotificationService) BotService {
+	workers := config.AppConfig.Bot.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	queueSize := config.AppConfig.Bot.QueueSize
+	if queueSize <= 0 {
+		queueSize = 256
+	}
+
+	queues := make([]chan *model.Message, workers)
+	for i := range queues {
+		queues[i] = make(chan *model.Message, queueSize)
+	}
+
+	return &botService{
+		messageRepo: repository.NewMessageRepository(db),
+		userRepo:    repository.NewUserRepository(db),
+		responder:   newBotResponder(),
+		hub:         hub,
+		notifier:    notifier,
+		queues:      queues,
+	}
+}
+
+// newBotResponder 按配置创建回复器，默认使用规则回复器
+func newBotResponder() BotResponder {
+	botConfig := config.AppConfig.Bot
+	if botConfig.Responder == "http" {
+		return bot.NewHTTPResponder(botConfig.HTTP.URL, botConfig.HTTP.APIKey, botConfig.HTTP.Model, botConfig.HTTP.SystemPrompt)
+	}
+
+	rules := make([]bot.Rule, 0, len(botConfig.Rules))
+	for _, rule := range botConfig.Rules {
+		rules = append(rules, bot.Rule{Keyword: rule.Keyword, Reply: rule.Reply})
+	}
+	return bot.NewRuleResponder(rules, botConfig.Fallback)
+}
+
+// Start 启动回复协程
+func (s *botService) Start() {
+	for _, queue := range s.queues {
+		go func(queue chan *model.Message) {
+			for message := range queue {
+				if err := s.reply(message); err != nil {
+					log.Printf("机器人%d回复消息%d失败: %v", message.ReceiverID, message.ID, err)
+				}
+			}
+		}(queue)
+	}
+}
+
+// HandleMessage 将发给机器人的消息放入队列，不等待回复；队列已满时丢弃
+// 同一用户的消息总是进入同一个队列，保证回复顺序
+func (s *botService) HandleMessage(message *model.Message) {
+	queue := s.queues[message.SenderID%uint(len(s.queues))]
+	select {
+	case queue <- message:
+	default:
+		log.Printf("机器人回复队列已满，丢弃消息%d", message.ID)
+	}
+}
+
+// reply 机器人读取消息并生成回复，生成期间向用户显示输入状态
+func (s *botService) reply(message *model.Message) error {
+	botID, userID := message.ReceiverID, message.SenderID
+	botUser, err := s.userRepo.GetUserByID(botID)
+	if err != nil {
+		return err
+	}
+
+	// 机器人收到即已读
+	lastID, err := s.messageRepo.MarkMessagesAsRead(botID, userID, message.ID)
+	if err != nil {
+		return err
+	}
+	if lastID > 0 {
+		s.hub.SendToUser(userID, ws.NewEvent(ws.EventMessageRead, &model.ReadReceiptEvent{
+			ReaderID:          botID,
+			SessionID:         message.SessionID,
+			LastReadMessageID: lastID,
+			ReadAt:            time.Now().UnixMilli(),
+		}))
+	}
+
+	s.typing(message.SessionID, botID, userID, model.TypingActionTyping)
+	content, err := s.generate(botUser, message)
+	s.typing(message.SessionID, botID, userID, model.TypingActionStopped)
+	if err != nil {
+		log.Printf("机器人%d生成回复失败: %v", botID, err)
+		content = botFailureReply
+	}
+	if content == "" {
+		return nil
+	}
+
+	now := time.Now()
+	reply := &model.Message{
+		SessionID:  message.SessionID,
+		SenderID:   botID,
+		ReceiverID: userID,
+		Type:       model.MessageTypeText,
+		Content:    content,
+		Timestamp:  now.UnixMilli(),
+		Status:     "sent",
+		CreatedAt:  now,
+	}
+	if err := s.messageRepo.CreateMessage(reply); err != nil {
+		return err
+	}
+
+	s.hub.SendToUser(userID, ws.NewEvent(ws.EventMessageNew, toChatMessage(reply, userID)))
+	if !s.hub.IsOnline(userID) {
+		s.notifier.Notify(&model.Notification{
+			UserID:    userID,
+			Kind:      model.NotificationKindMessage,
+			SenderID:  botID,
+			SessionID: reply.SessionID,
+			MessageID: reply.ID,
+			Preview:   contentPreview(reply),
+		})
+	}
+	return nil
+}
+
+// generate 以会话中最近的消息为上下文调用回复器
+func (s *botService) generate(botUser *model.User, message *model.Message) (string, error) {
+	historySize := config.AppConfig.Bot.HistorySize
+	var history []*model.Message
+	if historySize > 0 {
+		var err error
+		history, err = s.messageRepo.GetChatHistory(message.SenderID, botUser.ID, &model.ChatHistoryQuery{
+			Before: message.ID,
+			Limit:  historySize,
+		})
+		if err != nil {
+			return "", err
+		}
+		// 仓库多返回的一条在开头
+		if len(history) > historySize {
+			history = history[1:]
+		}
+	}
+
+	turns := make([]model.BotTurn, 0, len(history))
+	for _, previous := range history {
+		if previous.Recalled {
+			continue
+		}
+		role := model.BotRoleUser
+		if previous.SenderID == botUser.ID {
+			role = model.BotRoleAssistant
+		}
+		turns = append(turns, model.BotTurn{Role: role, Content: contentPreview(previous)})
+	}
+
+	timeout := config.AppConfig.Bot.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return s.responder.Reply(ctx, &model.BotRequest{
+		BotID:   botUser.ID,
+		BotName: botUser.Nickname,
+		UserID:  message.SenderID,
+		Content: contentPreview(message),
+		History: turns,
+	})
+}
+
+// typing 向用户推送机器人的输入状态
+func (s *botService) typing(sessionID string, botID, userID uint, action string) {
+	s.hub.SendToUser(userID, ws.NewEvent(ws.EventChatTyping, &model.TypingEvent{
+		SessionID: sessionID,
+		UserID:    botID,
+		Action:    action,
+	}))
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/repository"
+	"time"
+)
+
+// exportBatchSize 导出时每批读取的消息数量
+const exportBatchSize = 500
+
+// exportTimeLayout 文本和HTML记录中的时间格式
+const exportTimeLayout = "2006-01-02 15:04:05"
+
+// ErrInvalidExportFormat 不支持的导出格式
+var ErrInvalidExportFormat = errors.New("不支持的导出格式，可选json、txt、html")
+
+// mediaMessageTypes Content为媒体文件地址的消息类型
+var mediaMessageTypes = map[string]bool{
+	model.MessageTypeVoice:   true,
+	model.MessageTypeImage:   true,
+	model.MessageTypeVideo:   true,
+	model.MessageTypeFile:    true,
+	model.MessageTypeSticker: true,
+}
+
+// ChatExport 流式导出的聊天记录
+// 调用方设置好响应头后反复调用Next写入，直到返回false，整个会话的消息不会一次性加载到内存
+type ChatExport struct {
+	FileName    string
+	ContentType string
+
+	messageRepo repository.MessageRepository
+	header      *model.ExportHeader
+	transcript  transcriptWriter
+	baseURL     string
+	afterID     uint
+	started     bool
+}
+
+// newChatExport 创建会话的导出，since为用户删除会话时的最后一条消息ID，之前的消息不导出
+// baseURL用于把上传文件的相对地址补全为可直接访问的链接
+func newChatExport(messageRepo repository.MessageRepository, header *model.ExportHeader, format, baseURL string, since uint) (*ChatExport, error) {
+	var transcript transcriptWriter
+	var contentType string
+	switch format {
+	case model.ExportFormatJSON:
+		transcript, contentType = &jsonTranscript{}, "application/json; charset=utf-8"
+	case model.ExportFormatText:
+		transcript, contentType = &textTranscript{}, "text/plain; charset=utf-8"
+	case model.ExportFormatHTML:
+		transcript, contentType = &htmlTranscript{}, "text/html; charset=utf-8"
+	default:
+		return nil, ErrInvalidExportFormat
+	}
+
+	return &ChatExport{
+		FileName:    fmt.Sprintf("%s_%s.%s", header.SessionID, time.UnixMilli(header.ExportedAt).Format("20060102150405"), format),
+		ContentType: contentType,
+		messageRepo: messageRepo,
+		header:      header,
+		transcript:  transcript,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		afterID:     since,
+	}, nil
+}
+
+// Next 写入下一段内容：第一次调用写入会话信息，之后每次写入一批消息，写完结尾后返回false
+func (e *ChatExport) Next(w io.Writer) (bool, error) {
+	if !e.started {
+		e.started = true
+		return true, e.transcript.begin(w, e.header)
+	}
+
+	messages, err := e.messageRepo.GetSessionMessages(e.header.SessionID, e.afterID, exportBatchSize)
+	if err != nil {
+		return false, err
+	}
+	media, err := e.loadMedia(messages)
+	if err != nil {
+		return false, err
+	}
+	for _, message := range messages {
+		if err := e.transcript.message(w, e.toExportMessage(message, media)); err != nil {
+			return false, err
+		}
+		e.afterID = message.ID
+	}
+
+	if len(messages) < exportBatchSize {
+		return false, e.transcript.end(w)
+	}
+	return true, nil
+}
+
+// loadMedia 批量获取一批消息引用的上传文件，键为文件地址
+func (e *ChatExport) loadMedia(messages []*model.Message) (map[string]*model.MediaFile, error) {
+	var urls []string
+	for _, message := range messages {
+		if mediaMessageTypes[message.Type] && !message.Recalled && message.Content != "" {
+			urls = append(urls, message.Content)
+		}
+	}
+	files, err := e.messageRepo.GetMediaFiles(urls)
+	if err != nil {
+		return nil, err
+	}
+
+	byURL := make(map[string]*model.MediaFile, len(files))
+	for _, file := range files {
+		byURL[file.URL] = file
+	}
+	return byURL, nil
+}
+
+// toExportMessage 转换导出的消息，媒体消息附带文件链接和上传时的文件名
+func (e *ChatExport) toExportMessage(message *model.Message, media map[string]*model.MediaFile) *model.ExportMessage {
+	exported := &model.ExportMessage{
+		ID:         message.ID,
+		SenderID:   message.SenderID,
+		SenderName: message.Sender.Nickname,
+		Type:       message.Type,
+		Content:    message.Content,
+		Caption:    message.Caption,
+		Duration:   message.Duration,
+		ReplyToID:  message.ReplyToID,
+		Recalled:   message.Recalled,
+		Edited:     message.Edited,
+		Timestamp:  message.Timestamp,
+	}
+	// 撤回前保存的文件、位置和名片等数据不导出
+	if !message.Recalled {
+		exported.Payload = rawPayload(message.Payload)
+	}
+	if !mediaMessageTypes[message.Type] || message.Recalled || message.Content == "" {
+		return exported
+	}
+
+	exported.Media = &model.ExportMedia{URL: message.Content}
+	if strings.HasPrefix(message.Content, "/") {
+		exported.Media.URL = e.baseURL + message.Content
+	}
+	if file, ok := media[message.Content]; ok {
+		exported.Media.FileName = file.FileName
+		exported.Media.FileSize = file.FileSize
+	} else if message.Type == model.MessageTypeFile {
+		var payload model.FilePayload
+		if err := json.Unmarshal([]byte(message.Payload), &payload); err == nil {
+			exported.Media.FileName = payload.FileName
+			exported.Media.FileSize = payload.FileSize
+		}
+	}
+	return exported
+}
+
+// transcriptWriter 按格式写入聊天记录的各个部分
+type transcriptWriter interface {
+	begin(w io.Writer, header *model.ExportHeader) error
+	message(w io.Writer, message *model.ExportMessage) error
+	end(w io.Writer) error
+}
+
+// jsonTranscript JSON格式：{"session": {...}, "messages": [...]}
+type jsonTranscript struct {
+	count int
+}
+
+func (t *jsonTranscript) begin(w io.Writer, header *model.ExportHeader) error {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, `{"session":%s,"messages":[`, data)
+	return err
+}
+
+func (t *jsonTranscript) message(w io.Writer, message *model.ExportMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if t.count > 0 {
+		if _, err := io.WriteString(w, ","); err != nil {
+			return err
+		}
+	}
+	t.count++
+	_, err = w.Write(data)
+	return err
+}
+
+func (t *jsonTranscript) end(w io.Writer) error {
+	_, err := io.WriteString(w, "]}\n")
+	return err
+}
+
+// textTranscript 纯文本格式，每条消息一行
+type textTranscript struct{}
+
+func (t *textTranscript) begin(w io.Writer, header *model.ExportHeader) error {
+	names := make([]string, 0, len(header.Participants))
+	for _, participant := range header.Participants {
+		names = append(names, participant.Nickname)
+	}
+	_, err := fmt.Fprintf(w, "%s\n导出时间: %s\n参与者: %s\n\n",
+		header.Title,
+		time.UnixMilli(header.ExportedAt).Format(exportTimeLayout),
+		strings.Join(names, "、"),
+	)
+	return err
+}
+
+func (t *textTranscript) message(w io.Writer, message *model.ExportMessage) error {
+	_, err := fmt.Fprintf(w, "[%s] %s: %s\n",
+		time.UnixMilli(message.Timestamp).Format(exportTimeLayout),
+		message.SenderName,
+		exportText(message),
+	)
+	return err
+}
+
+func (t *textTranscript) end(w io.Writer) error {
+	return nil
+}
+
+// exportText 消息在纯文本记录中的内容，媒体消息显示类型标签和文件链接
+func exportText(message *model.ExportMessage) string {
+	if message.Recalled {
+		return "[撤回了一条消息]"
+	}
+
+	text := message.Content
+	if label, ok := typeLabels[message.Type]; ok {
+		text = label + " " + message.Content
+	}
+	if message.Media != nil {
+		text = typeLabels[message.Type] + " " + message.Media.URL
+		if message.Media.FileName != "" {
+			text += " (" + message.Media.FileName + ")"
+		}
+	}
+	if message.Caption != "" {
+		text += " " + message.Caption
+	}
+	if message.Edited {
+		text += " (已编辑)"
+	}
+	return text
+}
+
+// htmlTranscript HTML格式，图片直接显示，其他媒体显示为链接
+type htmlTranscript struct{}
+
+// htmlHeaderTemplate HTML记录的页头
+var htmlHeaderTemplate = template.Must(template.New("header").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 800px; margin: 0 auto; padding: 16px; color: #222; }
+.meta { color: #888; font-size: 13px; }
+.message { padding: 8px 0; border-bottom: 1px solid #eee; }
+.sender { font-weight: bold; }
+.time { color: #888; font-size: 12px; margin-left: 8px; }
+.content { margin-top: 4px; white-space: pre-wrap; word-break: break-word; }
+.content img { max-width: 320px; max-height: 320px; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">导出时间: {{.ExportedAt}}　参与者: {{.Participants}}</p>
+`))
+
+// htmlMessageTemplate HTML记录中的一条消息
+var htmlMessageTemplate = template.Must(template.New("message").Parse(`<div class="message" id="m{{.ID}}">
+<span class="sender">{{.SenderName}}</span><span class="time">{{.Time}}</span>
+<div class="content">{{if .Media}}{{if .Image}}<a href="{{.Media.URL}}"><img src="{{.Media.URL}}" alt="{{.Label}}"></a>{{else}}{{.Label}} <a href="{{.Media.URL}}">{{if .Media.FileName}}{{.Media.FileName}}{{else}}{{.Media.URL}}{{end}}</a>{{end}}{{if .Caption}}
+{{.Caption}}{{end}}{{if .Edited}} <span class="meta">(已编辑)</span>{{end}}{{else}}{{.Text}}{{end}}</div>
+</div>
+`))
+
+func (t *htmlTranscript) begin(w io.Writer, header *model.ExportHeader) error {
+	names := make([]string, 0, len(header.Participants))
+	for _, participant := range header.Participants {
+		names = append(names, participant.Nickname)
+	}
+	return htmlHeaderTemplate.Execute(w, map[string]interface{}{
+		"Title":        header.Title,
+		"ExportedAt":   time.UnixMilli(header.ExportedAt).Format(exportTimeLayout),
+		"Participants": strings.Join(names, "、"),
+	})
+}
+
+func (t *htmlTranscript) message(w io.Writer, message *model.ExportMessage) error {
+	return htmlMessageTemplate.Execute(w, map[string]interface{}{
+		"ID":         message.ID,
+		"SenderName": message.SenderName,
+		"Time":       time.UnixMilli(message.Timestamp).Format(exportTimeLayout),
+		"Media":      message.Media,
+		"Image":      message.Type == model.MessageTypeImage || message.Type == model.MessageTypeSticker,
+		"Label":      typeLabels[message.Type],
+		"Caption":    message.Caption,
+		"Edited":     message.Edited,
+		"Text":       exportText(message),
+	})
+}
+
+func (t *htmlTranscript) end(w io.Writer) error {
+	_, err := io.WriteString(w, "</body>\n</html>\n")
+	return err
+}
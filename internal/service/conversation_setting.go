@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
+	"ticktok-service/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// loadConversationSettings 获取用户的所有会话设置，键为会话ID
+func loadConversationSettings(conversationRepo repository.ConversationRepository, userID uint) (map[string]*model.ConversationSetting, error) {
+	settings, err := conversationRepo.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	bySession := make(map[string]*model.ConversationSetting, len(settings))
+	for _, setting := range settings {
+		bySession[setting.SessionID] = setting
+	}
+	return bySession, nil
+}
+
+// conversationSetting 获取用户对会话的设置，没有记录时返回默认设置，单聊时groupID为0，群聊时peerID为0
+func conversationSetting(conversationRepo repository.ConversationRepository, userID, peerID, groupID uint) (*model.ConversationSetting, error) {
+	sessionID := model.SessionIDFor(userID, peerID)
+	if groupID > 0 {
+		sessionID = model.GroupSessionID(groupID)
+	}
+
+	setting, err := conversationRepo.GetSetting(userID, sessionID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		setting = &model.ConversationSetting{
+			UserID:    userID,
+			SessionID: sessionID,
+			PeerID:    peerID,
+			GroupID:   groupID,
+		}
+	}
+	return setting, nil
+}
+
+// clearedMessageID 获取用户删除会话时的最后一条消息ID，没有删除过时返回0
+func clearedMessageID(conversationRepo repository.ConversationRepository, userID uint, sessionID string) (uint, error) {
+	setting, err := conversationRepo.GetSetting(userID, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return setting.ClearedMessageID, nil
+}
+
+// updateConversationSetting 按请求更新会话设置，并同步到用户的其他设备
+// 归档时记录会话当前的最后一条消息并取消置顶，之后有新消息时会话自动回到列表
+func updateConversationSetting(conversationRepo repository.ConversationRepository, hub *ws.Hub, setting *model.ConversationSetting, req *model.UpdateConversationSettingRequest) (*model.ConversationSettingResponse, error) {
+	lastID, err := conversationRepo.GetLastMessageID(setting.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !setting.IsArchived(lastID) {
+		setting.Archived = false
+		setting.ArchivedMessageID = 0
+	}
+
+	now := time.Now()
+	if req.Archived != nil {
+		setting.Archived = *req.Archived
+		setting.ArchivedMessageID = 0
+		if setting.Archived {
+			setting.ArchivedMessageID = lastID
+			setting.PinnedAt = nil
+		}
+	}
+	if req.Pinned != nil {
+		if !*req.Pinned {
+			setting.PinnedAt = nil
+		} else if setting.PinnedAt == nil {
+			setting.PinnedAt = &now
+			setting.Archived = false
+			setting.ArchivedMessageID = 0
+		}
+	}
+	if req.Draft != nil && *req.Draft != setting.Draft {
+		setting.Draft = *req.Draft
+		setting.DraftUpdatedAt = nil
+		if setting.Draft != "" {
+			setting.DraftUpdatedAt = &now
+		}
+	}
+
+	if err := conversationRepo.SaveSetting(setting); err != nil {
+		return nil, err
+	}
+	return conversationChanged(hub, setting), nil
+}
+
+// clearConversation 为用户删除会话，只隐藏自己看到的历史记录，不影响其他参与者
+func clearConversation(conversationRepo repository.ConversationRepository, hub *ws.Hub, setting *model.ConversationSetting) (*model.ConversationSettingResponse, error) {
+	if err := conversationRepo.ClearConversation(setting); err != nil {
+		return nil, err
+	}
+	return conversationChanged(hub, setting), nil
+}
+
+// conversationChanged 向用户的所有设备推送会话设置变更，返回推送的内容
+func conversationChanged(hub *ws.Hub, setting *model.ConversationSetting) *model.ConversationSettingResponse {
+	response := &model.ConversationSettingResponse{
+		SessionID:        setting.SessionID,
+		PeerID:           setting.PeerID,
+		GroupID:          setting.GroupID,
+		Pinned:           setting.PinnedAt != nil,
+		PinnedAt:         unixMilli(setting.PinnedAt),
+		Archived:         setting.Archived,
+		ClearedMessageID: setting.ClearedMessageID,
+		Draft:            setting.Draft,
+		DraftUpdatedAt:   unixMilli(setting.DraftUpdatedAt),
+	}
+	hub.SendToUser(setting.UserID, ws.NewEvent(ws.EventConversationUpdated, response))
+	return response
+}
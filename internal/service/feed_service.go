@@ -0,0 +1,382 @@
+package service
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"ticktok-service/config"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 关注时间线的默认配置
+const (
+	defaultBigAccountFollowers = 10000
+	defaultFanoutBatchSize     = 1000
+	defaultFeedBackfillSize    = 20
+)
+
+// 发布内容通过收件箱分发；轮播内容和博客由外部导入、没有发布入口，不写收件箱，读取时从关注的所有人的作品中拉取
+var (
+	inboxPostTypes  = []string{model.PostTypeContent}
+	pulledPostTypes = []string{model.PostTypeSlide, model.PostTypeBlog}
+)
+
+// FeedService 关注时间线服务接口
+// 粉丝数未达到阈值的普通账号发布内容时写入每个粉丝的收件箱（写扩散），
+// 大账号的发布内容以及所有关注的人的轮播内容和博客在读取时从作品表拉取（读扩散），按发布时间合并
+type FeedService interface {
+	GetFollowingFeed(userID uint, before int64, pageSize int) (*model.FeedResponse, error)
+	Publish(authorID uint, postType, postID, visibility string, publishedAt time.Time)
+	BackfillInboxes() (int, error)
+}
+
+// feedService 关注时间线服务实现
+type feedService struct {
+	feedRepo    repository.FeedRepository
+	followRepo  repository.FollowRepository
+	friendRepo  repository.FriendRepository
+	userRepo    repository.UserRepository
+	privacyRepo repository.PrivacyRepository
+	slideRepo   repository.SlideRepository
+	contentRepo repository.ContentRepository
+	blogRepo    repository.BlogRepository
+}
+
+// NewFeedService 创建关注时间线服务
+func NewFeedService(db *gorm.DB) FeedService {
+	return &feedService{
+		feedRepo:    repository.NewFeedRepository(db),
+		followRepo:  repository.NewFollowRepository(db),
+		friendRepo:  repository.NewFriendRepository(db),
+		userRepo:    repository.NewUserRepository(db),
+		privacyRepo: repository.NewPrivacyRepository(db),
+		slideRepo:   repository.NewSlideRepository(db),
+		contentRepo: repository.NewContentRepository(db),
+		blogRepo:    repository.NewBlogRepository(),
+	}
+}
+
+// GetFollowingFeed 获取关注的人发布的作品，按发布时间倒序，before为上一页返回的nextCursor
+// 同一毫秒发布的作品不会被拆到两页，因此单页可能略多于pageSize；仅好友可见的内容只对好友展示，单页也可能略少于pageSize
+func (s *feedService) GetFollowingFeed(userID uint, before int64, pageSize int) (*model.FeedResponse, error) {
+	bigIDs, err := s.followRepo.GetBigFollowingIDs(userID, bigAccountFollowers())
+	if err != nil {
+		return nil, err
+	}
+
+	query := &model.FeedQuery{Limit: pageSize + 1}
+	if before > 0 {
+		query.Before = time.UnixMilli(before)
+	}
+	entries, err := s.collect(userID, bigIDs, query)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(entries) > pageSize
+	if hasMore {
+		boundary := entries[pageSize-1].PublishedAt
+		split := entries[pageSize].PublishedAt.Equal(boundary)
+		entries = entries[:pageSize]
+		if split {
+			// 补齐与本页最后一条同一时刻发布的全部作品，下一页从该时刻之前开始
+			for len(entries) > 0 && entries[len(entries)-1].PublishedAt.Equal(boundary) {
+				entries = entries[:len(entries)-1]
+			}
+			tied, err := s.collect(userID, bigIDs, &model.FeedQuery{At: boundary})
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, tied...)
+		}
+	}
+
+	list, err := s.hydrate(userID, entries)
+	if err != nil {
+		return nil, err
+	}
+	response := &model.FeedResponse{
+		List:    list,
+		HasMore: hasMore,
+	}
+	if len(entries) > 0 {
+		response.NextCursor = entries[len(entries)-1].PublishedAt.UnixMilli()
+	}
+	return response, nil
+}
+
+// collect 合并收件箱、大账号的发布内容和关注的人的轮播内容与博客，去重后按发布时间倒序
+func (s *feedService) collect(userID uint, bigIDs []uint, query *model.FeedQuery) ([]*model.FeedItem, error) {
+	inbox, err := s.feedRepo.GetInbox(userID, query)
+	if err != nil {
+		return nil, err
+	}
+	pulled, err := s.feedRepo.GetAuthorPosts(bigIDs, inboxPostTypes, query)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.feedRepo.GetFollowingPosts(userID, pulledPostTypes, query)
+	if err != nil {
+		return nil, err
+	}
+	pulled = append(pulled, posts...)
+
+	// 账号粉丝数刚越过阈值时，之前写入收件箱的作品也会被拉取到
+	seen := make(map[string]bool, len(inbox)+len(pulled))
+	entries := make([]*model.FeedItem, 0, len(inbox)+len(pulled))
+	for _, entry := range append(inbox, pulled...) {
+		if seen[entry.Key()] {
+			continue
+		}
+		seen[entry.Key()] = true
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].PublishedAt.Equal(entries[j].PublishedAt) {
+			return entries[i].PublishedAt.After(entries[j].PublishedAt)
+		}
+		return entries[i].Key() > entries[j].Key()
+	})
+
+	if query.At.IsZero() && len(entries) > query.Limit {
+		entries = entries[:query.Limit]
+	}
+	return entries, nil
+}
+
+// hydrate 批量加载时间线条目对应的作品和作者，已删除的作品和无权查看的内容会被跳过
+func (s *feedService) hydrate(userID uint, entries []*model.FeedItem) ([]*model.FeedItemResponse, error) {
+	var slideIDs, contentIDs []string
+	var blogIDs, authorIDs []uint
+	for _, entry := range entries {
+		authorIDs = append(authorIDs, entry.AuthorID)
+		switch entry.PostType {
+		case model.PostTypeSlide:
+			slideIDs = append(slideIDs, entry.PostID)
+		case model.PostTypeContent:
+			contentIDs = append(contentIDs, entry.PostID)
+		case model.PostTypeBlog:
+			if id, err := strconv.ParseUint(entry.PostID, 10, 32); err == nil {
+				blogIDs = append(blogIDs, uint(id))
+			}
+		}
+	}
+
+	authors, err := s.userRepo.GetUsersByIDs(authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	authorMap := make(map[uint]*model.User, len(authors))
+	for _, author := range authors {
+		authorMap[author.ID] = author
+	}
+	friends, err := s.friendRepo.GetFriendTypes(userID, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	privacySettings, err := s.privacyRepo.GetSettings(authorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	slides, err := s.slideRepo.GetSlideItemsByItemIDs(slideIDs)
+	if err != nil {
+		return nil, err
+	}
+	slideMap := make(map[string]*model.SlideItem, len(slides))
+	for _, slide := range slides {
+		slideMap[slide.ItemID] = slide
+	}
+	contents, err := s.contentRepo.GetContentsByIDs(contentIDs)
+	if err != nil {
+		return nil, err
+	}
+	contentMap := make(map[string]*model.Content, len(contents))
+	for _, content := range contents {
+		contentMap[content.ID] = content
+	}
+	blogs, err := s.blogRepo.GetBlogsByIDs(blogIDs)
+	if err != nil {
+		return nil, err
+	}
+	blogMap := make(map[string]*model.Blog, len(blogs))
+	for i := range blogs {
+		blogs[i].IsFollowing = true
+		blogMap[strconv.FormatUint(uint64(blogs[i].ID), 10)] = &blogs[i]
+	}
+
+	list := make([]*model.FeedItemResponse, 0, len(entries))
+	for _, entry := range entries {
+		author, ok := authorMap[entry.AuthorID]
+		if !ok {
+			continue
+		}
+		_, isFriend := friends[entry.AuthorID]
+		item := &model.FeedItemResponse{
+			PostType:    entry.PostType,
+			PostID:      entry.PostID,
+			Author:      toUserResponse(author),
+			PublishedAt: entry.PublishedAt.UnixMilli(),
+		}
+		applyUserPrivacy(item.Author, privacySettings[entry.AuthorID], userID, isFriend)
+		switch entry.PostType {
+		case model.PostTypeSlide:
+			slide, ok := slideMap[entry.PostID]
+			if !ok {
+				continue
+			}
+			item.Slide = convertToSlideItemResponse(slide)
+			item.Slide.IsFollowing = true
+		case model.PostTypeContent:
+			content, ok := contentMap[entry.PostID]
+			if !ok || content.Visibility == model.VisibilityPrivate {
+				continue
+			}
+			if content.Visibility == model.VisibilityFriends && !isFriend {
+				continue
+			}
+			item.Content = toContentResponse(content)
+		case model.PostTypeBlog:
+			blog, ok := blogMap[entry.PostID]
+			if !ok {
+				continue
+			}
+			item.Blog = blog
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// Publish 把新发布的作品分发到粉丝的收件箱，大账号和仅自己可见的内容不分发
+// 在后台分批写入，调用方无需等待
+func (s *feedService) Publish(authorID uint, postType, postID, visibility string, publishedAt time.Time) {
+	if visibility == model.VisibilityPrivate {
+		return
+	}
+	go s.fanOut(&model.FeedItem{
+		AuthorID:    authorID,
+		PostType:    postType,
+		PostID:      postID,
+		PublishedAt: publishedAt,
+	}, visibility == model.VisibilityFriends)
+}
+
+// fanOut 分批写入粉丝的收件箱，friendsOnly为true时只写入同时是好友的粉丝
+func (s *feedService) fanOut(template *model.FeedItem, friendsOnly bool) {
+	author, err := s.userRepo.GetUserByID(template.AuthorID)
+	if err != nil {
+		log.Printf("分发作品 %s 失败: %v", template.Key(), err)
+		return
+	}
+	if author.FollowerCount >= bigAccountFollowers() {
+		return
+	}
+
+	batchSize := config.AppConfig.Feed.FanoutBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultFanoutBatchSize
+	}
+	now := time.Now()
+	var afterID uint
+	for {
+		followerIDs, err := s.followRepo.GetFollowerIDs(template.AuthorID, afterID, batchSize)
+		if err != nil {
+			log.Printf("分发作品 %s 失败: %v", template.Key(), err)
+			return
+		}
+		if len(followerIDs) == 0 {
+			return
+		}
+		afterID = followerIDs[len(followerIDs)-1]
+
+		var friends map[uint]string
+		if friendsOnly {
+			if friends, err = s.friendRepo.GetFriendTypes(template.AuthorID, followerIDs); err != nil {
+				log.Printf("分发作品 %s 失败: %v", template.Key(), err)
+				return
+			}
+		}
+		items := make([]*model.FeedItem, 0, len(followerIDs))
+		for _, followerID := range followerIDs {
+			if _, isFriend := friends[followerID]; friendsOnly && !isFriend {
+				continue
+			}
+			item := *template
+			item.UserID = followerID
+			item.CreatedAt = now
+			items = append(items, &item)
+		}
+		if err := s.feedRepo.AddFeedItems(items); err != nil {
+			log.Printf("分发作品 %s 失败: %v", template.Key(), err)
+			return
+		}
+
+		if len(followerIDs) < batchSize {
+			return
+		}
+	}
+}
+
+// BackfillInboxes 为现有的全部关注关系补齐收件箱，返回处理的关注关系数，用于启用关注时间线前的数据迁移
+func (s *feedService) BackfillInboxes() (int, error) {
+	batchSize := config.AppConfig.Feed.FanoutBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultFanoutBatchSize
+	}
+
+	var afterID uint
+	processed := 0
+	for {
+		follows, err := s.followRepo.GetFollowsAfter(afterID, batchSize)
+		if err != nil {
+			return processed, err
+		}
+		for _, follow := range follows {
+			if err := backfillInbox(s.feedRepo, follow.UserID, &follow.Following); err != nil {
+				return processed, err
+			}
+			afterID = follow.ID
+			processed++
+		}
+		if len(follows) < batchSize {
+			return processed, nil
+		}
+	}
+}
+
+// backfillInbox 关注普通账号后把对方最近的作品补入收件箱，大账号的作品读取时拉取，无需补入
+// 仅好友可见的内容在读取时按好友关系过滤
+func backfillInbox(feedRepo repository.FeedRepository, userID uint, author *model.User) error {
+	if author.FollowerCount >= bigAccountFollowers() {
+		return nil
+	}
+
+	size := config.AppConfig.Feed.BackfillSize
+	if size <= 0 {
+		size = defaultFeedBackfillSize
+	}
+	posts, err := feedRepo.GetAuthorPosts([]uint{author.ID}, inboxPostTypes, &model.FeedQuery{Limit: size})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, post := range posts {
+		post.UserID = userID
+		post.CreatedAt = now
+	}
+	return feedRepo.AddFeedItems(posts)
+}
+
+// bigAccountFollowers 粉丝数达到该值的账号视为大账号
+func bigAccountFollowers() int64 {
+	if config.AppConfig.Feed.BigAccountFollowers > 0 {
+		return config.AppConfig.Feed.BigAccountFollowers
+	}
+	return defaultBigAccountFollowers
+}
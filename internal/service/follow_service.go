@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// ErrCannotFollowSelf 不能关注自己
+var ErrCannotFollowSelf = errors.New("不能关注自己")
+
+// FollowService 关注服务接口
+type FollowService interface {
+	Follow(userID, targetID uint) (*model.FollowStatusResponse, error)
+	Unfollow(userID, targetID uint) (*model.FollowStatusResponse, error)
+	GetFollowStatus(userID, targetID uint) (*model.FollowStatusResponse, error)
+	GetFollowers(viewerID, userID uint, page, pageSize int) ([]*model.FollowUserResponse, int64, error)
+	GetFollowing(viewerID, userID uint, page, pageSize int) ([]*model.FollowUserResponse, int64, error)
+}
+
+// followService 关注服务实现
+type followService struct {
+	followRepo repository.FollowRepository
+	feedRepo   repository.FeedRepository
+	userRepo   repository.UserRepository
+	blockRepo  repository.BlockRepository
+}
+
+// NewFollowService 创建关注服务
+func NewFollowService(db *gorm.DB) FollowService {
+	return &followService{
+		followRepo: repository.NewFollowRepository(db),
+		feedRepo:   repository.NewFeedRepository(db),
+		userRepo:   repository.NewUserRepository(db),
+		blockRepo:  repository.NewBlockRepository(db),
+	}
+}
+
+// Follow 关注用户，重复关注不报错，与对方存在屏蔽关系时不能关注
+// 对方是普通账号时把其最近的作品补入关注时间线的收件箱
+func (s *followService) Follow(userID, targetID uint) (*model.FollowStatusResponse, error) {
+	if userID == targetID {
+		return nil, ErrCannotFollowSelf
+	}
+	target, err := s.getUser(targetID)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.blockRepo.IsBlockedEither(userID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrUserBlocked
+	}
+
+	if err := s.followRepo.Follow(userID, targetID); err != nil {
+		return nil, err
+	}
+	if err := backfillInbox(s.feedRepo, userID, target); err != nil {
+		return nil, err
+	}
+	return s.GetFollowStatus(userID, targetID)
+}
+
+// Unfollow 取消关注，未关注时不报错
+func (s *followService) Unfollow(userID, targetID uint) (*model.FollowStatusResponse, error) {
+	if err := s.followRepo.Unfollow(userID, targetID); err != nil {
+		return nil, err
+	}
+	return s.GetFollowStatus(userID, targetID)
+}
+
+// GetFollowStatus 获取当前用户与目标用户的关注关系，以及目标用户的粉丝数和关注数
+func (s *followService) GetFollowStatus(userID, targetID uint) (*model.FollowStatusResponse, error) {
+	target, err := s.getUser(targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &model.FollowStatusResponse{
+		UserID:         targetID,
+		FollowerCount:  target.FollowerCount,
+		FollowingCount: target.FollowingCount,
+	}
+	if userID == targetID {
+		return status, nil
+	}
+	if status.IsFollowing, err = s.followRepo.IsFollowing(userID, targetID); err != nil {
+		return nil, err
+	}
+	if status.IsFollowedBy, err = s.followRepo.IsFollowing(targetID, userID); err != nil {
+		return nil, err
+	}
+	status.IsMutual = status.IsFollowing && status.IsFollowedBy
+	return status, nil
+}
+
+// GetFollowers 分页获取用户的粉丝，viewerID为0表示游客
+func (s *followService) GetFollowers(viewerID, userID uint, page, pageSize int) ([]*model.FollowUserResponse, int64, error) {
+	if _, err := s.getUser(userID); err != nil {
+		return nil, 0, err
+	}
+	follows, total, err := s.followRepo.GetFollowers(userID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]*model.User, 0, len(follows))
+	for _, follow := range follows {
+		users = append(users, &follow.Follower)
+	}
+	responses, err := s.toFollowUserResponses(viewerID, follows, users)
+	return responses, total, err
+}
+
+// GetFollowing 分页获取用户关注的人，viewerID为0表示游客
+func (s *followService) GetFollowing(viewerID, userID uint, page, pageSize int) ([]*model.FollowUserResponse, int64, error) {
+	if _, err := s.getUser(userID); err != nil {
+		return nil, 0, err
+	}
+	follows, total, err := s.followRepo.GetFollowing(userID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]*model.User, 0, len(follows))
+	for _, follow := range follows {
+		users = append(users, &follow.Following)
+	}
+	responses, err := s.toFollowUserResponses(viewerID, follows, users)
+	return responses, total, err
+}
+
+// toFollowUserResponses 转换关注列表，批量查询当前用户与列表中各用户的关注关系
+// follows与users一一对应，users为列表中展示的一方
+func (s *followService) toFollowUserResponses(viewerID uint, follows []*model.UserFollow, users []*model.User) ([]*model.FollowUserResponse, error) {
+	ids := make([]uint, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	following, err := followingAuthors(s.followRepo, viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+	followedBy := make(map[uint]bool)
+	if viewerID > 0 {
+		if followedBy, err = s.followRepo.GetFollowerSet(viewerID, ids); err != nil {
+			return nil, err
+		}
+	}
+
+	responses := make([]*model.FollowUserResponse, 0, len(users))
+	for i, user := range users {
+		responses = append(responses, &model.FollowUserResponse{
+			ID:           user.ID,
+			Nickname:     user.Nickname,
+			Avatar:       user.Avatar,
+			Signature:    user.Signature,
+			IsFollowing:  following[user.ID],
+			IsFollowedBy: followedBy[user.ID],
+			IsMutual:     following[user.ID] && followedBy[user.ID],
+			FollowedAt:   follows[i].CreatedAt.UnixMilli(),
+		})
+	}
+	return responses, nil
+}
+
+// getUser 获取用户，不存在时返回ErrUserNotFound
+func (s *followService) getUser(userID uint) (*model.User, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// followingAuthors 获取viewerID关注了authorIDs中的哪些用户，游客返回空集合
+func followingAuthors(followRepo repository.FollowRepository, viewerID uint, authorIDs []uint) (map[uint]bool, error) {
+	if viewerID == 0 {
+		return map[uint]bool{}, nil
+	}
+	return followRepo.GetFollowingSet(viewerID, authorIDs)
+}
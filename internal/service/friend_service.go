@@ -1,52 +1,362 @@
 package service
 
 import (
+	"errors"
 	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
 	"ticktok-service/internal/repository"
 
 	"gorm.io/gorm"
 )
 
+// 好友相关错误
+var (
+	ErrUserNotFound          = errors.New("用户不存在")
+	ErrCannotAddSelf         = errors.New("不能添加自己为好友")
+	ErrAlreadyFriends        = errors.New("已经是好友")
+	ErrNotFriends            = errors.New("对方不是你的好友")
+	ErrFriendRequestExists   = errors.New("已发送过好友申请，请等待对方处理")
+	ErrFriendRequestNotFound = errors.New("好友申请不存在或已处理")
+)
+
 // FriendService 好友服务接口
 type FriendService interface {
 	GetFriendsByUserID(userID uint) ([]*model.FriendResponse, error)
+	GetUserFriends(viewerID, userID uint) ([]*model.FriendResponse, error)
+	SendFriendRequest(userID uint, req *model.SendFriendRequestRequest) (*model.FriendRequestResponse, error)
+	GetReceivedRequests(userID uint) ([]*model.FriendRequestResponse, error)
+	GetSentRequests(userID uint) ([]*model.FriendRequestResponse, error)
+	AcceptFriendRequest(userID, requestID uint) error
+	RejectFriendRequest(userID, requestID uint) error
+	CancelFriendRequest(userID, requestID uint) error
+	DeleteFriend(userID, friendID uint) error
 }
 
 // friendService 好友服务实现
 type friendService struct {
-	friendRepo repository.FriendRepository
+	friendRepo  repository.FriendRepository
+	userRepo    repository.UserRepository
+	blockRepo   repository.BlockRepository
+	privacyRepo repository.PrivacyRepository
+	presence    PresenceService
+	notifier    NotificationService
+	hub         *ws.Hub
 }
 
 // NewFriendService 创建好友服务
-func NewFriendService(db *gorm.DB) FriendService {
+func NewFriendService(db *gorm.DB, hub *ws.Hub, presence PresenceService, notifier NotificationService) FriendService {
 	return &friendService{
-		friendRepo: repository.NewFriendRepository(db),
+		friendRepo:  repository.NewFriendRepository(db),
+		userRepo:    repository.NewUserRepository(db),
+		blockRepo:   repository.NewBlockRepository(db),
+		privacyRepo: repository.NewPrivacyRepository(db),
+		presence:    presence,
+		notifier:    notifier,
+		hub:         hub,
 	}
 }
 
-// GetFriendsByUserID 获取用户的好友列表
+// GetFriendsByUserID 获取用户的好友列表，已屏蔽的好友不会出现在列表中
 func (s *friendService) GetFriendsByUserID(userID uint) ([]*model.FriendResponse, error) {
+	return s.listFriends(userID, userID)
+}
+
+// GetUserFriends 查看其他用户的好友列表，按对方的隐私设置判断是否可见，viewerID为0表示游客
+func (s *friendService) GetUserFriends(viewerID, userID uint) ([]*model.FriendResponse, error) {
+	if viewerID == userID {
+		return s.GetFriendsByUserID(userID)
+	}
+	if _, err := s.userRepo.GetUserByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	
+	isFriend := false
+	if viewerID > 0 {
+		blocked, err := s.blockRepo.IsBlockedEither(viewerID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, ErrUserBlocked
+		}
+		if isFriend, err = s.friendRepo.IsFriend(viewerID, userID); err != nil {
+			return nil, err
+		}
+	}
+	
+	setting, err := s.privacyRepo.GetSetting(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !friendListVisible(setting, viewerID, isFriend) {
+		return nil, ErrFriendListHidden
+	}
+	return s.listFriends(userID, viewerID)
+}
+
+// listFriends 获取用户的好友列表，好友的在线状态按各自的隐私设置对查看者显示
+func (s *friendService) listFriends(userID, viewerID uint) ([]*model.FriendResponse, error) {
 	friendships, err := s.friendRepo.GetFriendsByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
 	
-	var friendResponses []*model.FriendResponse
+	blockedIDs, err := s.blockRepo.GetBlockedIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	blocked := make(map[uint]bool, len(blockedIDs))
+	for _, id := range blockedIDs {
+		blocked[id] = true
+	}
+	
+	var visible []*model.Friendship
+	var friendIDs []uint
 	for _, friendship := range friendships {
+		if blocked[friendship.FriendID] {
+			continue
+		}
+		visible = append(visible, friendship)
+		friendIDs = append(friendIDs, friendship.FriendID)
+	}
+	
+	// 批量获取好友在线状态
+	presences, err := s.presence.GetPresence(viewerID, friendIDs)
+	if err != nil {
+		return nil, err
+	}
+	presenceByID := make(map[uint]*model.PresenceResponse, len(presences))
+	for _, presence := range presences {
+		presenceByID[presence.UserID] = presence
+	}
+	
+	var friendResponses []*model.FriendResponse
+	for _, friendship := range visible {
 		friend := friendship.Friend
-		isOnline := friend.Status == "online"
-		isOfficial := friendship.FriendType == "aibot" || friendship.FriendType == "system"
+		friendType := peerFriendType(&friend, friendship.FriendType)
 		
-		friendResponses = append(friendResponses, &model.FriendResponse{
+		response := &model.FriendResponse{
 			ID:         friend.ID,
 			Name:       friend.Nickname,
 			Avatar:     friend.Avatar,
-			Online:     isOnline,
-			IsOfficial: isOfficial,
-			LastActive: friend.LastSeen,
-			FriendType: friendship.FriendType,
-		})
+			IsOfficial: friendType != model.FriendTypeNormal,
+			FriendType: friendType,
+		}
+		if presence, ok := presenceByID[friend.ID]; ok {
+			response.Online = presence.Online
+			response.LastActive = presence.LastSeen
+		}
+		friendResponses = append(friendResponses, response)
 	}
 	
 	return friendResponses, nil
-} 
\ No newline at end of file
+}
+
+// SendFriendRequest 发送好友申请
+// 如果对方已向自己发出待处理的申请，则直接通过对方的申请
+func (s *friendService) SendFriendRequest(userID uint, req *model.SendFriendRequestRequest) (*model.FriendRequestResponse, error) {
+	if req.UserID == userID {
+		return nil, ErrCannotAddSelf
+	}
+	if _, err := s.userRepo.GetUserByID(req.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	blocked, err := s.blockRepo.IsBlockedEither(userID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrUserBlocked
+	}
+
+	isFriend, err := s.friendRepo.IsFriend(userID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if isFriend {
+		return nil, ErrAlreadyFriends
+	}
+
+	if _, err := s.friendRepo.GetPendingRequest(userID, req.UserID); err == nil {
+		return nil, ErrFriendRequestExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// 对方已发出申请，直接互加好友
+	if incoming, err := s.friendRepo.GetPendingRequest(req.UserID, userID); err == nil {
+		if err := s.accept(incoming); err != nil {
+			return nil, err
+		}
+		incoming.Status = model.FriendRequestAccepted
+		return toFriendRequestResponse(incoming), nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	request := &model.FriendRequest{
+		FromUserID: userID,
+		ToUserID:   req.UserID,
+		Message:    req.Message,
+		Status:     model.FriendRequestPending,
+	}
+	if err := s.friendRepo.CreateFriendRequest(request); err != nil {
+		return nil, err
+	}
+
+	request, err = s.friendRepo.GetFriendRequestByID(request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toFriendRequestResponse(request)
+	s.hub.SendToUser(request.ToUserID, ws.NewEvent(ws.EventFriendRequest, response))
+	if !s.hub.IsOnline(request.ToUserID) {
+		s.notifier.Notify(&model.Notification{
+			UserID:   request.ToUserID,
+			Kind:     model.NotificationKindFriendRequest,
+			SenderID: userID,
+		})
+	}
+
+	return response, nil
+}
+
+// GetReceivedRequests 获取收到的待处理好友申请
+func (s *friendService) GetReceivedRequests(userID uint) ([]*model.FriendRequestResponse, error) {
+	requests, err := s.friendRepo.GetReceivedRequests(userID)
+	if err != nil {
+		return nil, err
+	}
+	return toFriendRequestResponses(requests), nil
+}
+
+// GetSentRequests 获取发出的待处理好友申请
+func (s *friendService) GetSentRequests(userID uint) ([]*model.FriendRequestResponse, error) {
+	requests, err := s.friendRepo.GetSentRequests(userID)
+	if err != nil {
+		return nil, err
+	}
+	return toFriendRequestResponses(requests), nil
+}
+
+// AcceptFriendRequest 通过好友申请，仅申请的接收者可操作
+func (s *friendService) AcceptFriendRequest(userID, requestID uint) error {
+	request, err := s.getPendingRequest(requestID)
+	if err != nil {
+		return err
+	}
+	if request.ToUserID != userID {
+		return ErrFriendRequestNotFound
+	}
+
+	return s.accept(request)
+}
+
+// RejectFriendRequest 拒绝好友申请，仅申请的接收者可操作
+func (s *friendService) RejectFriendRequest(userID, requestID uint) error {
+	request, err := s.getPendingRequest(requestID)
+	if err != nil {
+		return err
+	}
+	if request.ToUserID != userID {
+		return ErrFriendRequestNotFound
+	}
+
+	if err := s.friendRepo.UpdateFriendRequestStatus(request.ID, model.FriendRequestRejected); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrFriendRequestNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// CancelFriendRequest 撤销好友申请，仅申请的发起者可操作
+func (s *friendService) CancelFriendRequest(userID, requestID uint) error {
+	request, err := s.getPendingRequest(requestID)
+	if err != nil {
+		return err
+	}
+	if request.FromUserID != userID {
+		return ErrFriendRequestNotFound
+	}
+
+	if err := s.friendRepo.UpdateFriendRequestStatus(request.ID, model.FriendRequestCancelled); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrFriendRequestNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteFriend 删除好友，双方的好友关系同时解除
+func (s *friendService) DeleteFriend(userID, friendID uint) error {
+	isFriend, err := s.friendRepo.IsFriend(userID, friendID)
+	if err != nil {
+		return err
+	}
+	if !isFriend {
+		return ErrNotFriends
+	}
+
+	return s.friendRepo.DeleteFriendship(userID, friendID)
+}
+
+// accept 通过申请并通知申请发起者，申请已被并发处理时返回ErrFriendRequestNotFound
+func (s *friendService) accept(request *model.FriendRequest) error {
+	if err := s.friendRepo.AcceptFriendRequest(request); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrFriendRequestNotFound
+		}
+		return err
+	}
+
+	request.Status = model.FriendRequestAccepted
+	s.hub.SendToUser(request.FromUserID, ws.NewEvent(ws.EventFriendAccepted, toFriendRequestResponse(request)))
+	return nil
+}
+
+// getPendingRequest 获取待处理的好友申请，不存在或已处理时返回ErrFriendRequestNotFound
+func (s *friendService) getPendingRequest(requestID uint) (*model.FriendRequest, error) {
+	request, err := s.friendRepo.GetFriendRequestByID(requestID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFriendRequestNotFound
+		}
+		return nil, err
+	}
+	if request.Status != model.FriendRequestPending {
+		return nil, ErrFriendRequestNotFound
+	}
+	return request, nil
+}
+
+// toFriendRequestResponse 将FriendRequest转换为FriendRequestResponse
+func toFriendRequestResponse(request *model.FriendRequest) *model.FriendRequestResponse {
+	return &model.FriendRequestResponse{
+		ID:        request.ID,
+		FromUser:  toUserResponse(&request.FromUser),
+		ToUser:    toUserResponse(&request.ToUser),
+		Message:   request.Message,
+		Status:    request.Status,
+		CreatedAt: request.CreatedAt.UnixMilli(),
+	}
+}
+
+// toFriendRequestResponses 批量转换好友申请
+func toFriendRequestResponses(requests []*model.FriendRequest) []*model.FriendRequestResponse {
+	responses := make([]*model.FriendRequestResponse, 0, len(requests))
+	for _, request := range requests {
+		responses = append(responses, toFriendRequestResponse(request))
+	}
+	return responses
+}
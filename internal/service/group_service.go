@@ -0,0 +1,451 @@
+package service
+
+import (
+	"errors"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
+	"ticktok-service/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 群聊相关错误
+var (
+	ErrGroupNotFound    = errors.New("群聊不存在")
+	ErrNotGroupMember   = errors.New("不是群成员")
+	ErrGroupPermission  = errors.New("没有操作权限")
+	ErrNoMembersInvited = errors.New("没有可邀请的用户")
+)
+
+// GroupService 群聊服务接口
+type GroupService interface {
+	CreateGroup(ownerID uint, req *model.CreateGroupRequest) (*model.GroupResponse, error)
+	GetGroup(userID, groupID uint) (*model.GroupResponse, error)
+	GetMembers(userID, groupID uint) ([]*model.GroupMemberResponse, error)
+	InviteMembers(userID, groupID uint, userIDs []uint) error
+	LeaveGroup(userID, groupID uint) error
+	KickMember(userID, groupID, targetID uint) error
+	UpdateMemberRole(userID, groupID, targetID uint, role string) error
+	SendGroupMessage(userID, groupID uint, req *model.GroupMessageRequest) (*model.ChatMessage, error)
+	GetGroupChatHistory(userID, groupID uint, query *model.ChatHistoryQuery) (*model.CursorResult, error)
+	MarkGroupAsRead(userID, groupID uint) error
+	GetGroupReadCursors(userID, groupID uint) ([]*model.ReadCursorResponse, error)
+	SetGroupMuted(userID, groupID uint, muted bool) error
+	UpdateGroupSetting(userID, groupID uint, req *model.UpdateConversationSettingRequest) (*model.ConversationSettingResponse, error)
+	DeleteGroupConversation(userID, groupID uint) (*model.ConversationSettingResponse, error)
+	ExportGroupChat(userID, groupID uint, format, baseURL string) (*ChatExport, error)
+}
+
+// groupService 群聊服务实现
+type groupService struct {
+	groupRepo        repository.GroupRepository
+	messageRepo      repository.MessageRepository
+	userRepo         repository.UserRepository
+	friendRepo       repository.FriendRepository
+	blockRepo        repository.BlockRepository
+	conversationRepo repository.ConversationRepository
+	resolver         *payloadResolver
+	hub              *ws.Hub
+	notifier         NotificationService
+}
+
+// NewGroupService 创建群聊服务
+func NewGroupService(db *gorm.DB, hub *ws.Hub, notifier NotificationService) GroupService {
+	return &groupService{
+		groupRepo:        repository.NewGroupRepository(db),
+		messageRepo:      repository.NewMessageRepository(db),
+		userRepo:         repository.NewUserRepository(db),
+		friendRepo:       repository.NewFriendRepository(db),
+		blockRepo:        repository.NewBlockRepository(db),
+		conversationRepo: repository.NewConversationRepository(db),
+		resolver:         newPayloadResolver(db),
+		hub:              hub,
+		notifier:         notifier,
+	}
+}
+
+// CreateGroup 创建群聊，初始成员只能是群主的好友
+func (s *groupService) CreateGroup(ownerID uint, req *model.CreateGroupRequest) (*model.GroupResponse, error) {
+	memberIDs, err := s.invitableUserIDs(ownerID, req.MemberIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &model.ChatGroup{
+		Name:    req.Name,
+		Avatar:  req.Avatar,
+		OwnerID: ownerID,
+	}
+	if err := s.groupRepo.CreateGroup(group, memberIDs); err != nil {
+		return nil, err
+	}
+
+	return s.GetGroup(ownerID, group.ID)
+}
+
+// GetGroup 获取群聊信息
+func (s *groupService) GetGroup(userID, groupID uint) (*model.GroupResponse, error) {
+	group, err := s.getGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.getMember(groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.groupRepo.CountMembers(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.GroupResponse{
+		ID:          group.ID,
+		Name:        group.Name,
+		Avatar:      group.Avatar,
+		OwnerID:     group.OwnerID,
+		MemberCount: int(count),
+		MyRole:      member.Role,
+		SessionID:   model.GroupSessionID(group.ID),
+	}, nil
+}
+
+// GetMembers 获取群成员列表
+func (s *groupService) GetMembers(userID, groupID uint) ([]*model.GroupMemberResponse, error) {
+	if _, err := s.getMember(groupID, userID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.groupRepo.GetMembers(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	memberResponses := make([]*model.GroupMemberResponse, 0, len(members))
+	for _, member := range members {
+		memberResponses = append(memberResponses, &model.GroupMemberResponse{
+			UserID:   member.UserID,
+			Nickname: member.User.Nickname,
+			Avatar:   member.User.Avatar,
+			Role:     member.Role,
+			JoinedAt: member.JoinedAt.UnixMilli(),
+		})
+	}
+
+	return memberResponses, nil
+}
+
+// InviteMembers 邀请用户入群，仅群主和管理员可操作，只能邀请自己的好友
+func (s *groupService) InviteMembers(userID, groupID uint, userIDs []uint) error {
+	member, err := s.getMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role == model.GroupRoleMember {
+		return ErrGroupPermission
+	}
+
+	validIDs, err := s.invitableUserIDs(userID, userIDs)
+	if err != nil {
+		return err
+	}
+	if len(validIDs) == 0 {
+		return ErrNoMembersInvited
+	}
+
+	return s.groupRepo.AddMembers(groupID, validIDs)
+}
+
+// LeaveGroup 退出群聊
+// 群主退出时将群主转让给最早加入的管理员，没有管理员则转给最早加入的成员；最后一人退出时解散群聊
+func (s *groupService) LeaveGroup(userID, groupID uint) error {
+	if _, err := s.getMember(groupID, userID); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.LeaveGroup(groupID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotGroupMember
+		}
+		return err
+	}
+	return nil
+}
+
+// KickMember 移出成员：群主可移出任何人，管理员只能移出普通成员
+func (s *groupService) KickMember(userID, groupID, targetID uint) error {
+	if userID == targetID {
+		return ErrGroupPermission
+	}
+
+	operator, err := s.getMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+	target, err := s.getMember(groupID, targetID)
+	if err != nil {
+		return err
+	}
+
+	if !canManage(operator.Role, target.Role) {
+		return ErrGroupPermission
+	}
+
+	return s.groupRepo.RemoveMember(groupID, targetID)
+}
+
+// UpdateMemberRole 设置管理员或取消管理员，仅群主可操作
+func (s *groupService) UpdateMemberRole(userID, groupID, targetID uint, role string) error {
+	operator, err := s.getMember(groupID, userID)
+	if err != nil {
+		return err
+	}
+	if operator.Role != model.GroupRoleOwner || userID == targetID {
+		return ErrGroupPermission
+	}
+	if _, err := s.getMember(groupID, targetID); err != nil {
+		return err
+	}
+
+	return s.groupRepo.UpdateMemberRole(groupID, targetID, role)
+}
+
+// SendGroupMessage 发送群消息并推送给在线成员
+func (s *groupService) SendGroupMessage(userID, groupID uint, req *model.GroupMessageRequest) (*model.ChatMessage, error) {
+	if _, err := s.getMember(groupID, userID); err != nil {
+		return nil, err
+	}
+
+	// 按消息类型校验内容
+	content, err := s.resolver.resolve(req.Type, req.Content, req.Duration, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// 引用的消息必须属于同一群聊
+	var replyTo *model.Message
+	if req.ReplyToID > 0 {
+		replyTo, err = replyTarget(s.messageRepo, s.userRepo, s.conversationRepo, userID, model.GroupSessionID(groupID), req.ReplyToID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	message := &model.Message{
+		SenderID:  userID,
+		GroupID:   groupID,
+		Type:      req.Type,
+		Content:   content.Content,
+		Payload:   content.Payload,
+		Timestamp: req.Timestamp,
+		Duration:  req.Duration,
+		Caption:   req.Caption,
+		ReplyToID: req.ReplyToID,
+		Status:    "sent",
+	}
+	if err := s.groupRepo.CreateGroupMessage(message); err != nil {
+		return nil, err
+	}
+	message.ReplyTo = replyTo
+
+	// 推送给所有成员的在线设备，没有在线设备的成员发送离线推送
+	memberIDs, err := s.groupRepo.GetMemberIDs(groupID)
+	if err == nil {
+		for _, memberID := range memberIDs {
+			s.hub.SendToUser(memberID, ws.NewEvent(ws.EventMessageNew, toChatMessage(message, memberID)))
+			if memberID != userID && !s.hub.IsOnline(memberID) {
+				s.notifier.Notify(&model.Notification{
+					UserID:    memberID,
+					Kind:      model.NotificationKindGroupMessage,
+					SenderID:  userID,
+					GroupID:   groupID,
+					SessionID: message.SessionID,
+					MessageID: message.ID,
+					Preview:   contentPreview(message),
+				})
+			}
+		}
+	}
+
+	return toChatMessage(message, userID), nil
+}
+
+// GetGroupChatHistory 按游标分页获取群聊历史记录
+func (s *groupService) GetGroupChatHistory(userID, groupID uint, query *model.ChatHistoryQuery) (*model.CursorResult, error) {
+	if _, err := s.getMember(groupID, userID); err != nil {
+		return nil, err
+	}
+
+	// 删除过会话时只返回之后的消息
+	since, err := clearedMessageID(s.conversationRepo, userID, model.GroupSessionID(groupID))
+	if err != nil {
+		return nil, err
+	}
+	query.Since = since
+
+	messages, err := s.groupRepo.GetGroupChatHistory(groupID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	reactions, err := loadReactions(s.messageRepo, messages, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCursorResult(messages, query, userID, reactions), nil
+}
+
+// MarkGroupAsRead 清零群未读数
+func (s *groupService) MarkGroupAsRead(userID, groupID uint) error {
+	if _, err := s.getMember(groupID, userID); err != nil {
+		return err
+	}
+	return s.groupRepo.MarkGroupAsRead(userID, groupID)
+}
+
+// GetGroupReadCursors 获取群成员的阅读游标，只返回标记过已读的成员
+func (s *groupService) GetGroupReadCursors(userID, groupID uint) ([]*model.ReadCursorResponse, error) {
+	if _, err := s.getMember(groupID, userID); err != nil {
+		return nil, err
+	}
+	cursors, err := s.messageRepo.GetReadCursors(model.GroupSessionID(groupID))
+	if err != nil {
+		return nil, err
+	}
+	return toReadCursorResponses(cursors), nil
+}
+
+// SetGroupMuted 开启或关闭群聊免打扰
+func (s *groupService) SetGroupMuted(userID, groupID uint, muted bool) error {
+	if _, err := s.getMember(groupID, userID); err != nil {
+		return err
+	}
+	if muted {
+		return s.blockRepo.Mute(userID, 0, groupID)
+	}
+	return s.blockRepo.Unmute(userID, 0, groupID)
+}
+
+// UpdateGroupSetting 更新群聊会话的置顶、归档和草稿
+func (s *groupService) UpdateGroupSetting(userID, groupID uint, req *model.UpdateConversationSettingRequest) (*model.ConversationSettingResponse, error) {
+	if _, err := s.getMember(groupID, userID); err != nil {
+		return nil, err
+	}
+	setting, err := conversationSetting(s.conversationRepo, userID, 0, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return updateConversationSetting(s.conversationRepo, s.hub, setting, req)
+}
+
+// DeleteGroupConversation 删除群聊会话，只对当前用户隐藏已有的聊天记录，仍保留群成员身份
+func (s *groupService) DeleteGroupConversation(userID, groupID uint) (*model.ConversationSettingResponse, error) {
+	if _, err := s.getMember(groupID, userID); err != nil {
+		return nil, err
+	}
+	setting, err := conversationSetting(s.conversationRepo, userID, 0, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return clearConversation(s.conversationRepo, s.hub, setting)
+}
+
+// ExportGroupChat 导出群聊记录，仅群成员可导出，用户删除会话前的消息不导出
+func (s *groupService) ExportGroupChat(userID, groupID uint, format, baseURL string) (*ChatExport, error) {
+	group, err := s.getGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getMember(groupID, userID); err != nil {
+		return nil, err
+	}
+	members, err := s.groupRepo.GetMembers(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID := model.GroupSessionID(groupID)
+	since, err := clearedMessageID(s.conversationRepo, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	header := &model.ExportHeader{
+		SessionID:    sessionID,
+		Title:        group.Name + " 群聊记录",
+		IsGroup:      true,
+		ExportedBy:   userID,
+		ExportedAt:   time.Now().UnixMilli(),
+		Participants: make([]*model.ExportParticipant, 0, len(members)),
+	}
+	for _, member := range members {
+		header.Participants = append(header.Participants, &model.ExportParticipant{
+			ID:       member.UserID,
+			Nickname: member.User.Nickname,
+		})
+	}
+	return newChatExport(s.messageRepo, header, format, baseURL, since)
+}
+
+// getGroup 获取群聊，不存在时返回ErrGroupNotFound
+func (s *groupService) getGroup(groupID uint) (*model.ChatGroup, error) {
+	group, err := s.groupRepo.GetGroupByID(groupID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, err
+	}
+	return group, nil
+}
+
+// getMember 获取群成员，不是成员时返回ErrNotGroupMember
+func (s *groupService) getMember(groupID, userID uint) (*model.GroupMember, error) {
+	member, err := s.groupRepo.GetMember(groupID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotGroupMember
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// invitableUserIDs 过滤出邀请者可以拉入群聊的用户ID
+// 被邀请者必须是邀请者的好友（已同意过好友申请），且双方不存在屏蔽关系
+func (s *groupService) invitableUserIDs(inviterID uint, ids []uint) ([]uint, error) {
+	friendTypes, err := s.friendRepo.GetFriendTypes(inviterID, ids)
+	if err != nil {
+		return nil, err
+	}
+	blockedIDs, err := s.blockRepo.GetBlockedEitherIDs(inviterID, ids)
+	if err != nil {
+		return nil, err
+	}
+	blocked := make(map[uint]bool, len(blockedIDs))
+	for _, id := range blockedIDs {
+		blocked[id] = true
+	}
+
+	validIDs := make([]uint, 0, len(friendTypes))
+	for _, id := range ids {
+		if _, ok := friendTypes[id]; ok && !blocked[id] {
+			validIDs = append(validIDs, id)
+		}
+	}
+	return validIDs, nil
+}
+
+// canManage 判断operatorRole是否有权管理targetRole的成员
+func canManage(operatorRole, targetRole string) bool {
+	switch operatorRole {
+	case model.GroupRoleOwner:
+		return targetRole != model.GroupRoleOwner
+	case model.GroupRoleAdmin:
+		return targetRole == model.GroupRoleMember
+	default:
+		return false
+	}
+}
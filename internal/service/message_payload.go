@@ -0,0 +1,256 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidMessage 消息内容不符合类型要求
+var ErrInvalidMessage = errors.New("无效的消息内容")
+
+// typeLabels 消息列表中非文本消息的展示文案
+var typeLabels = map[string]string{
+	model.MessageTypeVoice:       "[语音]",
+	model.MessageTypeImage:       "[图片]",
+	model.MessageTypeVideo:       "[视频]",
+	model.MessageTypeFile:        "[文件]",
+	model.MessageTypeLocation:    "[位置]",
+	model.MessageTypeSticker:     "[表情]",
+	model.MessageTypeProductCard: "[商品]",
+	model.MessageTypePostCard:    "[作品]",
+}
+
+// messageContent 校验并规范化后的消息内容
+type messageContent struct {
+	Content string
+	Payload string
+}
+
+// payloadResolver 按消息类型校验内容，并为卡片消息填充引用对象的快照
+type payloadResolver struct {
+	productRepo repository.ProductRepository
+	slideRepo   repository.SlideRepository
+	blogRepo    repository.BlogRepository
+}
+
+// newPayloadResolver 创建消息内容校验器
+func newPayloadResolver(db *gorm.DB) *payloadResolver {
+	return &payloadResolver{
+		productRepo: repository.NewProductRepository(db),
+		slideRepo:   repository.NewSlideRepository(db),
+		blogRepo:    repository.NewBlogRepository(),
+	}
+}
+
+// resolve 校验消息内容，返回写入数据库的Content和Payload
+func (r *payloadResolver) resolve(msgType, content, duration string, raw json.RawMessage) (*messageContent, error) {
+	content = strings.TrimSpace(content)
+
+	switch msgType {
+	case model.MessageTypeText:
+		if content == "" {
+			return nil, invalidMessage("文本消息内容不能为空")
+		}
+		return &messageContent{Content: content}, nil
+
+	case model.MessageTypeVoice:
+		if content == "" || duration == "" {
+			return nil, invalidMessage("语音消息需要音频地址和时长")
+		}
+		return &messageContent{Content: content}, nil
+
+	case model.MessageTypeImage:
+		if content == "" {
+			return nil, invalidMessage("图片消息需要图片地址")
+		}
+		return &messageContent{Content: content}, nil
+
+	case model.MessageTypeVideo:
+		if content == "" {
+			return nil, invalidMessage("视频消息需要视频地址")
+		}
+		var payload model.VideoPayload
+		if err := decodePayload(raw, &payload, false); err != nil {
+			return nil, err
+		}
+		return withPayload(content, payload)
+
+	case model.MessageTypeFile:
+		var payload model.FilePayload
+		if err := decodePayload(raw, &payload, true); err != nil {
+			return nil, err
+		}
+		if content == "" || payload.FileName == "" || payload.FileSize <= 0 {
+			return nil, invalidMessage("文件消息需要文件地址、文件名和大小")
+		}
+		return withPayload(content, payload)
+
+	case model.MessageTypeLocation:
+		var payload model.LocationPayload
+		if err := decodePayload(raw, &payload, true); err != nil {
+			return nil, err
+		}
+		if payload.Latitude < -90 || payload.Latitude > 90 || payload.Longitude < -180 || payload.Longitude > 180 {
+			return nil, invalidMessage("无效的经纬度")
+		}
+		// Content保存可读的位置名称，便于预览和搜索
+		if content == "" {
+			content = payload.Name
+		}
+		if content == "" {
+			content = payload.Address
+		}
+		return withPayload(content, payload)
+
+	case model.MessageTypeSticker:
+		if content == "" {
+			return nil, invalidMessage("表情消息需要表情地址")
+		}
+		var payload model.StickerPayload
+		if err := decodePayload(raw, &payload, false); err != nil {
+			return nil, err
+		}
+		return withPayload(content, payload)
+
+	case model.MessageTypeProductCard:
+		var payload model.ProductCardPayload
+		if err := decodePayload(raw, &payload, true); err != nil {
+			return nil, err
+		}
+		card, err := r.productCard(payload.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		return withPayload(card.Title, card)
+
+	case model.MessageTypePostCard:
+		var payload model.PostCardPayload
+		if err := decodePayload(raw, &payload, true); err != nil {
+			return nil, err
+		}
+		card, err := r.postCard(payload.PostType, payload.PostID)
+		if err != nil {
+			return nil, err
+		}
+		return withPayload(card.Title, card)
+
+	default:
+		return nil, invalidMessage("不支持的消息类型: " + msgType)
+	}
+}
+
+// productCard 根据商品ID生成商品卡片快照
+func (r *payloadResolver) productCard(productID uint) (*model.ProductCardPayload, error) {
+	if productID == 0 {
+		return nil, invalidMessage("商品卡片需要商品ID")
+	}
+
+	product, err := r.productRepo.GetProductByID(productID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidMessage("商品不存在")
+		}
+		return nil, err
+	}
+
+	return &model.ProductCardPayload{
+		ProductID:     product.ID,
+		Title:         product.Title,
+		Image:         product.Image,
+		Price:         fmt.Sprintf("%.2f", product.Price),
+		OriginalPrice: fmt.Sprintf("%.2f", product.OriginalPrice),
+		ShopName:      product.Shop.Name,
+	}, nil
+}
+
+// postCard 根据作品类型和ID生成作品卡片快照
+func (r *payloadResolver) postCard(postType, postID string) (*model.PostCardPayload, error) {
+	if postID == "" {
+		return nil, invalidMessage("作品卡片需要作品ID")
+	}
+
+	switch postType {
+	case model.PostTypeSlide:
+		item, err := r.slideRepo.GetSlideItemByItemID(postID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, invalidMessage("作品不存在")
+			}
+			return nil, err
+		}
+
+		cover := item.Avatar
+		if len(item.Album) > 0 {
+			cover = item.Album[0].ImageURL
+		}
+		return &model.PostCardPayload{
+			PostType:     postType,
+			PostID:       item.ItemID,
+			Title:        item.Title,
+			Cover:        cover,
+			ContentType:  item.ContentType,
+			AuthorName:   item.Author,
+			AuthorAvatar: item.Avatar,
+		}, nil
+
+	case model.PostTypeBlog:
+		id, err := strconv.ParseUint(postID, 10, 32)
+		if err != nil {
+			return nil, invalidMessage("无效的博客ID")
+		}
+		blog, err := r.blogRepo.GetBlogByID(uint(id))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, invalidMessage("作品不存在")
+			}
+			return nil, err
+		}
+
+		return &model.PostCardPayload{
+			PostType:     postType,
+			PostID:       postID,
+			Title:        blog.Title,
+			Cover:        blog.CoverImg,
+			AuthorName:   blog.AuthorName,
+			AuthorAvatar: blog.AuthorAvatar,
+		}, nil
+
+	default:
+		return nil, invalidMessage("作品类型必须是slide或blog")
+	}
+}
+
+// decodePayload 解析请求中的payload，required为true时payload不能为空
+func decodePayload(raw json.RawMessage, v interface{}, required bool) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		if required {
+			return invalidMessage("缺少payload")
+		}
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return invalidMessage("payload格式错误")
+	}
+	return nil
+}
+
+// withPayload 序列化payload并组装消息内容
+func withPayload(content string, payload interface{}) (*messageContent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &messageContent{Content: content, Payload: string(data)}, nil
+}
+
+// invalidMessage 包装消息校验错误
+func invalidMessage(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidMessage, reason)
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"ticktok-service/config"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
+	"ticktok-service/internal/repository"
+	"time"
+)
+
+// defaultAllowedReactions 未配置chat.allowed_reactions时允许使用的表情回应
+var defaultAllowedReactions = []string{"👍", "❤️", "😂", "😮", "😢", "😡", "🙏", "👏", "🔥", "🎉"}
+
+// AddReaction 为消息添加表情回应，返回该消息最新的回应汇总
+func (s *messageService) AddReaction(userID, messageID uint, emoji string) ([]*model.ReactionSummary, error) {
+	if !reactionAllowed(emoji) {
+		return nil, ErrInvalidReaction
+	}
+
+	message, err := s.reactionTarget(userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.messageRepo.AddReaction(&model.MessageReaction{
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.reactionChanged(message, userID, emoji, true)
+}
+
+// RemoveReaction 取消自己对消息的表情回应，返回该消息最新的回应汇总
+func (s *messageService) RemoveReaction(userID, messageID uint, emoji string) ([]*model.ReactionSummary, error) {
+	message, err := s.reactionTarget(userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.messageRepo.RemoveReaction(messageID, userID, emoji); err != nil {
+		return nil, err
+	}
+
+	return s.reactionChanged(message, userID, emoji, false)
+}
+
+// reactionTarget 获取可回应的消息：用户须为会话参与者且未删除会话时清除该消息，消息未撤回，单聊双方没有屏蔽对方
+func (s *messageService) reactionTarget(userID, messageID uint) (*model.Message, error) {
+	message, err := s.getMessage(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isParticipant(message, userID) {
+		return nil, ErrMessageNotFound
+	}
+	since, err := clearedMessageID(s.conversationRepo, userID, message.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if message.ID <= since {
+		return nil, ErrMessageNotFound
+	}
+	if message.Recalled {
+		return nil, ErrMessageRecalled
+	}
+
+	if message.GroupID == 0 {
+		peerID := message.ReceiverID
+		if peerID == userID {
+			peerID = message.SenderID
+		}
+		blocked, err := s.blockRepo.IsBlockedEither(userID, peerID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, ErrUserBlocked
+		}
+	}
+	return message, nil
+}
+
+// reactionChanged 向会话参与者推送回应变更，返回当前用户视角的回应汇总
+func (s *messageService) reactionChanged(message *model.Message, userID uint, emoji string, added bool) ([]*model.ReactionSummary, error) {
+	event := ws.NewEvent(ws.EventMessageReaction, &model.ReactionEvent{
+		MessageID: message.ID,
+		SessionID: message.SessionID,
+		UserID:    userID,
+		Emoji:     emoji,
+		Added:     added,
+	})
+	for _, participantID := range s.participantIDs(message) {
+		s.hub.SendToUser(participantID, event)
+	}
+
+	reactions, err := loadReactions(s.messageRepo, []*model.Message{message}, userID)
+	if err != nil {
+		return nil, err
+	}
+	if summaries, ok := reactions[message.ID]; ok {
+		return summaries, nil
+	}
+	return []*model.ReactionSummary{}, nil
+}
+
+// loadReactions 批量加载消息的表情回应并按表情聚合，以消息ID为键，已撤回的消息不返回回应
+func loadReactions(messageRepo repository.MessageRepository, messages []*model.Message, userID uint) (map[uint][]*model.ReactionSummary, error) {
+	messageIDs := make([]uint, 0, len(messages))
+	for _, message := range messages {
+		if !message.Recalled {
+			messageIDs = append(messageIDs, message.ID)
+		}
+	}
+
+	reactions, err := messageRepo.GetReactions(messageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[uint][]*model.ReactionSummary)
+	byEmoji := make(map[uint]map[string]*model.ReactionSummary)
+	for _, reaction := range reactions {
+		emojis, ok := byEmoji[reaction.MessageID]
+		if !ok {
+			emojis = make(map[string]*model.ReactionSummary)
+			byEmoji[reaction.MessageID] = emojis
+		}
+
+		summary, ok := emojis[reaction.Emoji]
+		if !ok {
+			summary = &model.ReactionSummary{Emoji: reaction.Emoji}
+			emojis[reaction.Emoji] = summary
+			summaries[reaction.MessageID] = append(summaries[reaction.MessageID], summary)
+		}
+		summary.Count++
+		if reaction.UserID == userID {
+			summary.ReactedByMe = true
+		}
+	}
+	return summaries, nil
+}
+
+// reactionAllowed 判断是否为允许使用的表情回应
+func reactionAllowed(emoji string) bool {
+	allowed := config.AppConfig.Chat.AllowedReactions
+	if len(allowed) == 0 {
+		allowed = defaultAllowedReactions
+	}
+	for _, a := range allowed {
+		if a == emoji {
+			return true
+		}
+	}
+	return false
+}
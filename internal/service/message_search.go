@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"ticktok-service/internal/model"
+	"unicode/utf8"
+)
+
+// ErrInvalidSearch 搜索条件无效
+var ErrInvalidSearch = errors.New("无效的搜索条件")
+
+// snippetRadius 搜索结果片段在命中位置前后保留的字符数
+const snippetRadius = 20
+
+// SearchMessages 在用户的所有会话中全文搜索消息，按时间倒序分页
+func (s *messageService) SearchMessages(userID uint, query *model.MessageSearchQuery) (*model.CursorResult, error) {
+	terms := strings.Fields(strings.ReplaceAll(query.Keyword, `"`, " "))
+	if utf8.RuneCountInString(strings.Join(terms, "")) < 2 {
+		// ngram分词的最小单位是2个字符，更短的关键词无法命中
+		return nil, invalidSearch("关键词至少需要2个字符")
+	}
+	if query.FriendID > 0 && query.GroupID > 0 {
+		return nil, invalidSearch("不能同时按好友和群聊筛选")
+	}
+
+	// 每个词作为必须出现的短语，避免用户输入被解析为检索运算符
+	phrases := make([]string, 0, len(terms))
+	for _, term := range terms {
+		phrases = append(phrases, `+"`+term+`"`)
+	}
+
+	messages, err := s.messageRepo.SearchMessages(userID, strings.Join(phrases, " "), query)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(messages) > query.Limit
+	if hasMore {
+		messages = messages[:query.Limit]
+	}
+
+	sessions, err := s.searchSessions(userID, messages)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := loadConversationSettings(s.conversationRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]*model.MessageSearchHit, 0, len(messages))
+	for _, message := range messages {
+		var since uint
+		if setting, ok := settings[message.SessionID]; ok {
+			since = setting.ClearedMessageID
+		}
+		before, after, err := s.messageRepo.GetContextMessages(message.SessionID, message.ID, since, query.Context)
+		if err != nil {
+			return nil, err
+		}
+
+		hits = append(hits, &model.MessageSearchHit{
+			Message: toChatMessage(message, userID),
+			Snippet: searchSnippet(message, terms),
+			Session: sessions[message.SessionID],
+			Before:  toChatMessages(before, userID),
+			After:   toChatMessages(after, userID),
+		})
+	}
+
+	var nextCursor uint
+	if hasMore {
+		nextCursor = messages[len(messages)-1].ID
+	}
+
+	return &model.CursorResult{
+		List:       hits,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// searchSessions 批量获取搜索结果所属会话的信息，以会话ID为键
+func (s *messageService) searchSessions(userID uint, messages []*model.Message) (map[string]*model.SearchSession, error) {
+	sessions := make(map[string]*model.SearchSession)
+
+	var peerIDs []uint
+	hasGroup := false
+	for _, message := range messages {
+		if message.GroupID > 0 {
+			hasGroup = true
+			continue
+		}
+		peerID := message.ReceiverID
+		if peerID == userID {
+			peerID = message.SenderID
+		}
+		peerIDs = append(peerIDs, peerID)
+	}
+
+	if len(peerIDs) > 0 {
+		peers, err := s.userRepo.GetUsersByIDs(peerIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, peer := range peers {
+			sessionID := model.SessionIDFor(userID, peer.ID)
+			sessions[sessionID] = &model.SearchSession{
+				SessionID: sessionID,
+				ID:        peer.ID,
+				Name:      peer.Nickname,
+				Avatar:    peer.Avatar,
+			}
+		}
+	}
+
+	// 搜索范围只包含用户所在的群，直接取用户的群列表
+	if hasGroup {
+		groups, err := s.groupRepo.GetGroupsByUserID(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			sessionID := model.GroupSessionID(group.ID)
+			sessions[sessionID] = &model.SearchSession{
+				SessionID: sessionID,
+				IsGroup:   true,
+				ID:        group.ID,
+				Name:      group.Name,
+				Avatar:    group.Avatar,
+			}
+		}
+	}
+
+	return sessions, nil
+}
+
+// searchSnippet 截取内容或附言中第一个命中词附近的文本
+func searchSnippet(message *model.Message, terms []string) string {
+	for _, text := range []string{message.Content, message.Caption} {
+		lower := strings.ToLower(text)
+		for _, term := range terms {
+			index := strings.Index(lower, strings.ToLower(term))
+			if index < 0 {
+				continue
+			}
+			return excerpt(text, utf8.RuneCountInString(lower[:index]), utf8.RuneCountInString(term))
+		}
+	}
+	return excerpt(message.Content, 0, 0)
+}
+
+// excerpt 以start开始、长度为length的字符区间为中心截取片段，被截断的一端补省略号
+func excerpt(text string, start, length int) string {
+	runes := []rune(text)
+	from := max(start-snippetRadius, 0)
+	to := min(start+length+snippetRadius, len(runes))
+
+	snippet := string(runes[from:to])
+	if from > 0 {
+		snippet = "…" + snippet
+	}
+	if to < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// toChatMessages 批量转换聊天消息
+func toChatMessages(messages []*model.Message, userID uint) []*model.ChatMessage {
+	chatMessages := make([]*model.ChatMessage, 0, len(messages))
+	for _, message := range messages {
+		chatMessages = append(chatMessages, toChatMessage(message, userID))
+	}
+	return chatMessages
+}
+
+// invalidSearch 包装搜索条件错误
+func invalidSearch(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidSearch, reason)
+}
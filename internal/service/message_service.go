@@ -1,73 +1,144 @@
 package service
 
 import (
-	"fmt"
+	"encoding/json"
+	"errors"
+	"sort"
+	"ticktok-service/config"
 	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
 	"ticktok-service/internal/repository"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// 消息相关错误
+var (
+	ErrMessageNotFound    = errors.New("消息不存在")
+	ErrNotMessageSender   = errors.New("只能操作自己发送的消息")
+	ErrRecallExpired      = errors.New("消息已超过可撤回时间")
+	ErrEditExpired        = errors.New("消息已超过可编辑时间")
+	ErrMessageNotEditable = errors.New("只能编辑未撤回的文本消息")
+	ErrMessageRecalled    = errors.New("消息已撤回")
+	ErrInvalidReply       = errors.New("引用的消息不存在或已撤回")
+	ErrInvalidReaction    = errors.New("不支持的表情回应")
+)
+
 // MessageService 消息服务接口
 type MessageService interface {
-	GetMessageList(userID uint) ([]*model.MessageListResponse, error)
-	GetChatHistory(userID, friendID uint) ([]*model.ChatMessage, error)
+	GetMessageList(userID uint, archived bool) ([]*model.MessageListResponse, error)
+	GetChatHistory(userID, friendID uint, query *model.ChatHistoryQuery) (*model.CursorResult, error)
 	SendMessage(req *model.MessageRequest) (*model.MessageResponse, error)
-	MarkAsRead(userID, friendID uint) (bool, error)
+	MarkAsRead(userID, friendID, lastMessageID uint) (bool, error)
+	MarkAsDelivered(userID, friendID, lastMessageID uint) error
+	GetReadCursors(userID, friendID uint) ([]*model.ReadCursorResponse, error)
+	RecallMessage(userID, messageID uint) (*model.ChatMessage, error)
+	EditMessage(userID, messageID uint, content string) (*model.ChatMessage, error)
+	GetMessageEdits(userID, messageID uint) ([]*model.MessageEdit, error)
+	SetConversationMuted(userID, peerID uint, muted bool) error
+	UpdateConversationSetting(userID, peerID uint, req *model.UpdateConversationSettingRequest) (*model.ConversationSettingResponse, error)
+	DeleteConversation(userID, peerID uint) (*model.ConversationSettingResponse, error)
+	ExportChat(userID, peerID uint, format, baseURL string) (*ChatExport, error)
+	SearchMessages(userID uint, query *model.MessageSearchQuery) (*model.CursorResult, error)
+	AddReaction(userID, messageID uint, emoji string) ([]*model.ReactionSummary, error)
+	RemoveReaction(userID, messageID uint, emoji string) ([]*model.ReactionSummary, error)
 }
 
 // messageService 消息服务实现
 type messageService struct {
-	messageRepo repository.MessageRepository
-	userRepo    repository.UserRepository
-	friendRepo  repository.FriendRepository
+	messageRepo      repository.MessageRepository
+	userRepo         repository.UserRepository
+	friendRepo       repository.FriendRepository
+	groupRepo        repository.GroupRepository
+	blockRepo        repository.BlockRepository
+	conversationRepo repository.ConversationRepository
+	privacyRepo      repository.PrivacyRepository
+	resolver         *payloadResolver
+	hub              *ws.Hub
+	notifier         NotificationService
+	bots             BotService
 }
 
 // NewMessageService 创建消息服务
-func NewMessageService(db *gorm.DB) MessageService {
+func NewMessageService(db *gorm.DB, hub *ws.Hub, notifier NotificationService, bots BotService) MessageService {
 	return &messageService{
-		messageRepo: repository.NewMessageRepository(db),
-		userRepo:    repository.NewUserRepository(db),
-		friendRepo:  repository.NewFriendRepository(db),
+		messageRepo:      repository.NewMessageRepository(db),
+		userRepo:         repository.NewUserRepository(db),
+		friendRepo:       repository.NewFriendRepository(db),
+		groupRepo:        repository.NewGroupRepository(db),
+		blockRepo:        repository.NewBlockRepository(db),
+		conversationRepo: repository.NewConversationRepository(db),
+		privacyRepo:      repository.NewPrivacyRepository(db),
+		resolver:         newPayloadResolver(db),
+		hub:              hub,
+		notifier:         notifier,
+		bots:             bots,
 	}
 }
 
-// GetMessageList 获取消息列表
-func (s *messageService) GetMessageList(userID uint) ([]*model.MessageListResponse, error) {
+// GetMessageList 获取消息列表，archived为true时只返回归档的会话，否则只返回未归档的会话
+// 置顶的会话排在最前，已删除且没有新消息的会话不返回
+func (s *messageService) GetMessageList(userID uint, archived bool) ([]*model.MessageListResponse, error) {
 	// 获取用户的最近消息
 	messages, err := s.messageRepo.GetLastMessages(userID)
 	if err != nil {
 		return nil, err
 	}
 	
-	var messageResponses []*model.MessageListResponse
-	for _, message := range messages {
-		// 确定发送者
-		var senderID uint
-		if message.SenderID == userID {
-			senderID = message.ReceiverID
+	// 获取开启免打扰的会话
+	mutes, err := s.blockRepo.GetMutes(userID)
+	if err != nil {
+		return nil, err
+	}
+	mutedPeers := make(map[uint]bool)
+	mutedGroups := make(map[uint]bool)
+	for _, mute := range mutes {
+		if mute.GroupID > 0 {
+			mutedGroups[mute.GroupID] = true
 		} else {
-			senderID = message.SenderID
+			mutedPeers[mute.PeerID] = true
 		}
-		
-		// 获取发送者信息
-		sender, err := s.userRepo.GetUserByID(senderID)
-		if err != nil {
+	}
+	
+	// 确定各会话的对方，批量获取对方用户、未读数和好友类型
+	peerIDs := make([]uint, 0, len(messages))
+	for _, message := range messages {
+		peerIDs = append(peerIDs, peerOf(message, userID))
+	}
+	peers, err := s.userRepo.GetUsersByIDs(peerIDs)
+	if err != nil {
+		return nil, err
+	}
+	peerByID := make(map[uint]*model.User, len(peers))
+	for _, peer := range peers {
+		peerByID[peer.ID] = peer
+	}
+	unreadCounts, err := s.messageRepo.GetUnreadCounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	friendTypes, err := s.friendRepo.GetFriendTypes(userID, peerIDs)
+	if err != nil {
+		return nil, err
+	}
+	privacySettings, err := s.privacyRepo.GetSettings(peerIDs)
+	if err != nil {
+		return nil, err
+	}
+	
+	var messageResponses []*model.MessageListResponse
+	for _, message := range messages {
+		senderID := peerOf(message, userID)
+		sender, ok := peerByID[senderID]
+		if !ok {
 			continue
 		}
+		friendType := peerFriendType(sender, friendTypes[senderID])
 		
-		// 格式化时间
-		timeStr := formatTime(message.Timestamp)
-		
-		// 获取未读消息数量
-		unreadCount, err := s.messageRepo.GetUnreadCount(userID, senderID)
-		if err != nil {
-			unreadCount = 0
-		}
-		
-		// 检查是否是官方账号
-		isOfficial := false // 默认不是官方账号，实际应用中可以通过数据库判断
+		// 关闭在线状态的对方显示为离线
+		presence := toPresenceResponse(sender)
+		applyPresencePrivacy(presence, privacySettings[senderID], userID)
 		
 		// 创建响应
 		messageResponses = append(messageResponses, &model.MessageListResponse{
@@ -76,63 +147,261 @@ func (s *messageService) GetMessageList(userID uint) ([]*model.MessageListRespon
 				ID:         sender.ID,
 				Name:       sender.Nickname,
 				Avatar:     sender.Avatar,
-				Online:     sender.Status == "online",
-				IsOfficial: isOfficial,
-				LastActive: sender.LastSeen,
-				FriendType: "normal", // 默认值，实际应用中应该从数据库获取
+				Online:     presence.Online,
+				IsOfficial: friendType != model.FriendTypeNormal,
+				LastActive: presence.LastSeen,
+				FriendType: friendType,
 			},
-			Text:   message.Content,
-			Time:   timeStr,
-			Unread: unreadCount,
+			Text:   previewText(message, userID),
+			Time:   formatTime(message.Timestamp),
+			Unread: unreadCounts[senderID],
+			Muted:  mutedPeers[senderID],
+			LastAt: message.Timestamp,
 		})
 	}
 	
+	// 合并群聊会话
+	groupResponses, err := s.getGroupMessageList(userID, mutedGroups)
+	if err != nil {
+		return nil, err
+	}
+	messageResponses = append(messageResponses, groupResponses...)
+	
+	// 按会话设置隐藏已删除的会话，标记置顶、归档和草稿
+	settings, err := loadConversationSettings(s.conversationRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	visible := messageResponses[:0]
+	for _, response := range messageResponses {
+		sessionID := model.SessionIDFor(userID, response.Sender.ID)
+		if response.IsGroup {
+			sessionID = model.GroupSessionID(response.GroupID)
+		}
+		if setting, ok := settings[sessionID]; ok {
+			if setting.IsCleared(response.ID) {
+				continue
+			}
+			response.Pinned = setting.PinnedAt != nil
+			response.PinnedAt = unixMilli(setting.PinnedAt)
+			response.Archived = setting.IsArchived(response.ID)
+			response.Draft = setting.Draft
+		}
+		if response.Archived == archived {
+			visible = append(visible, response)
+		}
+	}
+	messageResponses = visible
+	
+	// 置顶的会话按置顶时间倒序排在最前，其余按最后消息时间倒序
+	sort.SliceStable(messageResponses, func(i, j int) bool {
+		if messageResponses[i].PinnedAt != messageResponses[j].PinnedAt {
+			return messageResponses[i].PinnedAt > messageResponses[j].PinnedAt
+		}
+		return messageResponses[i].LastAt > messageResponses[j].LastAt
+	})
+	
 	return messageResponses, nil
 }
 
-// GetChatHistory 获取聊天历史记录
-func (s *messageService) GetChatHistory(userID, friendID uint) ([]*model.ChatMessage, error) {
-	// 获取聊天记录
-	messages, err := s.messageRepo.GetChatHistory(userID, friendID)
+// peerOf 单聊消息中userID的对方
+func peerOf(message *model.Message, userID uint) uint {
+	if message.SenderID == userID {
+		return message.ReceiverID
+	}
+	return message.SenderID
+}
+
+// peerFriendType 会话对方的类型：官方账号以账号类型为准，其余取好友关系中标记的类型，非好友为normal
+func peerFriendType(peer *model.User, friendType string) string {
+	if peer.IsOfficial() || friendType == "" {
+		return peer.AccountType
+	}
+	return friendType
+}
+
+// getGroupMessageList 获取用户所在群聊的消息列表项
+func (s *messageService) getGroupMessageList(userID uint, mutedGroups map[uint]bool) ([]*model.MessageListResponse, error) {
+	groups, err := s.groupRepo.GetGroupsByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
+	if len(groups) == 0 {
+		return nil, nil
+	}
 	
-	var chatMessages []*model.ChatMessage
-	for _, message := range messages {
-		isSelf := message.SenderID == userID
+	// 批量获取最后一条消息和未读数
+	var lastMessageIDs []uint
+	for _, group := range groups {
+		if group.LastMessageID > 0 {
+			lastMessageIDs = append(lastMessageIDs, group.LastMessageID)
+		}
+	}
+	lastMessages, err := s.groupRepo.GetMessagesByIDs(lastMessageIDs)
+	if err != nil {
+		return nil, err
+	}
+	messageByID := make(map[uint]*model.Message, len(lastMessages))
+	for _, message := range lastMessages {
+		messageByID[message.ID] = message
+	}
+	unreadCounts, err := s.groupRepo.GetUnreadCounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	
+	var responses []*model.MessageListResponse
+	for _, group := range groups {
+		response := &model.MessageListResponse{
+			Sender: model.FriendResponse{
+				ID:         group.ID,
+				Name:       group.Name,
+				Avatar:     group.Avatar,
+				FriendType: "group",
+			},
+			Unread:  unreadCounts[group.ID],
+			Muted:   mutedGroups[group.ID],
+			IsGroup: true,
+			GroupID: group.ID,
+			LastAt:  group.CreatedAt.UnixMilli(),
+		}
 		
-		chatMessages = append(chatMessages, &model.ChatMessage{
-			ID:         message.ID,
-			SenderID:   message.SenderID,
-			ReceiverID: message.ReceiverID,
-			IsSelf:     isSelf,
-			Type:       message.Type,
-			Content:    message.Content,
-			Timestamp:  message.Timestamp,
-			Status:     message.Status,
-			SessionID:  message.SessionID,
-			Duration:   message.Duration,
-			Caption:    message.Caption,
-		})
+		// 群内还没有消息时以建群时间排序
+		if message, ok := messageByID[group.LastMessageID]; ok {
+			response.ID = message.ID
+			response.Text = message.Sender.Nickname + ": " + contentPreview(message)
+			if message.Recalled {
+				response.Text = message.Sender.Nickname + " 撤回了一条消息"
+			}
+			response.LastAt = message.Timestamp
+		}
+		response.Time = formatTime(response.LastAt)
+		
+		responses = append(responses, response)
 	}
 	
-	return chatMessages, nil
+	return responses, nil
+}
+
+// GetChatHistory 按游标分页获取聊天历史记录
+func (s *messageService) GetChatHistory(userID, friendID uint, query *model.ChatHistoryQuery) (*model.CursorResult, error) {
+	// 删除过会话时只返回之后的消息
+	since, err := clearedMessageID(s.conversationRepo, userID, model.SessionIDFor(userID, friendID))
+	if err != nil {
+		return nil, err
+	}
+	query.Since = since
+	
+	// 获取聊天记录，仓库会多返回一条用于判断是否还有更多
+	messages, err := s.messageRepo.GetChatHistory(userID, friendID, query)
+	if err != nil {
+		return nil, err
+	}
+	
+	reactions, err := loadReactions(s.messageRepo, messages, userID)
+	if err != nil {
+		return nil, err
+	}
+	
+	return toCursorResult(messages, query, userID, reactions), nil
+}
+
+// toCursorResult 将游标查询结果转换为指定用户视角的分页结果，reactions为按消息ID聚合的表情回应
+func toCursorResult(messages []*model.Message, query *model.ChatHistoryQuery, userID uint, reactions map[uint][]*model.ReactionSummary) *model.CursorResult {
+	// 裁掉多出的一条：向后加载时在末尾，向前加载时在开头
+	hasMore := len(messages) > query.Limit
+	if hasMore {
+		if query.After > 0 {
+			messages = messages[:query.Limit]
+		} else {
+			messages = messages[1:]
+		}
+	}
+	
+	chatMessages := toChatMessages(messages, userID)
+	for _, chatMessage := range chatMessages {
+		chatMessage.Reactions = reactions[chatMessage.ID]
+	}
+	
+	// 下一页游标：向后加载取最新一条，向前加载取最早一条
+	var nextCursor uint
+	if len(messages) > 0 {
+		if query.After > 0 {
+			nextCursor = messages[len(messages)-1].ID
+		} else {
+			nextCursor = messages[0].ID
+		}
+	}
+	
+	return &model.CursorResult{
+		List:       chatMessages,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}
 }
 
 // SendMessage 发送消息
 func (s *messageService) SendMessage(req *model.MessageRequest) (*model.MessageResponse, error) {
+	// 任意一方屏蔽了对方时不允许发消息
+	blocked, err := s.blockRepo.IsBlockedEither(req.SenderID, req.ReceiverID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrUserBlocked
+	}
+	
+	receiver, err := s.userRepo.GetUserByID(req.ReceiverID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	
+	// 按接收者的隐私设置判断是否允许发消息
+	friendship, err := s.friendRepo.GetFriendship(req.SenderID, req.ReceiverID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if req.SenderID != req.ReceiverID {
+		privacy, err := s.privacyRepo.GetSetting(req.ReceiverID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkMessagePrivacy(privacy, friendship != nil); err != nil {
+			return nil, err
+		}
+	}
+	
+	// 按消息类型校验内容
+	content, err := s.resolver.resolve(req.Type, req.Content, req.Duration, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	
+	// 引用的消息必须属于同一会话
+	sessionID := model.SessionIDFor(req.SenderID, req.ReceiverID)
+	var replyTo *model.Message
+	if req.ReplyToID > 0 {
+		replyTo, err = replyTarget(s.messageRepo, s.userRepo, s.conversationRepo, req.SenderID, sessionID, req.ReplyToID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	
 	// 创建消息记录
-	sessionID := fmt.Sprintf("chat_%d_%d", req.SenderID, req.ReceiverID)
 	message := &model.Message{
 		SessionID:  sessionID,
 		SenderID:   req.SenderID,
 		ReceiverID: req.ReceiverID,
 		Type:       req.Type,
-		Content:    req.Content,
+		Content:    content.Content,
+		Payload:    content.Payload,
 		Timestamp:  req.Timestamp,
 		Duration:   req.Duration,
 		Caption:    req.Caption,
+		ReplyToID:  req.ReplyToID,
 		Status:     "sent",
 		CreatedAt:  time.Now(),
 	}
@@ -141,6 +410,30 @@ func (s *messageService) SendMessage(req *model.MessageRequest) (*model.MessageR
 	if err := s.messageRepo.CreateMessage(message); err != nil {
 		return nil, err
 	}
+	message.ReplyTo = replyTo
+	
+	// 实时推送给接收者及发送者的其他设备
+	s.pushToParticipants(message, ws.EventMessageNew)
+	s.hub.SendToUser(message.SenderID, ws.NewEvent(ws.EventMessageStatus, &model.MessageStatusEvent{
+		MessageID: message.ID,
+		SessionID: message.SessionID,
+		Status:    message.Status,
+	}))
+	
+	// 发给AI机器人的消息由机器人运行时异步回复，不需要离线推送，以接收者的账号类型为准
+	if receiver.AccountType == model.AccountTypeAIBot {
+		s.bots.HandleMessage(message)
+	} else if !s.hub.IsOnline(message.ReceiverID) {
+		// 接收者没有在线设备时发送离线推送
+		s.notifier.Notify(&model.Notification{
+			UserID:    message.ReceiverID,
+			Kind:      model.NotificationKindMessage,
+			SenderID:  message.SenderID,
+			SessionID: message.SessionID,
+			MessageID: message.ID,
+			Preview:   contentPreview(message),
+		})
+	}
 	
 	// 返回响应
 	return &model.MessageResponse{
@@ -155,18 +448,371 @@ func (s *messageService) SendMessage(req *model.MessageRequest) (*model.MessageR
 		SessionID:  message.SessionID,
 		Duration:   message.Duration,
 		Caption:    message.Caption,
+		Payload:    rawPayload(message.Payload),
+		ReplyTo:    toQuotedMessage(replyTo),
 	}, nil
 }
 
-// MarkAsRead 标记消息为已读
-func (s *messageService) MarkAsRead(userID, friendID uint) (bool, error) {
-	err := s.messageRepo.MarkMessagesAsRead(userID, friendID)
+// MarkAsRead 将lastMessageID及之前的消息标记为已读，lastMessageID为0表示全部
+func (s *messageService) MarkAsRead(userID, friendID, lastMessageID uint) (bool, error) {
+	lastID, err := s.messageRepo.MarkMessagesAsRead(userID, friendID, lastMessageID)
 	if err != nil {
 		return false, err
 	}
+	if lastID == 0 {
+		return true, nil
+	}
+	
+	// 向对方推送已读回执
+	s.hub.SendToUser(friendID, ws.NewEvent(ws.EventMessageRead, &model.ReadReceiptEvent{
+		ReaderID:          userID,
+		SessionID:         model.SessionIDFor(userID, friendID),
+		LastReadMessageID: lastID,
+		ReadAt:            time.Now().UnixMilli(),
+	}))
+	
 	return true, nil
 }
 
+// MarkAsDelivered 客户端确认收到lastMessageID及之前的消息，lastMessageID为0表示全部
+func (s *messageService) MarkAsDelivered(userID, friendID, lastMessageID uint) error {
+	lastID, err := s.messageRepo.MarkMessagesAsDelivered(userID, friendID, lastMessageID)
+	if err != nil || lastID == 0 {
+		return err
+	}
+	
+	// 向对方推送送达回执
+	s.hub.SendToUser(friendID, ws.NewEvent(ws.EventMessageDelivered, &model.DeliveryReceiptEvent{
+		UserID:        userID,
+		SessionID:     model.SessionIDFor(userID, friendID),
+		LastMessageID: lastID,
+		DeliveredAt:   time.Now().UnixMilli(),
+	}))
+	return nil
+}
+
+// GetReadCursors 获取单聊双方的阅读游标
+func (s *messageService) GetReadCursors(userID, friendID uint) ([]*model.ReadCursorResponse, error) {
+	cursors, err := s.messageRepo.GetReadCursors(model.SessionIDFor(userID, friendID))
+	if err != nil {
+		return nil, err
+	}
+	return toReadCursorResponses(cursors), nil
+}
+
+// toChatMessage 将Message转换为指定用户视角的ChatMessage
+func toChatMessage(message *model.Message, userID uint) *model.ChatMessage {
+	chatMessage := &model.ChatMessage{
+		ID:          message.ID,
+		SenderID:    message.SenderID,
+		ReceiverID:  message.ReceiverID,
+		IsSelf:      message.SenderID == userID,
+		Type:        message.Type,
+		Content:     message.Content,
+		Timestamp:   message.Timestamp,
+		Status:      message.Status,
+		SessionID:   message.SessionID,
+		GroupID:     message.GroupID,
+		Duration:    message.Duration,
+		Caption:     message.Caption,
+		Recalled:    message.Recalled,
+		Edited:      message.Edited,
+		DeliveredAt: unixMilli(message.DeliveredAt),
+		ReadAt:      unixMilli(message.ReadAt),
+		ReplyTo:     toQuotedMessage(message.ReplyTo),
+	}
+	// 撤回前保存的文件、位置和名片等数据不再返回
+	if !message.Recalled {
+		chatMessage.Payload = rawPayload(message.Payload)
+	}
+	return chatMessage
+}
+
+// toQuotedMessage 生成被引用消息的摘要，未加载被引用消息时返回nil
+func toQuotedMessage(message *model.Message) *model.QuotedMessage {
+	if message == nil {
+		return nil
+	}
+	quoted := &model.QuotedMessage{
+		ID:         message.ID,
+		SenderID:   message.SenderID,
+		SenderName: message.Sender.Nickname,
+		Type:       message.Type,
+		Recalled:   message.Recalled,
+	}
+	if !message.Recalled {
+		// 只保留开头的一小段
+		quoted.Content = excerpt(contentPreview(message), 0, 0)
+	}
+	return quoted
+}
+
+// replyTarget 获取被引用的消息及其发送者，必须是sessionID会话中未撤回、且未被userID删除会话时清除的消息
+func replyTarget(messageRepo repository.MessageRepository, userRepo repository.UserRepository, conversationRepo repository.ConversationRepository, userID uint, sessionID string, replyToID uint) (*model.Message, error) {
+	target, err := messageRepo.GetMessageByID(replyToID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidReply
+		}
+		return nil, err
+	}
+	if target.SessionID != sessionID || target.Recalled {
+		return nil, ErrInvalidReply
+	}
+	since, err := clearedMessageID(conversationRepo, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if target.ID <= since {
+		return nil, ErrInvalidReply
+	}
+	
+	sender, err := userRepo.GetUserByID(target.SenderID)
+	if err != nil {
+		return nil, err
+	}
+	target.Sender = *sender
+	return target, nil
+}
+
+// toReadCursorResponses 批量转换阅读游标
+func toReadCursorResponses(cursors []*model.ReadCursor) []*model.ReadCursorResponse {
+	responses := make([]*model.ReadCursorResponse, 0, len(cursors))
+	for _, cursor := range cursors {
+		responses = append(responses, &model.ReadCursorResponse{
+			UserID:          cursor.UserID,
+			LastDeliveredID: cursor.LastDeliveredID,
+			LastReadID:      cursor.LastReadID,
+			DeliveredAt:     unixMilli(cursor.DeliveredAt),
+			ReadAt:          unixMilli(cursor.ReadAt),
+		})
+	}
+	return responses
+}
+
+// rawPayload 将存储的payload转换为原样输出的JSON
+func rawPayload(payload string) json.RawMessage {
+	if payload == "" {
+		return nil
+	}
+	return json.RawMessage(payload)
+}
+
+// RecallMessage 撤回消息，仅发送者可在撤回时限内操作
+func (s *messageService) RecallMessage(userID, messageID uint) (*model.ChatMessage, error) {
+	message, err := s.getOwnMessage(userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.Recalled {
+		return toChatMessage(message, userID), nil
+	}
+	if time.Since(message.CreatedAt) > config.AppConfig.Chat.RecallWindow {
+		return nil, ErrRecallExpired
+	}
+	
+	if err := s.messageRepo.RecallMessage(messageID); err != nil {
+		return nil, err
+	}
+	message.Recalled = true
+	message.Content = ""
+	message.Caption = ""
+	message.Payload = ""
+	
+	s.pushToParticipants(message, ws.EventMessageRecall)
+	return toChatMessage(message, userID), nil
+}
+
+// EditMessage 编辑文本消息，仅发送者可在编辑时限内操作
+func (s *messageService) EditMessage(userID, messageID uint, content string) (*model.ChatMessage, error) {
+	message, err := s.getOwnMessage(userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.Recalled || message.Type != model.MessageTypeText {
+		return nil, ErrMessageNotEditable
+	}
+	if time.Since(message.CreatedAt) > config.AppConfig.Chat.EditWindow {
+		return nil, ErrEditExpired
+	}
+	// 内容未变化时MySQL不计入受影响行数，直接返回，也不产生编辑历史
+	if content == message.Content {
+		return toChatMessage(message, userID), nil
+	}
+	
+	// 检查之后消息可能被撤回，以仓库中加锁后的状态为准
+	if err := s.messageRepo.EditMessage(messageID, content); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMessageRecalled
+		}
+		return nil, err
+	}
+	message.Content = content
+	message.Edited = true
+	
+	s.pushToParticipants(message, ws.EventMessageEdit)
+	return toChatMessage(message, userID), nil
+}
+
+// GetMessageEdits 获取消息编辑历史，仅会话参与者可查看
+func (s *messageService) GetMessageEdits(userID, messageID uint) ([]*model.MessageEdit, error) {
+	message, err := s.getMessage(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isParticipant(message, userID) {
+		return nil, ErrMessageNotFound
+	}
+	
+	return s.messageRepo.GetMessageEdits(messageID)
+}
+
+// SetConversationMuted 开启或关闭单聊免打扰，免打扰时消息照常保存但不增加未读数
+func (s *messageService) SetConversationMuted(userID, peerID uint, muted bool) error {
+	if muted {
+		return s.blockRepo.Mute(userID, peerID, 0)
+	}
+	return s.blockRepo.Unmute(userID, peerID, 0)
+}
+
+// UpdateConversationSetting 更新单聊会话的置顶、归档和草稿
+func (s *messageService) UpdateConversationSetting(userID, peerID uint, req *model.UpdateConversationSettingRequest) (*model.ConversationSettingResponse, error) {
+	setting, err := conversationSetting(s.conversationRepo, userID, peerID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return updateConversationSetting(s.conversationRepo, s.hub, setting, req)
+}
+
+// DeleteConversation 删除单聊会话，只对当前用户隐藏已有的聊天记录，对方不受影响
+func (s *messageService) DeleteConversation(userID, peerID uint) (*model.ConversationSettingResponse, error) {
+	setting, err := conversationSetting(s.conversationRepo, userID, peerID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return clearConversation(s.conversationRepo, s.hub, setting)
+}
+
+// ExportChat 导出与peerID的单聊记录，用户删除会话前的消息不导出
+func (s *messageService) ExportChat(userID, peerID uint, format, baseURL string) (*ChatExport, error) {
+	users, err := s.userRepo.GetUsersByIDs([]uint{userID, peerID})
+	if err != nil {
+		return nil, err
+	}
+	var me, peer *model.User
+	for _, user := range users {
+		if user.ID == userID {
+			me = user
+		}
+		if user.ID == peerID {
+			peer = user
+		}
+	}
+	if me == nil || peer == nil {
+		return nil, ErrUserNotFound
+	}
+	
+	sessionID := model.SessionIDFor(userID, peerID)
+	since, err := clearedMessageID(s.conversationRepo, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	
+	header := &model.ExportHeader{
+		SessionID:  sessionID,
+		Title:      "与" + peer.Nickname + "的聊天记录",
+		ExportedBy: userID,
+		ExportedAt: time.Now().UnixMilli(),
+		Participants: []*model.ExportParticipant{
+			{ID: me.ID, Nickname: me.Nickname},
+			{ID: peer.ID, Nickname: peer.Nickname},
+		},
+	}
+	if peerID == userID {
+		header.Participants = header.Participants[:1]
+	}
+	return newChatExport(s.messageRepo, header, format, baseURL, since)
+}
+
+// getMessage 获取消息，不存在时返回ErrMessageNotFound
+func (s *messageService) getMessage(messageID uint) (*model.Message, error) {
+	message, err := s.messageRepo.GetMessageByID(messageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+	return message, nil
+}
+
+// getOwnMessage 获取当前用户发送的消息
+func (s *messageService) getOwnMessage(userID, messageID uint) (*model.Message, error) {
+	message, err := s.getMessage(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.SenderID != userID {
+		return nil, ErrNotMessageSender
+	}
+	return message, nil
+}
+
+// isParticipant 判断用户是否为消息所在会话的参与者
+func (s *messageService) isParticipant(message *model.Message, userID uint) bool {
+	if message.GroupID > 0 {
+		_, err := s.groupRepo.GetMember(message.GroupID, userID)
+		return err == nil
+	}
+	return message.SenderID == userID || message.ReceiverID == userID
+}
+
+// participantIDs 获取消息所在会话的全部参与者
+func (s *messageService) participantIDs(message *model.Message) []uint {
+	if message.GroupID > 0 {
+		ids, err := s.groupRepo.GetMemberIDs(message.GroupID)
+		if err != nil {
+			return nil
+		}
+		return ids
+	}
+	return []uint{message.SenderID, message.ReceiverID}
+}
+
+// pushToParticipants 向会话所有参与者的在线设备推送消息事件
+func (s *messageService) pushToParticipants(message *model.Message, eventType string) {
+	for _, userID := range s.participantIDs(message) {
+		s.hub.SendToUser(userID, ws.NewEvent(eventType, toChatMessage(message, userID)))
+	}
+}
+
+// previewText 消息列表中展示的最后一条消息内容
+func previewText(message *model.Message, userID uint) string {
+	if !message.Recalled {
+		return contentPreview(message)
+	}
+	if message.SenderID == userID {
+		return "你撤回了一条消息"
+	}
+	return "对方撤回了一条消息"
+}
+
+// contentPreview 消息内容摘要，非文本消息显示类型标签
+func contentPreview(message *model.Message) string {
+	label, ok := typeLabels[message.Type]
+	if !ok {
+		return message.Content
+	}
+	// 位置和卡片消息的Content是可读的名称或标题
+	switch message.Type {
+	case model.MessageTypeLocation, model.MessageTypeProductCard, model.MessageTypePostCard:
+		if message.Content != "" {
+			return label + message.Content
+		}
+	}
+	return label
+}
+
 // formatTime 格式化时间
 func formatTime(timestamp int64) string {
 	t := time.Unix(timestamp/1000, 0)
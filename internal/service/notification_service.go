@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"ticktok-service/config"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/push"
+	"ticktok-service/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 推送相关错误
+var ErrUnsupportedProvider = errors.New("不支持的推送渠道")
+
+// pushTimeout 单次推送请求的超时时间
+const pushTimeout = 10 * time.Second
+
+// NotificationService 离线推送服务接口
+// Notify只将通知放入队列，由后台协程检查偏好和免打扰后发送到用户登记的设备，整个进程共用一个实例
+type NotificationService interface {
+	Start()
+	Notify(notification *model.Notification)
+	RegisterDevice(userID uint, req *model.RegisterDeviceRequest) error
+	UnregisterDevice(userID uint, token string) error
+	GetPreference(userID uint) (*model.NotificationPreference, error)
+	UpdatePreference(userID uint, req *model.UpdateNotificationPreferenceRequest) (*model.NotificationPreference, error)
+}
+
+// notificationService 离线推送服务实现
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	groupRepo        repository.GroupRepository
+	blockRepo        repository.BlockRepository
+	providers        map[string]push.Provider
+	queue            chan *model.Notification
+}
+
+// NewNotificationService 创建离线推送服务，按配置加载推送渠道
+func NewNotificationService(db *gorm.DB) NotificationService {
+	queueSize := config.AppConfig.Push.QueueSize
+	if queueSize <= 0 {
+		queueSize = 1024
+	}
+
+	return &notificationService{
+		notificationRepo: repository.NewNotificationRepository(db),
+		userRepo:         repository.NewUserRepository(db),
+		groupRepo:        repository.NewGroupRepository(db),
+		blockRepo:        repository.NewBlockRepository(db),
+		providers:        push.LoadProviders(),
+		queue:            make(chan *model.Notification, queueSize),
+	}
+}
+
+// Start 启动推送协程
+func (s *notificationService) Start() {
+	workers := config.AppConfig.Push.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for notification := range s.queue {
+				if err := s.deliver(notification); err != nil {
+					log.Printf("向用户%d推送通知失败: %v", notification.UserID, err)
+				}
+			}
+		}()
+	}
+}
+
+// Notify 将通知放入推送队列，不等待发送结果；队列已满时丢弃
+func (s *notificationService) Notify(notification *model.Notification) {
+	select {
+	case s.queue <- notification:
+	default:
+		log.Printf("推送队列已满，丢弃发给用户%d的通知", notification.UserID)
+	}
+}
+
+// RegisterDevice 登记设备推送令牌，渠道必须已在配置中启用
+func (s *notificationService) RegisterDevice(userID uint, req *model.RegisterDeviceRequest) error {
+	if _, ok := s.providers[req.Provider]; !ok {
+		return ErrUnsupportedProvider
+	}
+	return s.notificationRepo.SaveDeviceToken(&model.DeviceToken{
+		UserID:   userID,
+		Provider: req.Provider,
+		Token:    req.Token,
+	})
+}
+
+// UnregisterDevice 注销设备推送令牌，退出登录时调用
+func (s *notificationService) UnregisterDevice(userID uint, token string) error {
+	return s.notificationRepo.DeleteDeviceToken(userID, token)
+}
+
+// GetPreference 获取推送偏好
+func (s *notificationService) GetPreference(userID uint) (*model.NotificationPreference, error) {
+	return s.notificationRepo.GetPreference(userID)
+}
+
+// UpdatePreference 更新推送偏好，未传的字段保持不变
+func (s *notificationService) UpdatePreference(userID uint, req *model.UpdateNotificationPreferenceRequest) (*model.NotificationPreference, error) {
+	preference, err := s.notificationRepo.GetPreference(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Enabled != nil {
+		preference.Enabled = *req.Enabled
+	}
+	if req.Messages != nil {
+		preference.Messages = *req.Messages
+	}
+	if req.GroupMessages != nil {
+		preference.GroupMessages = *req.GroupMessages
+	}
+	if req.FriendRequests != nil {
+		preference.FriendRequests = *req.FriendRequests
+	}
+	if req.ShowPreview != nil {
+		preference.ShowPreview = *req.ShowPreview
+	}
+
+	if err := s.notificationRepo.SavePreference(preference); err != nil {
+		return nil, err
+	}
+	return preference, nil
+}
+
+// deliver 检查偏好和免打扰后，将通知发送到用户的所有设备，失效的令牌会被删除
+func (s *notificationService) deliver(notification *model.Notification) error {
+	preference, err := s.notificationRepo.GetPreference(notification.UserID)
+	if err != nil {
+		return err
+	}
+	allowed, err := s.allowed(preference, notification)
+	if err != nil || !allowed {
+		return err
+	}
+
+	tokens, err := s.notificationRepo.GetDeviceTokens(notification.UserID)
+	if err != nil || len(tokens) == 0 {
+		return err
+	}
+
+	message, err := s.compose(preference, notification)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		provider, ok := s.providers[token.Provider]
+		if !ok {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+		err := provider.Send(ctx, token.Token, message)
+		cancel()
+
+		if errors.Is(err, push.ErrInvalidToken) {
+			if err := s.notificationRepo.DeleteToken(token.Token); err != nil {
+				log.Printf("删除失效的推送令牌失败: %v", err)
+			}
+		} else if err != nil {
+			log.Printf("通过%s推送通知失败: %v", provider.Name(), err)
+		}
+	}
+	return nil
+}
+
+// allowed 判断用户的推送偏好和会话免打扰是否允许发送该通知
+func (s *notificationService) allowed(preference *model.NotificationPreference, notification *model.Notification) (bool, error) {
+	if !preference.Enabled {
+		return false, nil
+	}
+
+	switch notification.Kind {
+	case model.NotificationKindMessage:
+		if !preference.Messages {
+			return false, nil
+		}
+		muted, err := s.blockRepo.IsMuted(notification.UserID, notification.SenderID, 0)
+		return !muted, err
+	case model.NotificationKindGroupMessage:
+		if !preference.GroupMessages {
+			return false, nil
+		}
+		muted, err := s.blockRepo.IsMuted(notification.UserID, 0, notification.GroupID)
+		return !muted, err
+	case model.NotificationKindFriendRequest:
+		return preference.FriendRequests, nil
+	}
+	return false, nil
+}
+
+// compose 按通知类型生成标题、正文和跳转参数，关闭预览时不显示消息内容
+func (s *notificationService) compose(preference *model.NotificationPreference, notification *model.Notification) (*push.Notification, error) {
+	sender, err := s.userRepo.GetUserByID(notification.SenderID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := notification.Preview
+	if !preference.ShowPreview {
+		preview = "[新消息]"
+	}
+
+	message := &push.Notification{
+		Data: map[string]string{
+			"kind":     notification.Kind,
+			"senderId": strconv.FormatUint(uint64(notification.SenderID), 10),
+		},
+	}
+	if notification.SessionID != "" {
+		message.Data["sessionId"] = notification.SessionID
+		message.Data["messageId"] = strconv.FormatUint(uint64(notification.MessageID), 10)
+	}
+
+	switch notification.Kind {
+	case model.NotificationKindMessage:
+		message.Title = sender.Nickname
+		message.Body = preview
+	case model.NotificationKindGroupMessage:
+		group, err := s.groupRepo.GetGroupByID(notification.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		message.Title = group.Name
+		message.Body = sender.Nickname + ": " + preview
+		message.Data["groupId"] = strconv.FormatUint(uint64(notification.GroupID), 10)
+	case model.NotificationKindFriendRequest:
+		message.Title = "新的好友申请"
+		message.Body = sender.Nickname + " 请求添加你为好友"
+	}
+	return message, nil
+}
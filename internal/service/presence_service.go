@@ -0,0 +1,208 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"ticktok-service/config"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
+	"ticktok-service/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PresenceService 在线状态服务接口
+// 通过WebSocket连接和心跳维护users表的status和last_seen_at，整个进程共用一个实例
+type PresenceService interface {
+	ws.PresenceListener
+	Start()
+	GetPresence(viewerID uint, userIDs []uint) ([]*model.PresenceResponse, error)
+}
+
+// presenceService 在线状态服务实现
+type presenceService struct {
+	userRepo    repository.UserRepository
+	privacyRepo repository.PrivacyRepository
+	hub         *ws.Hub
+
+	// mu 保护内存状态，状态变更在持有锁时记入pending，由写入协程在锁外写入数据库，
+	// 连接回调和心跳不等待数据库；同一用户只保留最新的状态，写入协程只有一个，不会被旧状态覆盖
+	mu       sync.Mutex
+	activeAt map[uint]time.Time      // 有连接的用户最后一次活跃的时间
+	status   map[uint]string         // 有连接的用户最新的状态
+	pending  map[uint]presenceUpdate // 尚未写入数据库的状态变更
+	wake     chan struct{}           // 有新的状态变更时通知写入协程
+}
+
+// presenceUpdate 待写入数据库的状态变更
+type presenceUpdate struct {
+	status   string
+	lastSeen time.Time
+}
+
+// NewPresenceService 创建在线状态服务
+func NewPresenceService(db *gorm.DB, hub *ws.Hub) PresenceService {
+	return &presenceService{
+		userRepo:    repository.NewUserRepository(db),
+		privacyRepo: repository.NewPrivacyRepository(db),
+		hub:         hub,
+		activeAt:    make(map[uint]time.Time),
+		status:      make(map[uint]string),
+		pending:     make(map[uint]presenceUpdate),
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Start 重置上次运行遗留的在线状态，再启动后台协程写入状态变更，并定期将空闲用户置为离开或离线
+// 重置在返回前完成，以免覆盖之后建立连接的用户的在线状态
+func (s *presenceService) Start() {
+	interval := config.AppConfig.Presence.SweepInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	if err := s.userRepo.ResetPresence(); err != nil {
+		log.Printf("重置在线状态失败: %v", err)
+	}
+
+	go s.flush()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			s.sweep(now)
+		}
+	}()
+}
+
+// UserConnected 用户上线
+func (s *presenceService) UserConnected(userID uint) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activeAt[userID] = now
+	s.status[userID] = model.PresenceOnline
+	s.apply(userID, model.PresenceOnline, now)
+}
+
+// UserDisconnected 用户的所有设备都已断开，以最后活跃时间作为last_seen_at
+func (s *presenceService) UserDisconnected(userID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 断开与重连的回调可能乱序到达，持有锁后以连接中心的实际状态为准
+	// 连接中心先登记连接再回调UserConnected，此处看到离线时重连的回调一定在释放锁之后执行
+	if s.hub.IsOnline(userID) {
+		return
+	}
+
+	lastSeen, ok := s.activeAt[userID]
+	delete(s.activeAt, userID)
+	delete(s.status, userID)
+	if !ok {
+		lastSeen = time.Now()
+	}
+	s.apply(userID, model.PresenceOffline, lastSeen)
+}
+
+// UserActive 刷新活跃时间，离开或空闲离线的用户恢复为在线
+// 心跳很频繁，状态不变时只更新内存，last_seen_at在状态变化时写入
+func (s *presenceService) UserActive(userID uint) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activeAt[userID] = now
+	if s.status[userID] != model.PresenceOnline {
+		s.status[userID] = model.PresenceOnline
+		s.apply(userID, model.PresenceOnline, now)
+	}
+}
+
+// GetPresence 批量获取用户在线状态，关闭了在线状态的用户对查看者显示为离线
+func (s *presenceService) GetPresence(viewerID uint, userIDs []uint) ([]*model.PresenceResponse, error) {
+	users, err := s.userRepo.GetUsersByIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.privacyRepo.GetSettings(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*model.PresenceResponse, 0, len(users))
+	for _, user := range users {
+		response := toPresenceResponse(user)
+		applyPresencePrivacy(response, settings[user.ID], viewerID)
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// sweep 按空闲时长将仍保持连接的用户置为离开或离线
+func (s *presenceService) sweep(now time.Time) {
+	awayAfter := config.AppConfig.Presence.AwayAfter
+	offlineAfter := config.AppConfig.Presence.OfflineAfter
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for userID, activeAt := range s.activeAt {
+		idle := now.Sub(activeAt)
+		status := model.PresenceOnline
+		if offlineAfter > 0 && idle >= offlineAfter {
+			status = model.PresenceOffline
+		} else if awayAfter > 0 && idle >= awayAfter {
+			status = model.PresenceAway
+		}
+
+		if status != s.status[userID] {
+			s.status[userID] = status
+			s.apply(userID, status, activeAt)
+		}
+	}
+}
+
+// apply 记录状态变更并唤醒写入协程，调用方需持有s.mu
+func (s *presenceService) apply(userID uint, status string, lastSeen time.Time) {
+	s.pending[userID] = presenceUpdate{status: status, lastSeen: lastSeen}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// flush 在锁外将积累的状态变更写入数据库
+func (s *presenceService) flush() {
+	for range s.wake {
+		s.mu.Lock()
+		updates := s.pending
+		s.pending = make(map[uint]presenceUpdate)
+		s.mu.Unlock()
+
+		for userID, update := range updates {
+			if err := s.userRepo.UpdatePresence(userID, update.status, update.lastSeen); err != nil {
+				log.Printf("更新用户%d在线状态失败: %v", userID, err)
+			}
+		}
+	}
+}
+
+// toPresenceResponse 将User转换为PresenceResponse
+func toPresenceResponse(user *model.User) *model.PresenceResponse {
+	return &model.PresenceResponse{
+		UserID:   user.ID,
+		Status:   user.Status,
+		Online:   user.Status == model.PresenceOnline,
+		LastSeen: unixMilli(user.LastSeen),
+	}
+}
+
+// unixMilli 将可能为空的时间转换为毫秒时间戳，空值返回0
+func unixMilli(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.UnixMilli()
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// 隐私相关错误
+var (
+	ErrMessagesRestricted = errors.New("对方设置了不接收消息")
+	ErrFriendListHidden   = errors.New("对方设置了不公开好友列表")
+)
+
+// PrivacyService 隐私设置服务接口
+type PrivacyService interface {
+	GetSetting(userID uint) (*model.PrivacySetting, error)
+	UpdateSetting(userID uint, req *model.UpdatePrivacySettingRequest) (*model.PrivacySetting, error)
+}
+
+// privacyService 隐私设置服务实现
+type privacyService struct {
+	privacyRepo repository.PrivacyRepository
+}
+
+// NewPrivacyService 创建隐私设置服务
+func NewPrivacyService(db *gorm.DB) PrivacyService {
+	return &privacyService{
+		privacyRepo: repository.NewPrivacyRepository(db),
+	}
+}
+
+// GetSetting 获取隐私设置
+func (s *privacyService) GetSetting(userID uint) (*model.PrivacySetting, error) {
+	return s.privacyRepo.GetSetting(userID)
+}
+
+// UpdateSetting 更新隐私设置，未传的字段保持不变
+func (s *privacyService) UpdateSetting(userID uint, req *model.UpdatePrivacySettingRequest) (*model.PrivacySetting, error) {
+	setting, err := s.privacyRepo.GetSetting(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.WhoCanMessage != nil {
+		setting.WhoCanMessage = *req.WhoCanMessage
+	}
+	if req.FriendListVisibility != nil {
+		setting.FriendListVisibility = *req.FriendListVisibility
+	}
+	if req.ShowOnlineStatus != nil {
+		setting.ShowOnlineStatus = *req.ShowOnlineStatus
+	}
+	if req.SearchableByUID != nil {
+		setting.SearchableByUID = *req.SearchableByUID
+	}
+
+	if err := s.privacyRepo.SaveSetting(setting); err != nil {
+		return nil, err
+	}
+	return setting, nil
+}
+
+// checkMessagePrivacy 按接收者的隐私设置判断发送者能否给其发单聊消息，isFriend为双方是否为好友
+func checkMessagePrivacy(setting *model.PrivacySetting, isFriend bool) error {
+	switch setting.WhoCanMessage {
+	case model.PrivacyNobody:
+		return ErrMessagesRestricted
+	case model.PrivacyFriends:
+		if !isFriend {
+			return ErrNotFriends
+		}
+	}
+	return nil
+}
+
+// friendListVisible 按好友列表所有者的隐私设置判断查看者能否查看，viewerID为0表示游客
+func friendListVisible(setting *model.PrivacySetting, viewerID uint, isFriend bool) bool {
+	if viewerID == setting.UserID {
+		return true
+	}
+	switch setting.FriendListVisibility {
+	case model.PrivacyEveryone:
+		return true
+	case model.PrivacyFriends:
+		return isFriend
+	}
+	return false
+}
+
+// applyUserPrivacy 按用户的隐私设置隐藏查看者不应看到的字段：关闭在线状态时显示为离线，关闭UID查找时非好友看不到UID
+func applyUserPrivacy(response *model.UserResponse, setting *model.PrivacySetting, viewerID uint, isFriend bool) {
+	if viewerID == response.ID {
+		return
+	}
+	if !setting.ShowOnlineStatus {
+		response.Status = model.PresenceOffline
+		response.LastSeen = 0
+	}
+	if !setting.SearchableByUID && !isFriend {
+		response.UID = 0
+	}
+}
+
+// applyPresencePrivacy 关闭在线状态的用户对其他人始终显示为离线
+func applyPresencePrivacy(response *model.PresenceResponse, setting *model.PrivacySetting, viewerID uint) {
+	if viewerID == response.UserID || setting.ShowOnlineStatus {
+		return
+	}
+	response.Status = model.PresenceOffline
+	response.Online = false
+	response.LastSeen = 0
+}
@@ -9,21 +9,25 @@ import (
 
 // SlideService 轮播内容服务接口
 type SlideService interface {
-	GetSlideItems(startIndex, pageSize int) (*model.SlideResponse, error)
-	GetSlideItemByItemID(itemID string) (*model.SlideItemResponse, error)
-	GetSlideItemsByType(contentType string, startIndex, pageSize int) (*model.SlideResponse, error)
-	SearchSlideItems(keyword string, startIndex, pageSize int) (*model.SlideResponse, error)
+	GetSlideItems(viewerID uint, startIndex, pageSize int) (*model.SlideResponse, error)
+	GetSlideItemByItemID(viewerID uint, itemID string) (*model.SlideItemResponse, error)
+	GetSlideItemsByType(viewerID uint, contentType string, startIndex, pageSize int) (*model.SlideResponse, error)
+	SearchSlideItems(viewerID uint, keyword string, startIndex, pageSize int) (*model.SlideResponse, error)
 }
 
 // slideService 轮播内容服务实现
 type slideService struct {
-	slideRepo repository.SlideRepository
+	slideRepo  repository.SlideRepository
+	blockRepo  repository.BlockRepository
+	followRepo repository.FollowRepository
 }
 
 // NewSlideService 创建轮播内容服务
 func NewSlideService(db *gorm.DB) SlideService {
 	return &slideService{
-		slideRepo: repository.NewSlideRepository(db),
+		slideRepo:  repository.NewSlideRepository(db),
+		blockRepo:  repository.NewBlockRepository(db),
+		followRepo: repository.NewFollowRepository(db),
 	}
 }
 
@@ -50,6 +54,7 @@ func convertToSlideItemResponse(item *model.SlideItem) *model.SlideItemResponse
 		ContentType: item.ContentType,
 		Title:       item.Title,
 		Author:      item.Author,
+		UserID:      item.UserID,
 		Likes:       item.Likes,
 		Comments:    item.Comments,
 		Stars:       item.Stars,
@@ -68,10 +73,16 @@ func convertToSlideItemResponse(item *model.SlideItem) *model.SlideItemResponse
 	return response
 }
 
-// GetSlideItems 获取轮播内容列表
-func (s *slideService) GetSlideItems(startIndex, pageSize int) (*model.SlideResponse, error) {
+// GetSlideItems 获取轮播内容列表，viewerID为0表示游客
+func (s *slideService) GetSlideItems(viewerID uint, startIndex, pageSize int) (*model.SlideResponse, error) {
+	// 过滤当前用户屏蔽的发布者
+	blockedIDs, err := hiddenAuthorIDs(s.blockRepo, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	
 	// 获取轮播内容列表
-	items, total, err := s.slideRepo.GetSlideItems(startIndex, pageSize)
+	items, total, err := s.slideRepo.GetSlideItems(startIndex, pageSize, blockedIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -81,6 +92,9 @@ func (s *slideService) GetSlideItems(startIndex, pageSize int) (*model.SlideResp
 	for _, item := range items {
 		itemResponses = append(itemResponses, convertToSlideItemResponse(item))
 	}
+	if err := s.markFollowing(viewerID, itemResponses); err != nil {
+		return nil, err
+	}
 	
 	// 判断是否有更多数据
 	hasMore := int64(startIndex+len(itemResponses)) < total
@@ -95,8 +109,8 @@ func (s *slideService) GetSlideItems(startIndex, pageSize int) (*model.SlideResp
 	return response, nil
 }
 
-// GetSlideItemByItemID 根据ItemID获取轮播内容详情
-func (s *slideService) GetSlideItemByItemID(itemID string) (*model.SlideItemResponse, error) {
+// GetSlideItemByItemID 根据ItemID获取轮播内容详情，viewerID为0表示游客
+func (s *slideService) GetSlideItemByItemID(viewerID uint, itemID string) (*model.SlideItemResponse, error) {
 	// 获取轮播内容详情
 	item, err := s.slideRepo.GetSlideItemByItemID(itemID)
 	if err != nil {
@@ -105,14 +119,23 @@ func (s *slideService) GetSlideItemByItemID(itemID string) (*model.SlideItemResp
 	
 	// 转换为响应格式
 	response := convertToSlideItemResponse(item)
+	if err := s.markFollowing(viewerID, []*model.SlideItemResponse{response}); err != nil {
+		return nil, err
+	}
 	
 	return response, nil
 }
 
 // GetSlideItemsByType 根据内容类型获取轮播内容
-func (s *slideService) GetSlideItemsByType(contentType string, startIndex, pageSize int) (*model.SlideResponse, error) {
+func (s *slideService) GetSlideItemsByType(viewerID uint, contentType string, startIndex, pageSize int) (*model.SlideResponse, error) {
+	// 过滤当前用户屏蔽的发布者
+	blockedIDs, err := hiddenAuthorIDs(s.blockRepo, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	
 	// 获取指定类型的轮播内容
-	items, total, err := s.slideRepo.GetSlideItemsByType(contentType, startIndex, pageSize)
+	items, total, err := s.slideRepo.GetSlideItemsByType(contentType, startIndex, pageSize, blockedIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -122,6 +145,9 @@ func (s *slideService) GetSlideItemsByType(contentType string, startIndex, pageS
 	for _, item := range items {
 		itemResponses = append(itemResponses, convertToSlideItemResponse(item))
 	}
+	if err := s.markFollowing(viewerID, itemResponses); err != nil {
+		return nil, err
+	}
 	
 	// 判断是否有更多数据
 	hasMore := int64(startIndex+len(itemResponses)) < total
@@ -137,9 +163,15 @@ func (s *slideService) GetSlideItemsByType(contentType string, startIndex, pageS
 }
 
 // SearchSlideItems 搜索轮播内容
-func (s *slideService) SearchSlideItems(keyword string, startIndex, pageSize int) (*model.SlideResponse, error) {
+func (s *slideService) SearchSlideItems(viewerID uint, keyword string, startIndex, pageSize int) (*model.SlideResponse, error) {
+	// 过滤当前用户屏蔽的发布者
+	blockedIDs, err := hiddenAuthorIDs(s.blockRepo, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	
 	// 搜索轮播内容
-	items, total, err := s.slideRepo.SearchSlideItems(keyword, startIndex, pageSize)
+	items, total, err := s.slideRepo.SearchSlideItems(keyword, startIndex, pageSize, blockedIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -149,6 +181,9 @@ func (s *slideService) SearchSlideItems(keyword string, startIndex, pageSize int
 	for _, item := range items {
 		itemResponses = append(itemResponses, convertToSlideItemResponse(item))
 	}
+	if err := s.markFollowing(viewerID, itemResponses); err != nil {
+		return nil, err
+	}
 	
 	// 判断是否有更多数据
 	hasMore := int64(startIndex+len(itemResponses)) < total
@@ -161,4 +196,20 @@ func (s *slideService) SearchSlideItems(keyword string, startIndex, pageSize int
 	}
 	
 	return response, nil
-} 
\ No newline at end of file
+}
+
+// markFollowing 根据当前用户的关注关系设置轮播内容的IsFollowing
+func (s *slideService) markFollowing(viewerID uint, items []*model.SlideItemResponse) error {
+	authorIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		authorIDs = append(authorIDs, item.UserID)
+	}
+	following, err := followingAuthors(s.followRepo, viewerID, authorIDs)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		item.IsFollowing = following[item.UserID]
+	}
+	return nil
+}
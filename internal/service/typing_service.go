@@ -0,0 +1,231 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"ticktok-service/config"
+	"ticktok-service/internal/model"
+	"ticktok-service/internal/pkg/ws"
+	"ticktok-service/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// typingSweepInterval 检查过期输入状态的周期
+const typingSweepInterval = time.Second
+
+// TypingService 输入状态服务接口
+// 输入状态只保存在内存中并通过WebSocket转发给会话的其他参与者，不写入数据库
+type TypingService interface {
+	Start()
+	HandleTyping(userID uint, data json.RawMessage)
+}
+
+// typingService 输入状态服务实现
+type typingService struct {
+	friendRepo  repository.FriendRepository
+	groupRepo   repository.GroupRepository
+	blockRepo   repository.BlockRepository
+	privacyRepo repository.PrivacyRepository
+	hub         *ws.Hub
+
+	mu     sync.Mutex
+	states map[typingKey]*typingState
+}
+
+// typingKey 同一用户在同一会话中只有一个输入状态
+type typingKey struct {
+	sessionID string
+	userID    uint
+}
+
+// typingState 进行中的输入状态
+type typingState struct {
+	groupID    uint
+	action     string
+	recipients []uint
+	expiresAt  time.Time
+}
+
+// NewTypingService 创建输入状态服务
+func NewTypingService(db *gorm.DB, hub *ws.Hub) TypingService {
+	return &typingService{
+		friendRepo:  repository.NewFriendRepository(db),
+		groupRepo:   repository.NewGroupRepository(db),
+		blockRepo:   repository.NewBlockRepository(db),
+		privacyRepo: repository.NewPrivacyRepository(db),
+		hub:         hub,
+		states:      make(map[typingKey]*typingState),
+	}
+}
+
+// Start 启动后台协程，清除超时未刷新或用户已断开连接的输入状态
+func (s *typingService) Start() {
+	go func() {
+		ticker := time.NewTicker(typingSweepInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			s.sweep(now)
+		}
+	}()
+}
+
+// HandleTyping 处理客户端上报的输入状态
+// 状态开始或改变时才转发，重复上报只刷新超时时间，格式错误或无权限的上报直接忽略
+func (s *typingService) HandleTyping(userID uint, data json.RawMessage) {
+	var req model.TypingRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return
+	}
+	switch req.Action {
+	case model.TypingActionTyping, model.TypingActionRecording, model.TypingActionStopped:
+	default:
+		return
+	}
+	if (req.PeerID > 0) == (req.GroupID > 0) || req.PeerID == userID {
+		return
+	}
+
+	sessionID := model.SessionIDFor(userID, req.PeerID)
+	if req.GroupID > 0 {
+		sessionID = model.GroupSessionID(req.GroupID)
+	}
+	key := typingKey{sessionID: sessionID, userID: userID}
+
+	if req.Action == model.TypingActionStopped {
+		s.stop(key)
+		return
+	}
+
+	expiresAt := time.Now().Add(s.timeout())
+	s.mu.Lock()
+	if state, ok := s.states[key]; ok {
+		state.expiresAt = expiresAt
+		changed := state.action != req.Action
+		state.action = req.Action
+		recipients := state.recipients
+		s.mu.Unlock()
+
+		if changed {
+			s.broadcast(key, req.GroupID, req.Action, recipients)
+		}
+		return
+	}
+	s.mu.Unlock()
+
+	// 新的输入状态才校验权限，之后的刷新沿用同一批接收者
+	recipients, err := s.recipients(userID, &req)
+	if err != nil {
+		log.Printf("获取用户%d输入状态的接收者失败: %v", userID, err)
+		return
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	s.states[key] = &typingState{
+		groupID:    req.GroupID,
+		action:     req.Action,
+		recipients: recipients,
+		expiresAt:  expiresAt,
+	}
+	s.mu.Unlock()
+
+	s.broadcast(key, req.GroupID, req.Action, recipients)
+}
+
+// recipients 获取输入状态的接收者：单聊为对方，群聊为除自己外的其他成员；无权发消息时返回空
+func (s *typingService) recipients(userID uint, req *model.TypingRequest) ([]uint, error) {
+	if req.GroupID > 0 {
+		if _, err := s.groupRepo.GetMember(req.GroupID, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		memberIDs, err := s.groupRepo.GetMemberIDs(req.GroupID)
+		if err != nil {
+			return nil, err
+		}
+
+		recipients := make([]uint, 0, len(memberIDs))
+		for _, memberID := range memberIDs {
+			if memberID != userID {
+				recipients = append(recipients, memberID)
+			}
+		}
+		return recipients, nil
+	}
+
+	// 与发消息的规则一致
+	blocked, err := s.blockRepo.IsBlockedEither(userID, req.PeerID)
+	if err != nil || blocked {
+		return nil, err
+	}
+	isFriend, err := s.friendRepo.IsFriend(userID, req.PeerID)
+	if err != nil {
+		return nil, err
+	}
+	privacy, err := s.privacyRepo.GetSetting(req.PeerID)
+	if err != nil {
+		return nil, err
+	}
+	if checkMessagePrivacy(privacy, isFriend) != nil {
+		return nil, nil
+	}
+	return []uint{req.PeerID}, nil
+}
+
+// stop 清除输入状态，状态存在时通知接收者
+func (s *typingService) stop(key typingKey) {
+	s.mu.Lock()
+	state, ok := s.states[key]
+	delete(s.states, key)
+	s.mu.Unlock()
+
+	if ok {
+		s.broadcast(key, state.groupID, model.TypingActionStopped, state.recipients)
+	}
+}
+
+// sweep 清除超时未刷新或用户所有设备都已断开的输入状态，并通知接收者输入已停止
+func (s *typingService) sweep(now time.Time) {
+	expired := make(map[typingKey]*typingState)
+	s.mu.Lock()
+	for key, state := range s.states {
+		if now.After(state.expiresAt) || !s.hub.IsOnline(key.userID) {
+			expired[key] = state
+			delete(s.states, key)
+		}
+	}
+	s.mu.Unlock()
+
+	for key, state := range expired {
+		s.broadcast(key, state.groupID, model.TypingActionStopped, state.recipients)
+	}
+}
+
+// broadcast 向接收者推送输入状态
+func (s *typingService) broadcast(key typingKey, groupID uint, action string, recipients []uint) {
+	event := ws.NewEvent(ws.EventChatTyping, &model.TypingEvent{
+		SessionID: key.sessionID,
+		UserID:    key.userID,
+		GroupID:   groupID,
+		Action:    action,
+	})
+	for _, recipientID := range recipients {
+		s.hub.SendToUser(recipientID, event)
+	}
+}
+
+// timeout 输入状态的超时时间
+func (s *typingService) timeout() time.Duration {
+	if timeout := config.AppConfig.Chat.TypingTimeout; timeout > 0 {
+		return timeout
+	}
+	return 6 * time.Second
+}
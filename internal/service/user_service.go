@@ -1,67 +1,332 @@
 package service
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
 	"ticktok-service/internal/model"
 	"ticktok-service/internal/repository"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// 用户资料相关错误
+var (
+	ErrInvalidNickname = errors.New("昵称不能为空")
+	ErrInvalidAvatar   = errors.New("头像必须是自己上传的图片")
+	ErrInvalidBirthday = errors.New("生日格式应为YYYY-MM-DD，且不能早于1900年或晚于今天")
+)
+
+// profileListLimit 个人主页展示的最近发布内容和博客数量
+const profileListLimit = 20
+
 // UserService 用户服务接口
 type UserService interface {
-	GetUserByID(id uint) (*model.UserResponse, error)
-	GetUsersByIDs(ids []uint) ([]*model.UserResponse, error)
+	GetUserByID(viewerID, id uint) (*model.UserResponse, error)
+	GetUsersByIDs(viewerID uint, ids []uint) ([]*model.UserResponse, error)
+	FindUserByUID(viewerID, uid uint) (*model.UserResponse, error)
+	UpdateProfile(userID uint, req *model.UpdateProfileRequest) (*model.UserResponse, error)
+	GetProfile(viewerID, userID uint) (*model.ProfileResponse, error)
 }
 
 // userService 用户服务实现
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo    repository.UserRepository
+	friendRepo  repository.FriendRepository
+	blockRepo   repository.BlockRepository
+	contentRepo repository.ContentRepository
+	blogRepo    repository.BlogRepository
+	followRepo  repository.FollowRepository
+	privacyRepo repository.PrivacyRepository
 }
 
 // NewUserService 创建用户服务
 func NewUserService(db *gorm.DB) UserService {
 	return &userService{
-		userRepo: repository.NewUserRepository(db),
+		userRepo:    repository.NewUserRepository(db),
+		friendRepo:  repository.NewFriendRepository(db),
+		blockRepo:   repository.NewBlockRepository(db),
+		contentRepo: repository.NewContentRepository(db),
+		blogRepo:    repository.NewBlogRepository(),
+		followRepo:  repository.NewFollowRepository(db),
+		privacyRepo: repository.NewPrivacyRepository(db),
 	}
 }
 
-// GetUserByID 根据ID获取用户信息
-func (s *userService) GetUserByID(id uint) (*model.UserResponse, error) {
+// GetUserByID 根据ID获取用户信息，按对方的隐私设置隐藏在线状态和UID
+func (s *userService) GetUserByID(viewerID, id uint) (*model.UserResponse, error) {
 	user, err := s.userRepo.GetUserByID(id)
 	if err != nil {
 		return nil, err
 	}
 	
-	return &model.UserResponse{
-		ID:        user.ID,
-		UID:       user.UID,
-		Nickname:  user.Nickname,
-		Avatar:    user.Avatar,
-		Status:    user.Status,
-		LastSeen:  user.LastSeen,
-		Signature: user.Signature,
-	}, nil
+	userResponses, err := s.toVisibleUsers(viewerID, []*model.User{user})
+	if err != nil {
+		return nil, err
+	}
+	return userResponses[0], nil
 }
 
-// GetUsersByIDs 批量获取用户信息
-func (s *userService) GetUsersByIDs(ids []uint) ([]*model.UserResponse, error) {
+// GetUsersByIDs 批量获取用户信息，按各自的隐私设置隐藏在线状态和UID
+func (s *userService) GetUsersByIDs(viewerID uint, ids []uint) ([]*model.UserResponse, error) {
 	users, err := s.userRepo.GetUsersByIDs(ids)
 	if err != nil {
 		return nil, err
 	}
 	
-	var userResponses []*model.UserResponse
+	return s.toVisibleUsers(viewerID, users)
+}
+
+// FindUserByUID 通过UID查找用户，对方关闭了UID查找或存在屏蔽关系时视为用户不存在
+func (s *userService) FindUserByUID(viewerID, uid uint) (*model.UserResponse, error) {
+	user, err := s.userRepo.GetUserByUID(uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.ID == viewerID {
+		return toUserResponse(user), nil
+	}
+	
+	setting, err := s.privacyRepo.GetSetting(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !setting.SearchableByUID {
+		return nil, ErrUserNotFound
+	}
+	blocked, err := s.blockRepo.IsBlockedEither(viewerID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrUserNotFound
+	}
+	
+	response := toUserResponse(user)
+	applyUserPrivacy(response, setting, viewerID, false)
+	return response, nil
+}
+
+// UpdateProfile 更新个人资料，只修改请求中传了的字段
+func (s *userService) UpdateProfile(userID uint, req *model.UpdateProfileRequest) (*model.UserResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	
+	fields := make(map[string]interface{})
+	if req.Nickname != nil {
+		nickname := strings.TrimSpace(*req.Nickname)
+		if nickname == "" {
+			return nil, ErrInvalidNickname
+		}
+		fields["nickname"] = nickname
+	}
+	if req.Avatar != nil && *req.Avatar != user.Avatar {
+		if err := s.checkAvatar(userID, *req.Avatar); err != nil {
+			return nil, err
+		}
+		fields["avatar"] = *req.Avatar
+	}
+	if req.Signature != nil {
+		fields["signature"] = strings.TrimSpace(*req.Signature)
+	}
+	if req.Gender != nil {
+		fields["gender"] = *req.Gender
+	}
+	if req.Birthday != nil {
+		birthday, err := parseBirthday(*req.Birthday)
+		if err != nil {
+			return nil, err
+		}
+		fields["birthday"] = birthday
+	}
+	if req.Region != nil {
+		fields["region"] = strings.TrimSpace(*req.Region)
+	}
+	
+	if len(fields) > 0 {
+		if err := s.userRepo.UpdateProfile(userID, fields); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetUserByID(userID, userID)
+}
+
+// GetProfile 获取用户的公开主页，viewerID为0表示游客
+// 发布内容按可见范围过滤：游客和陌生人只能看到公开内容，好友还能看到仅好友可见的内容，本人可以看到全部
+func (s *userService) GetProfile(viewerID, userID uint) (*model.ProfileResponse, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	
+	profile := &model.ProfileResponse{
+		User:           toUserResponse(user),
+		IsSelf:         viewerID == userID,
+		FollowerCount:  user.FollowerCount,
+		FollowingCount: user.FollowingCount,
+	}
+	visibilities := []string{model.VisibilityPublic}
+	if profile.IsSelf {
+		visibilities = append(visibilities, model.VisibilityFriends, model.VisibilityPrivate)
+	} else if viewerID > 0 {
+		blocked, err := s.blockRepo.IsBlockedEither(viewerID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, ErrUserBlocked
+		}
+		if profile.IsFriend, err = s.friendRepo.IsFriend(viewerID, userID); err != nil {
+			return nil, err
+		}
+		if profile.IsFriend {
+			visibilities = append(visibilities, model.VisibilityFriends)
+		}
+		if profile.IsFollowing, err = s.followRepo.IsFollowing(viewerID, userID); err != nil {
+			return nil, err
+		}
+		if profile.IsFollowedBy, err = s.followRepo.IsFollowing(userID, viewerID); err != nil {
+			return nil, err
+		}
+	}
+	
+	setting, err := s.privacyRepo.GetSetting(userID)
+	if err != nil {
+		return nil, err
+	}
+	applyUserPrivacy(profile.User, setting, viewerID, profile.IsFriend)
+	
+	if profile.ContentCount, err = s.contentRepo.CountUserContents(userID, visibilities); err != nil {
+		return nil, err
+	}
+	contents, err := s.contentRepo.GetUserContents(userID, visibilities, profileListLimit)
+	if err != nil {
+		return nil, err
+	}
+	profile.Contents = make([]*model.ContentResponse, 0, len(contents))
+	for _, content := range contents {
+		profile.Contents = append(profile.Contents, toContentResponse(content))
+	}
+	
+	if profile.BlogCount, err = s.blogRepo.CountUserBlogs(userID); err != nil {
+		return nil, err
+	}
+	if profile.Blogs, err = s.blogRepo.GetUserBlogs(userID, profileListLimit); err != nil {
+		return nil, err
+	}
+	for i := range profile.Blogs {
+		profile.Blogs[i].UserID = profile.Blogs[i].PublisherID()
+		profile.Blogs[i].IsFollowing = profile.IsFollowing
+	}
+	return profile, nil
+}
+
+// checkAvatar 校验头像地址，必须是该用户通过上传接口上传的图片，空字符串表示恢复默认头像
+func (s *userService) checkAvatar(userID uint, avatar string) error {
+	if avatar == "" {
+		return nil
+	}
+	file, err := s.userRepo.GetMediaFileByURL(avatar)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidAvatar
+		}
+		return err
+	}
+	if file.UserID != userID || file.Type != model.MediaTypePhoto {
+		return ErrInvalidAvatar
+	}
+	return nil
+}
+
+// parseBirthday 解析YYYY-MM-DD格式的生日，空字符串表示清除生日
+func parseBirthday(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	birthday, err := time.ParseInLocation(model.BirthdayLayout, value, time.Local)
+	if err != nil {
+		return nil, ErrInvalidBirthday
+	}
+	if birthday.Year() < 1900 || birthday.After(time.Now()) {
+		return nil, ErrInvalidBirthday
+	}
+	return &birthday, nil
+}
+
+// toVisibleUsers 转换用户信息，并按各自的隐私设置隐藏查看者不应看到的字段
+func (s *userService) toVisibleUsers(viewerID uint, users []*model.User) ([]*model.UserResponse, error) {
+	ids := make([]uint, 0, len(users))
 	for _, user := range users {
-		userResponses = append(userResponses, &model.UserResponse{
-			ID:        user.ID,
-			UID:       user.UID,
-			Nickname:  user.Nickname,
-			Avatar:    user.Avatar,
-			Status:    user.Status,
-			LastSeen:  user.LastSeen,
-			Signature: user.Signature,
-		})
+		ids = append(ids, user.ID)
+	}
+	settings, err := s.privacyRepo.GetSettings(ids)
+	if err != nil {
+		return nil, err
+	}
+	friendTypes, err := s.friendRepo.GetFriendTypes(viewerID, ids)
+	if err != nil {
+		return nil, err
 	}
 	
+	var userResponses []*model.UserResponse
+	for _, user := range users {
+		_, isFriend := friendTypes[user.ID]
+		response := toUserResponse(user)
+		applyUserPrivacy(response, settings[user.ID], viewerID, isFriend)
+		userResponses = append(userResponses, response)
+	}
 	return userResponses, nil
+}
+
+// toUserResponse 将User转换为UserResponse
+func toUserResponse(user *model.User) *model.UserResponse {
+	response := &model.UserResponse{
+		ID:        user.ID,
+		UID:       user.UID,
+		Nickname:  user.Nickname,
+		Avatar:    user.Avatar,
+		Status:    user.Status,
+		LastSeen:  unixMilli(user.LastSeen),
+		Signature: user.Signature,
+		Gender:    user.Gender,
+		Region:    user.Region,
+	}
+	if user.Birthday != nil {
+		response.Birthday = user.Birthday.Format(model.BirthdayLayout)
+	}
+	return response
+}
+
+// toContentResponse 将Content转换为ContentResponse，解析JSON存储的字段
+func toContentResponse(content *model.Content) *model.ContentResponse {
+	response := &model.ContentResponse{
+		ID:           content.ID,
+		UserID:       content.UserID,
+		Title:        content.Title,
+		Description:  content.Description,
+		Visibility:   content.Visibility,
+		IsDaily:      content.IsDaily,
+		ViewCount:    content.ViewCount,
+		LikeCount:    content.LikeCount,
+		CommentCount: content.CommentCount,
+		ShareCount:   content.ShareCount,
+		CreatedAt:    content.CreatedAt.UnixMilli(),
+	}
+	json.Unmarshal([]byte(content.MediaItems), &response.MediaItems)
+	json.Unmarshal([]byte(content.Topics), &response.Topics)
+	json.Unmarshal([]byte(content.Tags), &response.Tags)
+	return response
 } 
\ No newline at end of file